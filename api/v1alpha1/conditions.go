@@ -29,9 +29,35 @@ const (
 	// StardogTerminating is given when the the Stardog resource is to be deleted but the object's finalizers cannot
 	// be cleared for a reason.
 	StardogTerminating StardogConditionType = "StardogTerminating"
+	// StardogOptionsSynchronized tracks if the options of a database match the desired options. It is false if
+	// options could not be applied because they are immutable or require the database to be taken offline.
+	StardogOptionsSynchronized StardogConditionType = "OptionsSynchronized"
+	// StardogCredentialsSynced tracks if the password of the credential Secret of a user has been applied to Stardog.
+	// The last transition time is the time the password has been changed last.
+	StardogCredentialsSynced StardogConditionType = "CredentialsSynced"
+	// StardogCredentialsRecovered is given as a warning when the credential Secret of users created by the operator was
+	// missing or incomplete and the passwords of the affected users have been reset.
+	StardogCredentialsRecovered StardogConditionType = "CredentialsRecovered"
+	// StardogUserEnabled tracks if a user is enabled in Stardog. It is false while the user is disabled.
+	StardogUserEnabled StardogConditionType = "Enabled"
+	// StardogUnexpectedSuperuser is given as a warning when a user is a superuser in Stardog although the StardogUser
+	// does not specify it, e.g. because the user has been promoted out of band.
+	StardogUnexpectedSuperuser StardogConditionType = "UnexpectedSuperuser"
+	// StardogDeletionBlocked is given when a resource to be deleted is protected by the DeletionProtectionAnnotation.
+	// The resources in Stardog are kept until the annotation has been removed.
+	StardogDeletionBlocked StardogConditionType = "DeletionBlocked"
 
-	ReasonFailed      = "SynchronizationFailed"
-	ReasonSucceeded   = "SynchronizationSucceeded"
-	ReasonSpecInvalid = "InvalidSpec"
-	ReasonTerminating = "StardogTerminating"
+	ReasonFailed        = "SynchronizationFailed"
+	ReasonSucceeded     = "SynchronizationSucceeded"
+	ReasonSpecInvalid   = "InvalidSpec"
+	ReasonTerminating   = "StardogTerminating"
+	ReasonPending       = "ChangesPending"
+	ReasonReset         = "CredentialsReset"
+	ReasonEnabled       = "UserEnabled"
+	ReasonDisabled      = "UserDisabled"
+	ReasonDrift         = "DriftCorrected"
+	ReasonSuperuser     = "SuperuserDrift"
+	ReasonNotEmpty      = "DatabaseNotEmpty"
+	ReasonBackupPending = "BackupPending"
+	ReasonProtected     = "DeletionProtected"
 )
@@ -0,0 +1,48 @@
+package v1alpha1
+
+import (
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// CredentialRotationSpec defines when the passwords of users managed by the operator are replaced by new ones
+// +kubebuilder:validation:XValidation:rule="has(self.interval) != has(self.schedule)",message="exactly one of interval or schedule is required"
+type CredentialRotationSpec struct {
+	// Interval is the time after which the passwords are rotated, e.g. "2160h" for 90 days.
+	// +kubebuilder:validation:Optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Schedule is a cron expression in the time zone of the operator defining when the passwords are rotated.
+	// +kubebuilder:validation:Optional
+	Schedule string `json:"schedule,omitempty"`
+	// RolloutSelector selects the Deployments in the namespace of the credential Secret which are restarted after
+	// the passwords have been rotated.
+	// +kubebuilder:validation:Optional
+	RolloutSelector *metav1.LabelSelector `json:"rolloutSelector,omitempty"`
+}
+
+// Validate returns the errors of the rotation policy at the given path
+func (in *CredentialRotationSpec) Validate(path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if in == nil {
+		return allErrs
+	}
+
+	if (in.Interval == nil) == (in.Schedule == "") {
+		allErrs = append(allErrs, field.Required(path, "exactly one of interval or schedule is required"))
+	}
+	if in.Interval != nil && in.Interval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("interval"), in.Interval.Duration.String(), "must be positive"))
+	}
+	if in.Schedule != "" {
+		if _, err := cron.ParseStandard(in.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("schedule"), in.Schedule, err.Error()))
+		}
+	}
+	if in.RolloutSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(in.RolloutSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("rolloutSelector"), in.RolloutSelector, err.Error()))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,49 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// CredentialSecretTemplate defines the layout of the credential Secrets created by the operator
+type CredentialSecretTemplate struct {
+	// PerUser creates one Secret per user named "<secret>-<username>" with the keys "username" and "password" instead
+	// of a single Secret containing the password of each user keyed by the username.
+	// +kubebuilder:validation:Optional
+	PerUser bool `json:"perUser,omitempty"`
+	// Type is the type of the Secrets. The type kubernetes.io/basic-auth requires a Secret per user.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Opaque;kubernetes.io/basic-auth
+	Type corev1.SecretType `json:"type,omitempty"`
+	// Labels are added to the Secrets
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the Secrets
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ConnectionDetails adds the keys "endpoint" with the server URL of the StardogInstance and, for users of a
+	// database, "database", "queryUrl" and "updateUrl" with the SPARQL endpoints of the database.
+	// +kubebuilder:validation:Optional
+	ConnectionDetails bool `json:"connectionDetails,omitempty"`
+}
+
+// Validate returns the errors of the template at the given path. Templates of single users cannot create a Secret
+// per user.
+func (in *CredentialSecretTemplate) Validate(path *field.Path, singleUser bool) field.ErrorList {
+	var allErrs field.ErrorList
+	if in == nil {
+		return allErrs
+	}
+
+	if singleUser && in.PerUser {
+		allErrs = append(allErrs, field.Forbidden(path.Child("perUser"), "not supported for a single user"))
+	}
+	if in.Type != "" && in.Type != corev1.SecretTypeOpaque && in.Type != corev1.SecretTypeBasicAuth {
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), in.Type,
+			[]string{string(corev1.SecretTypeOpaque), string(corev1.SecretTypeBasicAuth)}))
+	}
+	if in.Type == corev1.SecretTypeBasicAuth && !singleUser && !in.PerUser {
+		allErrs = append(allErrs, field.Invalid(path.Child("type"), in.Type, "requires perUser"))
+	}
+	return allErrs
+}
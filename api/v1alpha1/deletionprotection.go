@@ -0,0 +1,12 @@
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// DeletionProtectionAnnotation protects a StardogInstance, Database or Organization from deletion if set to "true".
+// The finalizers of a protected resource do not touch Stardog until the annotation has been removed.
+const DeletionProtectionAnnotation = "stardog.vshn.ch/deletion-protection"
+
+// IsDeletionProtected returns whether the object has the DeletionProtectionAnnotation set to "true"
+func IsDeletionProtected(obj metav1.Object) bool {
+	return obj.GetAnnotations()[DeletionProtectionAnnotation] == "true"
+}
@@ -15,6 +15,47 @@ type StardogInstanceSpec struct {
 	AdminCredentials StardogUserCredentialsSpec `json:"adminCredentials,omitempty"`
 	// Disabled whether this instance is disabled or enabled for operator to recycle resources
 	Disabled bool `json:"disabled,omitempty"`
+	// TLS configures the TLS connection to the Stardog instance. Only used if ServerUrl has the https scheme.
+	TLS *StardogTLSSpec `json:"tls,omitempty"`
+	// AllowSuperuserFrom selects the namespaces whose StardogUsers may be superusers. Superusers are not allowed if
+	// not set.
+	AllowSuperuserFrom *metav1.LabelSelector `json:"allowSuperuserFrom,omitempty"`
+}
+
+// StardogTLSSpec defines how the Operator verifies the Stardog server and authenticates against it on TLS level
+type StardogTLSSpec struct {
+	// CABundle references the PEM encoded CA certificates used to verify the certificate of the Stardog server.
+	// If not set, the system CA certificates are used.
+	CABundle *StardogCABundleSpec `json:"caBundle,omitempty"`
+	// ClientCertificate references a Secret of type kubernetes.io/tls containing the client certificate and key for mTLS.
+	ClientCertificate *StardogClientCertificateSpec `json:"clientCertificate,omitempty"`
+	// ServerName overrides the host name used to verify the certificate of the Stardog server.
+	ServerName string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables the verification of the certificate of the Stardog server. Do not use in production.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// StardogCABundleSpec references a key in a Secret or ConfigMap containing PEM encoded CA certificates.
+// Exactly one of SecretRef and ConfigMapRef has to be set.
+type StardogCABundleSpec struct {
+	// SecretRef is the name of the Secret containing the CA bundle
+	SecretRef string `json:"secretRef,omitempty"`
+	// ConfigMapRef is the name of the ConfigMap containing the CA bundle
+	ConfigMapRef string `json:"configMapRef,omitempty"`
+	// Key is the key in the Secret or ConfigMap containing the CA bundle
+	// +kubebuilder:default=ca.crt
+	Key string `json:"key,omitempty"`
+	// Namespace is the namespace of the Secret or ConfigMap. Defaults to the namespace of the StardogInstance.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// StardogClientCertificateSpec references a Secret containing a client certificate in the keys tls.crt and tls.key.
+type StardogClientCertificateSpec struct {
+	// SecretRef is the name of the Secret containing the client certificate
+	// +kubebuilder:validation:Required
+	SecretRef string `json:"secretRef,omitempty"`
+	// Namespace is the namespace of the Secret. Defaults to the namespace of the StardogInstance.
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // StardogInstanceStatus defines the observed state of StardogInstance
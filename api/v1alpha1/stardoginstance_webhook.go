@@ -0,0 +1,116 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of the StardogInstance
+func (r *StardogInstance) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&stardogInstanceWebhook{}).
+		WithValidator(&stardogInstanceWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-stardog-vshn-ch-v1alpha1-stardoginstance,mutating=true,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=stardoginstances,verbs=create;update,versions=v1alpha1,name=mstardoginstance.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-stardog-vshn-ch-v1alpha1-stardoginstance,mutating=false,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=stardoginstances,verbs=create;update,versions=v1alpha1,name=vstardoginstance.kb.io,admissionReviewVersions=v1
+
+type stardogInstanceWebhook struct{}
+
+// Default sets the namespace of the admin credentials to the namespace of the StardogInstance
+func (w *stardogInstanceWebhook) Default(_ context.Context, obj runtime.Object) error {
+	instance, ok := obj.(*StardogInstance)
+	if !ok {
+		return fmt.Errorf("expected a StardogInstance but got %T", obj)
+	}
+	if instance.Spec.AdminCredentials.Namespace == "" {
+		instance.Spec.AdminCredentials.Namespace = instance.Namespace
+	}
+	return nil
+}
+
+// ValidateCreate validates the StardogInstance on creation
+func (w *stardogInstanceWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	instance, ok := obj.(*StardogInstance)
+	if !ok {
+		return nil, fmt.Errorf("expected a StardogInstance but got %T", obj)
+	}
+	return instance.validate()
+}
+
+// ValidateUpdate validates the StardogInstance on update
+func (w *stardogInstanceWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	instance, ok := newObj.(*StardogInstance)
+	if !ok {
+		return nil, fmt.Errorf("expected a StardogInstance but got %T", newObj)
+	}
+	return instance.validate()
+}
+
+// ValidateDelete does not validate anything, deletion is guarded by finalizers
+func (w *stardogInstanceWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *StardogInstance) validate() (admission.Warnings, error) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+	specPath := field.NewPath("spec")
+
+	if err := validateServerUrl(specPath.Child("serverUrl"), r.Spec.ServerUrl); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if r.Spec.AdminCredentials.SecretRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("adminCredentials", "secretRef"), ""))
+	}
+
+	if tls := r.Spec.TLS; tls != nil {
+		tlsPath := specPath.Child("tls")
+		if tls.CABundle != nil && (tls.CABundle.SecretRef == "") == (tls.CABundle.ConfigMapRef == "") {
+			allErrs = append(allErrs, field.Invalid(tlsPath.Child("caBundle"), tls.CABundle,
+				"exactly one of secretRef and configMapRef is required"))
+		}
+		if tls.ClientCertificate != nil && tls.ClientCertificate.SecretRef == "" {
+			allErrs = append(allErrs, field.Required(tlsPath.Child("clientCertificate", "secretRef"), ""))
+		}
+		if tls.InsecureSkipVerify {
+			warnings = append(warnings, "spec.tls.insecureSkipVerify disables the verification of the Stardog server certificate")
+		}
+	}
+
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(r.Spec.AllowSuperuserFrom,
+		metav1validation.LabelSelectorValidationOptions{}, specPath.Child("allowSuperuserFrom"))...)
+
+	if len(allErrs) > 0 {
+		return warnings, apierrors.NewInvalid(GroupVersion.WithKind("StardogInstance").GroupKind(), r.Name, allErrs)
+	}
+	return warnings, nil
+}
+
+// validateServerUrl checks that the given URL is an absolute http or https URL with a host
+func validateServerUrl(path *field.Path, serverUrl string) *field.Error {
+	if serverUrl == "" {
+		return field.Required(path, "")
+	}
+	u, err := url.ParseRequestURI(serverUrl)
+	if err != nil {
+		return field.Invalid(path, serverUrl, err.Error())
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return field.NotSupported(path, u.Scheme, []string{"http", "https"})
+	}
+	if u.Host == "" {
+		return field.Invalid(path, serverUrl, "host is required")
+	}
+	return nil
+}
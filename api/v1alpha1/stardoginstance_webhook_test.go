@@ -0,0 +1,84 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_StardogInstanceWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name             string
+		spec             StardogInstanceSpec
+		expectedErr      bool
+		expectedWarnings int
+	}{
+		{
+			name:        "GivenValidSpec_WhenValidating_ThenAccept",
+			spec:        StardogInstanceSpec{ServerUrl: "https://stardog.example.com:5820/stardog", AdminCredentials: StardogUserCredentialsSpec{SecretRef: "admin"}},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenInvalidServerUrl_WhenValidating_ThenReject",
+			spec:        StardogInstanceSpec{ServerUrl: "stardog:5820", AdminCredentials: StardogUserCredentialsSpec{SecretRef: "admin"}},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenUnsupportedScheme_WhenValidating_ThenReject",
+			spec:        StardogInstanceSpec{ServerUrl: "ftp://stardog:5820", AdminCredentials: StardogUserCredentialsSpec{SecretRef: "admin"}},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenMissingAdminSecret_WhenValidating_ThenReject",
+			spec:        StardogInstanceSpec{ServerUrl: "http://stardog:5820"},
+			expectedErr: true,
+		},
+		{
+			name: "GivenCABundleWithBothRefs_WhenValidating_ThenReject",
+			spec: StardogInstanceSpec{ServerUrl: "https://stardog:5820", AdminCredentials: StardogUserCredentialsSpec{SecretRef: "admin"},
+				TLS: &StardogTLSSpec{CABundle: &StardogCABundleSpec{SecretRef: "ca", ConfigMapRef: "ca"}}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenAllowSuperuserFrom_WhenValidating_ThenAccept",
+			spec: StardogInstanceSpec{ServerUrl: "https://stardog:5820", AdminCredentials: StardogUserCredentialsSpec{SecretRef: "admin"},
+				AllowSuperuserFrom: &metav1.LabelSelector{MatchLabels: map[string]string{"stardog.vshn.ch/superusers": "true"}}},
+			expectedErr: false,
+		},
+		{
+			name: "GivenInvalidAllowSuperuserFrom_WhenValidating_ThenReject",
+			spec: StardogInstanceSpec{ServerUrl: "https://stardog:5820", AdminCredentials: StardogUserCredentialsSpec{SecretRef: "admin"},
+				AllowSuperuserFrom: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: metav1.LabelSelectorOpIn}}}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenInsecureSkipVerify_WhenValidating_ThenAcceptWithWarning",
+			spec: StardogInstanceSpec{ServerUrl: "https://stardog:5820", AdminCredentials: StardogUserCredentialsSpec{SecretRef: "admin"},
+				TLS: &StardogTLSSpec{InsecureSkipVerify: true}},
+			expectedErr:      false,
+			expectedWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &StardogInstance{ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "namespace"}, Spec: tt.spec}
+
+			warnings, err := (&stardogInstanceWebhook{}).ValidateCreate(context.Background(), instance)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+			assert.Len(t, warnings, tt.expectedWarnings)
+		})
+	}
+}
+
+func Test_StardogInstanceWebhook_Default(t *testing.T) {
+	instance := &StardogInstance{ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "namespace"}}
+
+	err := (&stardogInstanceWebhook{}).Default(context.Background(), instance)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "namespace", instance.Spec.AdminCredentials.Namespace)
+}
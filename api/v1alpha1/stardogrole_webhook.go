@@ -0,0 +1,110 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// StardogPermissionActions are the actions a StardogPermissionSpec can refer to
+var StardogPermissionActions = []string{"ALL", "CREATE", "DELETE", "READ", "WRITE", "GRANT", "REVOKE", "EXECUTE"}
+
+// StardogPermissionResourceTypes are the resource types a StardogPermissionSpec can refer to
+var StardogPermissionResourceTypes = []string{"DB", "USER", "ROLE", "ADMIN", "METADATA", "NAMED-GRAPH", "VIRTUAL-GRAPH",
+	"ICV-CONSTRAINTS", "SENSITIVE-PROPERTIES", "*"}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of the StardogRole
+func (r *StardogRole) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&stardogRoleWebhook{}).
+		WithValidator(&stardogRoleWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-stardog-vshn-ch-v1alpha1-stardogrole,mutating=true,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=stardogroles,verbs=create;update,versions=v1alpha1,name=mstardogrole.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-stardog-vshn-ch-v1alpha1-stardogrole,mutating=false,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=stardogroles,verbs=create;update,versions=v1alpha1,name=vstardogrole.kb.io,admissionReviewVersions=v1
+
+type stardogRoleWebhook struct{}
+
+// Default sets the role name to the name of the StardogRole
+func (w *stardogRoleWebhook) Default(_ context.Context, obj runtime.Object) error {
+	role, ok := obj.(*StardogRole)
+	if !ok {
+		return fmt.Errorf("expected a StardogRole but got %T", obj)
+	}
+	if role.Spec.RoleName == "" {
+		role.Spec.RoleName = role.Name
+	}
+	return nil
+}
+
+// ValidateCreate validates the StardogRole on creation
+func (w *stardogRoleWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	role, ok := obj.(*StardogRole)
+	if !ok {
+		return nil, fmt.Errorf("expected a StardogRole but got %T", obj)
+	}
+	return nil, role.validate()
+}
+
+// ValidateUpdate validates the StardogRole on update
+func (w *stardogRoleWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	role, ok := newObj.(*StardogRole)
+	if !ok {
+		return nil, fmt.Errorf("expected a StardogRole but got %T", newObj)
+	}
+	return nil, role.validate()
+}
+
+// ValidateDelete does not validate anything, deletion is guarded by finalizers
+func (w *stardogRoleWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *StardogRole) validate() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.StardogInstanceRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("stardogInstanceRef"), ""))
+	}
+	allErrs = append(allErrs, ValidatePermissions(specPath.Child("permissions"), r.Spec.Permissions)...)
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("StardogRole").GroupKind(), r.Name, allErrs)
+	}
+	return nil
+}
+
+// ValidatePermissions checks that every permission has a known action and resource type and at least one resource
+func ValidatePermissions(path *field.Path, permissions []StardogPermissionSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, permission := range permissions {
+		permissionPath := path.Index(i)
+		if !containsString(StardogPermissionActions, permission.Action) {
+			allErrs = append(allErrs, field.NotSupported(permissionPath.Child("action"), permission.Action, StardogPermissionActions))
+		}
+		if !containsString(StardogPermissionResourceTypes, permission.ResourceType) {
+			allErrs = append(allErrs, field.NotSupported(permissionPath.Child("resourceType"), permission.ResourceType, StardogPermissionResourceTypes))
+		}
+		if len(permission.Resources) == 0 {
+			allErrs = append(allErrs, field.Required(permissionPath.Child("resources"), "at least one resource is required"))
+		}
+	}
+	return allErrs
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_StardogRoleWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        StardogRoleSpec
+		expectedErr bool
+	}{
+		{
+			name: "GivenValidPermissions_WhenValidating_ThenAccept",
+			spec: StardogRoleSpec{StardogInstanceRef: "instance", Permissions: []StardogPermissionSpec{
+				{Action: "READ", ResourceType: "DB", Resources: []string{"db"}},
+				{Action: "ALL", ResourceType: "*", Resources: []string{"*"}},
+			}},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenMissingInstanceRef_WhenValidating_ThenReject",
+			spec:        StardogRoleSpec{},
+			expectedErr: true,
+		},
+		{
+			name: "GivenUnknownAction_WhenValidating_ThenReject",
+			spec: StardogRoleSpec{StardogInstanceRef: "instance", Permissions: []StardogPermissionSpec{
+				{Action: "UPDATE", ResourceType: "DB", Resources: []string{"db"}},
+			}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenUnknownResourceType_WhenValidating_ThenReject",
+			spec: StardogRoleSpec{StardogInstanceRef: "instance", Permissions: []StardogPermissionSpec{
+				{Action: "READ", ResourceType: "TABLE", Resources: []string{"db"}},
+			}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenNoResources_WhenValidating_ThenReject",
+			spec: StardogRoleSpec{StardogInstanceRef: "instance", Permissions: []StardogPermissionSpec{
+				{Action: "READ", ResourceType: "DB"},
+			}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := &StardogRole{ObjectMeta: metav1.ObjectMeta{Name: "role", Namespace: "namespace"}, Spec: tt.spec}
+
+			_, err := (&stardogRoleWebhook{}).ValidateCreate(context.Background(), role)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func Test_StardogRoleWebhook_Default(t *testing.T) {
+	role := &StardogRole{ObjectMeta: metav1.ObjectMeta{Name: "role", Namespace: "namespace"}}
+
+	err := (&stardogRoleWebhook{}).Default(context.Background(), role)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "role", role.Spec.RoleName)
+}
@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // StardogUserSpec defines the desired state of StardogUser
@@ -11,10 +12,32 @@ type StardogUserSpec struct {
 	StardogInstanceRef string `json:"stardogInstanceRef,omitempty"`
 	// StardogUserCredentialsSpec describes the credentials of a Stardog user
 	// +kubebuilder:validation:Required
-	Credentials StardogUserCredentialsSpec `json:"credentials,omitempty"`
+	Credentials StardogUserGeneratedCredentialsSpec `json:"credentials,omitempty"`
 	// Roles describe a list of StardogRoles assigned to a Stardog user. The names are referring the StardogRole metadata names, not the role name that is supposed to be in Stardog.
 	// +kubebuilder:validation:Optional
 	Roles []string `json:"roles,omitempty"`
+	// Permissions describe a list of permissions granted to the user directly, in addition to the ones of its roles.
+	// Permissions of the user which are not in this list are revoked, an empty list revokes all of them. The
+	// permissions granted to the user directly are not managed if the list is not set.
+	// +kubebuilder:validation:Optional
+	Permissions []StardogPermissionSpec `json:"permissions"`
+	// CredentialRotation rotates the password of generated credentials periodically.
+	// +kubebuilder:validation:Optional
+	CredentialRotation *CredentialRotationSpec `json:"credentialRotation,omitempty"`
+	// Superuser creates the user as a superuser. It is only honoured if the StardogInstance allows superusers from the
+	// namespace of the StardogUser and can only be set when the user is created in Stardog.
+	// +kubebuilder:validation:Optional
+	Superuser bool `json:"superuser,omitempty"`
+	// Enabled enables or disables the user in Stardog. A disabled user keeps its credentials and roles but cannot
+	// authenticate. Defaults to true.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// IsEnabled returns whether the user should be enabled in Stardog
+func (in *StardogUserSpec) IsEnabled() bool {
+	return in.Enabled == nil || *in.Enabled
 }
 
 // StardogUserCredentialsSpec specifies the password of a Stardog user
@@ -25,6 +48,51 @@ type StardogUserCredentialsSpec struct {
 	// SecretRef references the v1/Secret name which contains the "username" and "password" keys.
 	// +kubebuilder:validation:Required
 	SecretRef string `json:"secretRef,omitempty"`
+	// UsernameKey is the key of the username in the Secret. Defaults to "username".
+	// +kubebuilder:validation:Optional
+	UsernameKey string `json:"usernameKey,omitempty"`
+	// PasswordKey is the key of the password in the Secret. Defaults to "password".
+	// +kubebuilder:validation:Optional
+	PasswordKey string `json:"passwordKey,omitempty"`
+}
+
+// GetUsernameKey returns the key of the username in the Secret
+func (in StardogUserCredentialsSpec) GetUsernameKey() string {
+	if in.UsernameKey == "" {
+		return "username"
+	}
+	return in.UsernameKey
+}
+
+// GetPasswordKey returns the key of the password in the Secret
+func (in StardogUserCredentialsSpec) GetPasswordKey() string {
+	if in.PasswordKey == "" {
+		return "password"
+	}
+	return in.PasswordKey
+}
+
+// StardogUserGeneratedCredentialsSpec specifies the credentials of a StardogUser, which are either read from an
+// existing Secret or generated by the operator
+type StardogUserGeneratedCredentialsSpec struct {
+	StardogUserCredentialsSpec `json:",inline"`
+	// Generate lets the operator create the Secret referenced in SecretRef with a generated password. The Secret is
+	// owned by the StardogUser and recreated with a new password if it is deleted. The Secret has to be in the
+	// namespace of the StardogUser.
+	// +kubebuilder:validation:Optional
+	Generate bool `json:"generate,omitempty"`
+	// Username is the name of the user in the generated Secret. Defaults to .metadata.name.
+	// +kubebuilder:validation:Optional
+	Username string `json:"username,omitempty"`
+	// SecretTemplate defines the type, metadata and additional keys of the generated Secret.
+	// +kubebuilder:validation:Optional
+	SecretTemplate *CredentialSecretTemplate `json:"secretTemplate,omitempty"`
+}
+
+// CredentialsSecretVersion identifies a version of a credentials Secret
+type CredentialsSecretVersion struct {
+	UID             types.UID `json:"uid"`
+	ResourceVersion string    `json:"resourceVersion"`
 }
 
 // StardogUserStatus defines the observed state of StardogUser
@@ -32,6 +100,34 @@ type StardogUserStatus struct {
 	// Conditions contain the states of the StardogUser. A StardogUser is considered Ready when the user has been
 	// persisted to Stardog DB.
 	Conditions []StardogCondition `json:"conditions,omitempty" patchStrategy:"merge"`
+	// Username is the name of the user in Stardog, as read from the credentials Secret. This user is deleted from
+	// Stardog when the StardogUser is deleted.
+	Username string `json:"username,omitempty"`
+	// AppliedCredentials is the version of the credentials Secret which has been applied to Stardog last. The
+	// password is only changed in Stardog if the Secret has been changed or replaced since.
+	AppliedCredentials *CredentialsSecretVersion `json:"appliedCredentials,omitempty"`
+	// LastRotationTime is the time the password has been set in Stardog last.
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// Enabled is whether the user is enabled in Stardog.
+	Enabled *bool `json:"enabled,omitempty"`
+	// EffectivePermissions are all permissions of the user in Stardog, including the ones granted by its roles. They
+	// are refreshed on every reconciliation.
+	EffectivePermissions []EffectivePermission `json:"effectivePermissions,omitempty"`
+	// EffectivePermissionsRefreshTime is the time the effective permissions have been refreshed last.
+	EffectivePermissionsRefreshTime *metav1.Time `json:"effectivePermissionsRefreshTime,omitempty"`
+}
+
+// EffectivePermission is a permission of a user in Stardog and its origin
+type EffectivePermission struct {
+	// Action is the action of the permission
+	Action string `json:"action"`
+	// ResourceType is the type of resource of the permission
+	ResourceType string `json:"resourceType"`
+	// Resources are the targets of the permission
+	Resources []string `json:"resources,omitempty"`
+	// GrantedBy lists the roles of the user in Stardog granting the permission, either with an equal or a wildcard
+	// permission. Permissions granted to the user directly are marked with "user:<username>".
+	GrantedBy []string `json:"grantedBy,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,114 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of the StardogUser
+func (r *StardogUser) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&stardogUserWebhook{}).
+		WithValidator(&stardogUserWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-stardog-vshn-ch-v1alpha1-stardoguser,mutating=true,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=stardogusers,verbs=create;update,versions=v1alpha1,name=mstardoguser.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-stardog-vshn-ch-v1alpha1-stardoguser,mutating=false,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=stardogusers,verbs=create;update,versions=v1alpha1,name=vstardoguser.kb.io,admissionReviewVersions=v1
+
+type stardogUserWebhook struct{}
+
+// Default sets the namespace of the credentials to the namespace of the StardogUser. The generated Secret and its
+// username are named after the StardogUser unless they are set.
+func (w *stardogUserWebhook) Default(_ context.Context, obj runtime.Object) error {
+	user, ok := obj.(*StardogUser)
+	if !ok {
+		return fmt.Errorf("expected a StardogUser but got %T", obj)
+	}
+	if user.Spec.Credentials.Namespace == "" {
+		user.Spec.Credentials.Namespace = user.Namespace
+	}
+	if user.Spec.Credentials.Generate {
+		if user.Spec.Credentials.SecretRef == "" {
+			user.Spec.Credentials.SecretRef = user.Name
+		}
+		if user.Spec.Credentials.Username == "" {
+			user.Spec.Credentials.Username = user.Name
+		}
+	}
+	return nil
+}
+
+// ValidateCreate validates the StardogUser on creation
+func (w *stardogUserWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	user, ok := obj.(*StardogUser)
+	if !ok {
+		return nil, fmt.Errorf("expected a StardogUser but got %T", obj)
+	}
+	return nil, user.validate()
+}
+
+// ValidateUpdate validates the StardogUser on update
+func (w *stardogUserWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	user, ok := newObj.(*StardogUser)
+	if !ok {
+		return nil, fmt.Errorf("expected a StardogUser but got %T", newObj)
+	}
+	return nil, user.validate()
+}
+
+// ValidateDelete does not validate anything, deletion is guarded by finalizers
+func (w *stardogUserWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *StardogUser) validate() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.StardogInstanceRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("stardogInstanceRef"), ""))
+	}
+	if r.Spec.Credentials.SecretRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("credentials", "secretRef"), ""))
+	}
+	if r.Spec.Credentials.Generate && r.Spec.Credentials.Namespace != "" && r.Spec.Credentials.Namespace != r.Namespace {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("credentials", "namespace"), r.Spec.Credentials.Namespace,
+			"generated credentials must be in the namespace of the StardogUser"))
+	}
+	if !r.Spec.Credentials.Generate && r.Spec.Credentials.Username != "" {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("credentials", "username"), "only allowed for generated credentials"))
+	}
+	if !r.Spec.Credentials.Generate && r.Spec.CredentialRotation != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("credentialRotation"), "only allowed for generated credentials"))
+	}
+	allErrs = append(allErrs, r.Spec.CredentialRotation.Validate(specPath.Child("credentialRotation"))...)
+
+	credentialsPath := specPath.Child("credentials")
+	template := r.Spec.Credentials.SecretTemplate
+	if !r.Spec.Credentials.Generate && template != nil {
+		allErrs = append(allErrs, field.Forbidden(credentialsPath.Child("secretTemplate"), "only allowed for generated credentials"))
+	}
+	allErrs = append(allErrs, template.Validate(credentialsPath.Child("secretTemplate"), true)...)
+	if template != nil && template.Type == corev1.SecretTypeBasicAuth &&
+		(r.Spec.Credentials.GetUsernameKey() != corev1.BasicAuthUsernameKey || r.Spec.Credentials.GetPasswordKey() != corev1.BasicAuthPasswordKey) {
+		allErrs = append(allErrs, field.Invalid(credentialsPath, r.Spec.Credentials.SecretRef,
+			"the keys of a kubernetes.io/basic-auth Secret must be username and password"))
+	}
+	if r.Spec.Credentials.UsernameKey != "" && r.Spec.Credentials.UsernameKey == r.Spec.Credentials.GetPasswordKey() {
+		allErrs = append(allErrs, field.Invalid(credentialsPath.Child("usernameKey"), r.Spec.Credentials.UsernameKey, "must differ from passwordKey"))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("StardogUser").GroupKind(), r.Name, allErrs)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_StardogUserWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        StardogUserSpec
+		expectedErr bool
+	}{
+		{
+			name:        "GivenSecretRef_WhenValidating_ThenAccept",
+			spec:        StardogUserSpec{StardogInstanceRef: "instance", Credentials: createCredentials("namespace", "secret", false, "")},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenGeneratedCredentials_WhenValidating_ThenAccept",
+			spec:        StardogUserSpec{StardogInstanceRef: "instance", Credentials: createCredentials("namespace", "secret", true, "service")},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenGeneratedCredentialsInOtherNamespace_WhenValidating_ThenReject",
+			spec:        StardogUserSpec{StardogInstanceRef: "instance", Credentials: createCredentials("other", "secret", true, "")},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenUsernameWithoutGenerate_WhenValidating_ThenReject",
+			spec:        StardogUserSpec{StardogInstanceRef: "instance", Credentials: createCredentials("namespace", "secret", false, "service")},
+			expectedErr: true,
+		},
+		{
+			name: "GivenRotationWithInterval_WhenValidating_ThenAccept",
+			spec: StardogUserSpec{StardogInstanceRef: "instance", Credentials: createCredentials("namespace", "secret", true, ""),
+				CredentialRotation: &CredentialRotationSpec{Interval: &metav1.Duration{Duration: 2160 * time.Hour}}},
+			expectedErr: false,
+		},
+		{
+			name: "GivenRotationWithInvalidSchedule_WhenValidating_ThenReject",
+			spec: StardogUserSpec{StardogInstanceRef: "instance", Credentials: createCredentials("namespace", "secret", true, ""),
+				CredentialRotation: &CredentialRotationSpec{Schedule: "every day"}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenRotationWithIntervalAndSchedule_WhenValidating_ThenReject",
+			spec: StardogUserSpec{StardogInstanceRef: "instance", Credentials: createCredentials("namespace", "secret", true, ""),
+				CredentialRotation: &CredentialRotationSpec{Interval: &metav1.Duration{Duration: time.Hour}, Schedule: "@daily"}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenRotationWithoutGenerate_WhenValidating_ThenReject",
+			spec: StardogUserSpec{StardogInstanceRef: "instance", Credentials: createCredentials("namespace", "secret", false, ""),
+				CredentialRotation: &CredentialRotationSpec{Schedule: "@daily"}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenBasicAuthSecretTemplate_WhenValidating_ThenAccept",
+			spec: StardogUserSpec{StardogInstanceRef: "instance", Credentials: withSecretTemplate(createCredentials("namespace", "secret", true, ""),
+				&CredentialSecretTemplate{Type: corev1.SecretTypeBasicAuth, ConnectionDetails: true})},
+			expectedErr: false,
+		},
+		{
+			name: "GivenPerUserSecretTemplate_WhenValidating_ThenReject",
+			spec: StardogUserSpec{StardogInstanceRef: "instance", Credentials: withSecretTemplate(createCredentials("namespace", "secret", true, ""),
+				&CredentialSecretTemplate{PerUser: true})},
+			expectedErr: true,
+		},
+		{
+			name: "GivenSecretTemplateWithoutGenerate_WhenValidating_ThenReject",
+			spec: StardogUserSpec{StardogInstanceRef: "instance", Credentials: withSecretTemplate(createCredentials("namespace", "secret", false, ""),
+				&CredentialSecretTemplate{Type: corev1.SecretTypeOpaque})},
+			expectedErr: true,
+		},
+		{
+			name: "GivenBasicAuthSecretTemplateWithMappedKeys_WhenValidating_ThenReject",
+			spec: StardogUserSpec{StardogInstanceRef: "instance", Credentials: withKeys(withSecretTemplate(createCredentials("namespace", "secret", true, ""),
+				&CredentialSecretTemplate{Type: corev1.SecretTypeBasicAuth}), "user", "pass")},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenSameUsernameAndPasswordKey_WhenValidating_ThenReject",
+			spec:        StardogUserSpec{StardogInstanceRef: "instance", Credentials: withKeys(createCredentials("namespace", "secret", false, ""), "key", "key")},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenMissingSecretRef_WhenValidating_ThenReject",
+			spec:        StardogUserSpec{StardogInstanceRef: "instance"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &StardogUser{ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: "namespace"}, Spec: tt.spec}
+
+			_, err := (&stardogUserWebhook{}).ValidateCreate(context.Background(), user)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func Test_StardogUserWebhook_Default(t *testing.T) {
+	user := &StardogUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: "namespace"},
+		Spec:       StardogUserSpec{Credentials: createCredentials("", "", true, "")},
+	}
+
+	err := (&stardogUserWebhook{}).Default(context.Background(), user)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "namespace", user.Spec.Credentials.Namespace)
+	assert.Equal(t, "user", user.Spec.Credentials.SecretRef)
+	assert.Equal(t, "user", user.Spec.Credentials.Username)
+}
+
+func createCredentials(namespace, secretRef string, generate bool, username string) StardogUserGeneratedCredentialsSpec {
+	return StardogUserGeneratedCredentialsSpec{
+		StardogUserCredentialsSpec: StardogUserCredentialsSpec{Namespace: namespace, SecretRef: secretRef},
+		Generate:                   generate,
+		Username:                   username,
+	}
+}
+
+func withSecretTemplate(credentials StardogUserGeneratedCredentialsSpec, template *CredentialSecretTemplate) StardogUserGeneratedCredentialsSpec {
+	credentials.SecretTemplate = template
+	return credentials
+}
+
+func withKeys(credentials StardogUserGeneratedCredentialsSpec, usernameKey, passwordKey string) StardogUserGeneratedCredentialsSpec {
+	credentials.UsernameKey = usernameKey
+	credentials.PasswordKey = passwordKey
+	return credentials
+}
@@ -10,9 +10,134 @@ http://www.apache.org/licenses/LICENSE-2.0
 package v1alpha1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialRotationSpec) DeepCopyInto(out *CredentialRotationSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RolloutSelector != nil {
+		in, out := &in.RolloutSelector, &out.RolloutSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialRotationSpec.
+func (in *CredentialRotationSpec) DeepCopy() *CredentialRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialSecretTemplate) DeepCopyInto(out *CredentialSecretTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialSecretTemplate.
+func (in *CredentialSecretTemplate) DeepCopy() *CredentialSecretTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialSecretTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsSecretVersion) DeepCopyInto(out *CredentialsSecretVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsSecretVersion.
+func (in *CredentialsSecretVersion) DeepCopy() *CredentialsSecretVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsSecretVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EffectivePermission) DeepCopyInto(out *EffectivePermission) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GrantedBy != nil {
+		in, out := &in.GrantedBy, &out.GrantedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EffectivePermission.
+func (in *EffectivePermission) DeepCopy() *EffectivePermission {
+	if in == nil {
+		return nil
+	}
+	out := new(EffectivePermission)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StardogCABundleSpec) DeepCopyInto(out *StardogCABundleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StardogCABundleSpec.
+func (in *StardogCABundleSpec) DeepCopy() *StardogCABundleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StardogCABundleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StardogClientCertificateSpec) DeepCopyInto(out *StardogClientCertificateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StardogClientCertificateSpec.
+func (in *StardogClientCertificateSpec) DeepCopy() *StardogClientCertificateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StardogClientCertificateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StardogCondition) DeepCopyInto(out *StardogCondition) {
 	*out = *in
@@ -55,7 +180,7 @@ func (in *StardogInstance) DeepCopyInto(out *StardogInstance) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -113,6 +238,16 @@ func (in *StardogInstanceList) DeepCopyObject() runtime.Object {
 func (in *StardogInstanceSpec) DeepCopyInto(out *StardogInstanceSpec) {
 	*out = *in
 	out.AdminCredentials = in.AdminCredentials
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(StardogTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowSuperuserFrom != nil {
+		in, out := &in.AllowSuperuserFrom, &out.AllowSuperuserFrom
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StardogInstanceSpec.
@@ -270,6 +405,31 @@ func (in *StardogRoleStatus) DeepCopy() *StardogRoleStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StardogTLSSpec) DeepCopyInto(out *StardogTLSSpec) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(StardogCABundleSpec)
+		**out = **in
+	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(StardogClientCertificateSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StardogTLSSpec.
+func (in *StardogTLSSpec) DeepCopy() *StardogTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StardogTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StardogUser) DeepCopyInto(out *StardogUser) {
 	*out = *in
@@ -312,6 +472,27 @@ func (in *StardogUserCredentialsSpec) DeepCopy() *StardogUserCredentialsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StardogUserGeneratedCredentialsSpec) DeepCopyInto(out *StardogUserGeneratedCredentialsSpec) {
+	*out = *in
+	out.StardogUserCredentialsSpec = in.StardogUserCredentialsSpec
+	if in.SecretTemplate != nil {
+		in, out := &in.SecretTemplate, &out.SecretTemplate
+		*out = new(CredentialSecretTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StardogUserGeneratedCredentialsSpec.
+func (in *StardogUserGeneratedCredentialsSpec) DeepCopy() *StardogUserGeneratedCredentialsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StardogUserGeneratedCredentialsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StardogUserList) DeepCopyInto(out *StardogUserList) {
 	*out = *in
@@ -347,12 +528,29 @@ func (in *StardogUserList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StardogUserSpec) DeepCopyInto(out *StardogUserSpec) {
 	*out = *in
-	out.Credentials = in.Credentials
+	in.Credentials.DeepCopyInto(&out.Credentials)
 	if in.Roles != nil {
 		in, out := &in.Roles, &out.Roles
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]StardogPermissionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CredentialRotation != nil {
+		in, out := &in.CredentialRotation, &out.CredentialRotation
+		*out = new(CredentialRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StardogUserSpec.
@@ -375,6 +573,31 @@ func (in *StardogUserStatus) DeepCopyInto(out *StardogUserStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AppliedCredentials != nil {
+		in, out := &in.AppliedCredentials, &out.AppliedCredentials
+		*out = new(CredentialsSecretVersion)
+		**out = **in
+	}
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EffectivePermissions != nil {
+		in, out := &in.EffectivePermissions, &out.EffectivePermissions
+		*out = make([]EffectivePermission, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EffectivePermissionsRefreshTime != nil {
+		in, out := &in.EffectivePermissionsRefreshTime, &out.EffectivePermissionsRefreshTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StardogUserStatus.
@@ -0,0 +1,55 @@
+package v1beta1
+
+import (
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArchivedDatabaseSpec defines the Stardog database which has been archived
+type ArchivedDatabaseSpec struct {
+	// DatabaseName is the name of the archived database in the Stardog server
+	DatabaseName string `json:"databaseName,omitempty"`
+
+	// AddUserForNonHiddenGraphs is the custom user of the archived database, which has been disabled with the read and
+	// write users
+	AddUserForNonHiddenGraphs string `json:"addUserForNonHiddenGraphs,omitempty"`
+
+	// StardogInstanceRefs contains the Stardog instances the database has been taken offline on
+	StardogInstanceRefs []StardogInstanceRef `json:"stardogInstanceRefs,omitempty"`
+
+	// ExpirationTime is the time after which the database is dropped from the Stardog instances
+	ExpirationTime metav1.Time `json:"expirationTime"`
+}
+
+// ArchivedDatabaseStatus defines the observed state of the ArchivedDatabase
+type ArchivedDatabaseStatus struct {
+	Conditions []v1alpha1.StardogCondition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// ArchivedDatabase is the Schema for the archiveddatabases API. It is created by the operator when a Database with the
+// Archive deletion policy is deleted. The database is kept offline with its users disabled until the expiration time
+// and then dropped. Creating a Database with the same database name before brings the database back online.
+type ArchivedDatabase struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ArchivedDatabaseSpec   `json:"spec,omitempty"`
+	Status ArchivedDatabaseStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ArchivedDatabaseList contains a list of ArchivedDatabase
+type ArchivedDatabaseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ArchivedDatabase `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ArchivedDatabase{}, &ArchivedDatabaseList{})
+}
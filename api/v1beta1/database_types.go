@@ -1,7 +1,10 @@
 package v1beta1
 
 import (
+	"time"
+
 	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -14,9 +17,52 @@ type StardogInstanceRef struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// OptionsUpdatePolicy defines how changed options are applied to an existing database
+type OptionsUpdatePolicy string
+
+const (
+	// OptionsUpdateOnlineOnly only applies options which can be changed while the database is online
+	OptionsUpdateOnlineOnly OptionsUpdatePolicy = "OnlineOnly"
+	// OptionsUpdateOfflineAllowed takes the database offline to apply options which cannot be changed while it is online
+	OptionsUpdateOfflineAllowed OptionsUpdatePolicy = "OfflineAllowed"
+)
+
+// DatabaseState is the state of a database in the Stardog server
+type DatabaseState string
+
+const (
+	// DatabaseOnline means that the database is online and can be queried
+	DatabaseOnline DatabaseState = "Online"
+	// DatabaseOffline means that the database is offline, e.g. for maintenance
+	DatabaseOffline DatabaseState = "Offline"
+)
+
+// DeletionPolicy defines what happens to the Stardog database when the Database is deleted or a Stardog instance is
+// removed from it
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain keeps the database and its users and roles on the Stardog servers
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyDeleteIfEmpty drops the database only if it does not contain any triples
+	DeletionPolicyDeleteIfEmpty DeletionPolicy = "DeleteIfEmpty"
+	// DeletionPolicyBackupThenDelete backs up the database and drops it once the backup has completed
+	DeletionPolicyBackupThenDelete DeletionPolicy = "BackupThenDelete"
+	// DeletionPolicyForce drops the database regardless of its size
+	DeletionPolicyForce DeletionPolicy = "Force"
+	// DeletionPolicyArchive takes the database offline, disables its users and drops it after the archive grace period
+	DeletionPolicyArchive DeletionPolicy = "Archive"
+)
+
+// defaultArchiveGracePeriod is the time an archived database is kept if the Database does not specify it
+const defaultArchiveGracePeriod = 7 * 24 * time.Hour
+
 // DatabaseSpec defines the desired state of the Database
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.databaseName) || has(self.databaseName)",message="databaseName is immutable"
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.namedGraphPrefix) || has(self.namedGraphPrefix)",message="namedGraphPrefix is immutable"
 type DatabaseSpec struct {
 	//+kubebuilder:validation:required
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="databaseName is immutable"
 	// DatabaseName the database name that has to be created in the Stardog server
 	DatabaseName string `json:"databaseName,omitempty"`
 
@@ -26,26 +72,89 @@ type DatabaseSpec struct {
 	AddUserForNonHiddenGraphs string `json:"addUserForNonHiddenGraphs,omitempty"`
 
 	//+kubebuilder:validation:optional
-	// Options is the Stardog configuration options for this database. Only json input is valid.
-	Options string `json:"options,omitempty"`
+	// Options are the Stardog configuration options for this database, e.g. "search.enabled": true. The names are
+	// validated against the configuration properties known to the Stardog server.
+	Options map[string]apiextensionsv1.JSON `json:"options,omitempty"`
+
+	//+kubebuilder:validation:optional
+	//+kubebuilder:validation:Enum=OnlineOnly;OfflineAllowed
+	//+kubebuilder:default=OnlineOnly
+	// OptionsUpdatePolicy defines how changed options are applied to an existing database. OnlineOnly applies options
+	// which can be changed while the database is online, OfflineAllowed takes the database offline to apply the
+	// remaining ones. Immutable options are never applied.
+	OptionsUpdatePolicy OptionsUpdatePolicy `json:"optionsUpdatePolicy,omitempty"`
+
+	//+kubebuilder:validation:optional
+	//+kubebuilder:validation:Enum=Online;Offline
+	//+kubebuilder:default=Online
+	// State is the desired state of the database on all its Stardog instances. A database which is found offline
+	// unexpectedly is brought back online. Options which cannot be changed while the database is online are applied
+	// directly while it is Offline.
+	State DatabaseState `json:"state,omitempty"`
+
+	//+kubebuilder:validation:optional
+	//+kubebuilder:validation:Enum=Retain;DeleteIfEmpty;BackupThenDelete;Force;Archive
+	//+kubebuilder:default=DeleteIfEmpty
+	// DeletionPolicy defines what happens to the Stardog database when the Database is deleted or a Stardog instance is
+	// removed from StardogInstanceRefs. Retain keeps the
+	// database, users and roles, DeleteIfEmpty only drops an empty database, BackupThenDelete drops the database once a
+	// DatabaseBackup to DeletionBackupLocation has completed and Force drops the database regardless of its size.
+	// Archive takes the database offline, disables its users and records it in an ArchivedDatabase, which drops it
+	// once the ArchiveGracePeriod has expired.
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	//+kubebuilder:validation:optional
+	// ArchiveGracePeriod is the time the database is kept offline by the Archive deletion policy before it is dropped.
+	// Defaults to 168h.
+	ArchiveGracePeriod *metav1.Duration `json:"archiveGracePeriod,omitempty"`
+
+	//+kubebuilder:validation:optional
+	// DeletionBackupLocation is the directory on the Stardog servers the backup of the BackupThenDelete deletion policy
+	// is written to. It should not be the location of a DatabaseBackupSchedule, whose backups Stardog rotates.
+	DeletionBackupLocation string `json:"deletionBackupLocation,omitempty"`
+
+	//+kubebuilder:validation:optional
+	// SecretTemplate defines the layout, type, metadata and additional keys of the credential Secrets
+	SecretTemplate *v1alpha1.CredentialSecretTemplate `json:"secretTemplate,omitempty"`
+
+	//+kubebuilder:validation:optional
+	// CredentialRotation rotates the passwords of the read, write and custom users created for the Database periodically
+	CredentialRotation *v1alpha1.CredentialRotationSpec `json:"credentialRotation,omitempty"`
 
 	//+kubebuilder:validation:required
 	// StardogInstanceRefs contains the reference to the Stardog instance the database should exist in
 	StardogInstanceRefs []StardogInstanceRef `json:"stardogInstanceRefs,omitempty"`
 
 	//+kubebuilder:validation:required
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="namedGraphPrefix is immutable"
 	// NamedGraphPrefix a prefix for a Stardog Named Graph.
 	NamedGraphPrefix string `json:"namedGraphPrefix,omitempty"`
 }
 
+// DatabaseInstanceStatus describes the database on one Stardog instance
+type DatabaseInstanceStatus struct {
+	StardogInstanceRef StardogInstanceRef `json:"stardogInstanceRef"`
+	// State is the live state of the database on the Stardog instance
+	State DatabaseState `json:"state,omitempty"`
+	// Triples is the approximate number of triples in the database on the Stardog instance
+	// +optional
+	Triples *int64 `json:"triples,omitempty"`
+	// StatisticsRefreshTime is the time the statistics of the database have been refreshed last
+	// +optional
+	StatisticsRefreshTime *metav1.Time `json:"statisticsRefreshTime,omitempty"`
+}
+
 // DatabaseStatus defines the observed state of the Database
 type DatabaseStatus struct {
-	DatabaseName              string                      `json:"databaseName,omitempty"`
-	AddUserForNonHiddenGraphs string                      `json:"addUserForNonHiddenGraphs,omitempty"`
-	NamedGraphPrefix          string                      `json:"namedGraphPrefix,omitempty"`
-	Options                   string                      `json:"options,omitempty"`
-	StardogInstanceRefs       []StardogInstanceRef        `json:"stardogInstanceRef,omitempty"`
-	Conditions                []v1alpha1.StardogCondition `json:"conditions,omitempty"`
+	DatabaseName              string                          `json:"databaseName,omitempty"`
+	AddUserForNonHiddenGraphs string                          `json:"addUserForNonHiddenGraphs,omitempty"`
+	NamedGraphPrefix          string                          `json:"namedGraphPrefix,omitempty"`
+	Options                   map[string]apiextensionsv1.JSON `json:"options,omitempty"`
+	StardogInstanceRefs       []StardogInstanceRef            `json:"stardogInstanceRef,omitempty"`
+	LastCredentialRotation    *metav1.Time                    `json:"lastCredentialRotation,omitempty"`
+	// Instances contains the state of the database on each of its Stardog instances
+	Instances  []DatabaseInstanceStatus    `json:"instances,omitempty"`
+	Conditions []v1alpha1.StardogCondition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -70,6 +179,30 @@ type DatabaseList struct {
 	Items           []Database `json:"items"`
 }
 
+// GetDeletionPolicy returns the deletion policy of the Database, which defaults to DeleteIfEmpty
+func (in *Database) GetDeletionPolicy() DeletionPolicy {
+	if in.Spec.DeletionPolicy == "" {
+		return DeletionPolicyDeleteIfEmpty
+	}
+	return in.Spec.DeletionPolicy
+}
+
+// GetState returns the desired state of the database, which defaults to Online
+func (in *Database) GetState() DatabaseState {
+	if in.Spec.State == "" {
+		return DatabaseOnline
+	}
+	return in.Spec.State
+}
+
+// GetArchiveGracePeriod returns the time the database is kept offline by the Archive deletion policy
+func (in *Database) GetArchiveGracePeriod() time.Duration {
+	if in.Spec.ArchiveGracePeriod == nil {
+		return defaultArchiveGracePeriod
+	}
+	return in.Spec.ArchiveGracePeriod.Duration
+}
+
 // NewStardogInstanceRef creates a new StardogInstanceRef from name and namespace
 func NewStardogInstanceRef(name, namespace string) StardogInstanceRef {
 	return StardogInstanceRef{
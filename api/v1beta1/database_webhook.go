@@ -0,0 +1,215 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of the Database
+func (r *Database) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&DatabaseWebhook{}).
+		WithValidator(&DatabaseWebhook{Client: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-stardog-vshn-ch-v1beta1-database,mutating=true,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=databases,verbs=create;update,versions=v1beta1,name=mdatabase.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-stardog-vshn-ch-v1beta1-database,mutating=false,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=databases,verbs=create;update,versions=v1beta1,name=vdatabase.kb.io,admissionReviewVersions=v1
+
+// DatabaseWebhook defaults and validates Databases. The Client is used to find other Databases with the same name.
+// +kubebuilder:object:generate=false
+type DatabaseWebhook struct {
+	Client client.Reader
+}
+
+// Default sets the database name to the name of the Database
+func (w *DatabaseWebhook) Default(_ context.Context, obj runtime.Object) error {
+	database, ok := obj.(*Database)
+	if !ok {
+		return fmt.Errorf("expected a Database but got %T", obj)
+	}
+	if database.Spec.DatabaseName == "" {
+		database.Spec.DatabaseName = database.Name
+	}
+	return nil
+}
+
+// ValidateCreate validates the Database on creation
+func (w *DatabaseWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	database, ok := obj.(*Database)
+	if !ok {
+		return nil, fmt.Errorf("expected a Database but got %T", obj)
+	}
+	return nil, w.validate(ctx, nil, database)
+}
+
+// ValidateUpdate validates the Database on update and rejects changes to immutable fields
+func (w *DatabaseWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldDatabase, ok := oldObj.(*Database)
+	if !ok {
+		return nil, fmt.Errorf("expected a Database but got %T", oldObj)
+	}
+	database, ok := newObj.(*Database)
+	if !ok {
+		return nil, fmt.Errorf("expected a Database but got %T", newObj)
+	}
+	return nil, w.validate(ctx, oldDatabase, database)
+}
+
+// ValidateDelete does not validate anything, deletion is guarded by finalizers
+func (w *DatabaseWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *DatabaseWebhook) validate(ctx context.Context, oldDatabase, database *Database) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	spec := database.Spec
+
+	if oldDatabase != nil {
+		allErrs = append(allErrs, validateImmutable(specPath.Child("databaseName"), oldDatabase.Spec.DatabaseName, spec.DatabaseName)...)
+		allErrs = append(allErrs, validateImmutable(specPath.Child("namedGraphPrefix"), oldDatabase.Spec.NamedGraphPrefix, spec.NamedGraphPrefix)...)
+	}
+
+	if spec.DatabaseName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("databaseName"), ""))
+	}
+	if spec.NamedGraphPrefix == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("namedGraphPrefix"), ""))
+	}
+	for name, value := range spec.Options {
+		if strings.TrimSpace(name) == "" {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("options"), name, "option names must not be empty"))
+		}
+		if len(value.Raw) == 0 || string(value.Raw) == "null" {
+			allErrs = append(allErrs, field.Required(specPath.Child("options").Key(name), "option values must not be null"))
+		}
+	}
+
+	if spec.OptionsUpdatePolicy != "" && spec.OptionsUpdatePolicy != OptionsUpdateOnlineOnly && spec.OptionsUpdatePolicy != OptionsUpdateOfflineAllowed {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("optionsUpdatePolicy"), spec.OptionsUpdatePolicy,
+			[]string{string(OptionsUpdateOnlineOnly), string(OptionsUpdateOfflineAllowed)}))
+	}
+
+	if spec.State != "" && spec.State != DatabaseOnline && spec.State != DatabaseOffline {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("state"), spec.State, []string{string(DatabaseOnline), string(DatabaseOffline)}))
+	}
+
+	switch spec.DeletionPolicy {
+	case "", DeletionPolicyRetain, DeletionPolicyDeleteIfEmpty, DeletionPolicyForce, DeletionPolicyArchive:
+	case DeletionPolicyBackupThenDelete:
+		if spec.DeletionBackupLocation == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("deletionBackupLocation"), "required by the BackupThenDelete deletion policy"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("deletionPolicy"), spec.DeletionPolicy,
+			[]string{string(DeletionPolicyRetain), string(DeletionPolicyDeleteIfEmpty), string(DeletionPolicyBackupThenDelete),
+				string(DeletionPolicyForce), string(DeletionPolicyArchive)}))
+	}
+	if spec.ArchiveGracePeriod != nil && spec.ArchiveGracePeriod.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("archiveGracePeriod"), spec.ArchiveGracePeriod.Duration.String(), "must be positive"))
+	}
+
+	allErrs = append(allErrs, spec.CredentialRotation.Validate(specPath.Child("credentialRotation"))...)
+	allErrs = append(allErrs, spec.SecretTemplate.Validate(specPath.Child("secretTemplate"), false)...)
+
+	refsPath := specPath.Child("stardogInstanceRefs")
+	if len(spec.StardogInstanceRefs) == 0 {
+		allErrs = append(allErrs, field.Required(refsPath, "at least one instance is required"))
+	}
+	seen := make(map[StardogInstanceRef]bool)
+	for i, ref := range spec.StardogInstanceRefs {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(refsPath.Index(i).Child("name"), ""))
+		}
+		if ref.Namespace == "" {
+			allErrs = append(allErrs, field.Required(refsPath.Index(i).Child("namespace"), ""))
+		}
+		if seen[ref] {
+			allErrs = append(allErrs, field.Duplicate(refsPath.Index(i), ref))
+		}
+		seen[ref] = true
+	}
+
+	if len(allErrs) == 0 && requiresUniqueDatabaseNameCheck(oldDatabase, database) {
+		errs, err := w.validateUniqueDatabaseName(ctx, database)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, errs...)
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("Database").GroupKind(), database.Name, allErrs)
+	}
+	return nil
+}
+
+// requiresUniqueDatabaseNameCheck returns true if the Database is created or its database name or StardogInstances
+// change. Other updates, e.g. removing the finalizer of a deleted Database, must not be blocked by an existing
+// duplicate.
+func requiresUniqueDatabaseNameCheck(oldDatabase, database *Database) bool {
+	if database.GetDeletionTimestamp() != nil {
+		return false
+	}
+	if oldDatabase == nil {
+		return true
+	}
+	return oldDatabase.Spec.DatabaseName != database.Spec.DatabaseName ||
+		!reflect.DeepEqual(oldDatabase.Spec.StardogInstanceRefs, database.Spec.StardogInstanceRefs)
+}
+
+// validateUniqueDatabaseName rejects a Database if another Database creates a database with the same name on any of
+// its StardogInstances
+func (w *DatabaseWebhook) validateUniqueDatabaseName(ctx context.Context, database *Database) (field.ErrorList, error) {
+	if w.Client == nil {
+		return nil, nil
+	}
+	databases := &DatabaseList{}
+	if err := w.Client.List(ctx, databases); err != nil {
+		return nil, fmt.Errorf("cannot list Databases: %v", err)
+	}
+
+	var allErrs field.ErrorList
+	for _, other := range databases.Items {
+		if other.Name == database.Name || other.Spec.DatabaseName != database.Spec.DatabaseName {
+			continue
+		}
+		for _, ref := range database.Spec.StardogInstanceRefs {
+			if containsStardogInstanceRef(other.Spec.StardogInstanceRefs, ref) {
+				allErrs = append(allErrs, field.Duplicate(field.NewPath("spec", "databaseName"),
+					fmt.Sprintf("%s (already used by Database %s on StardogInstance %s/%s)",
+						database.Spec.DatabaseName, other.Name, ref.Namespace, ref.Name)))
+				break
+			}
+		}
+	}
+	return allErrs, nil
+}
+
+// validateImmutable rejects any change of a field once it has been set
+func validateImmutable(path *field.Path, oldValue, newValue string) field.ErrorList {
+	if oldValue != "" && oldValue != newValue {
+		return field.ErrorList{field.Forbidden(path, fmt.Sprintf("field is immutable, cannot change it from %s to %s", oldValue, newValue))}
+	}
+	return nil
+}
+
+func containsStardogInstanceRef(refs []StardogInstanceRef, ref StardogInstanceRef) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_DatabaseWebhook_Validate(t *testing.T) {
+	instance := NewStardogInstanceRef("instance", "namespace")
+	otherInstance := NewStardogInstanceRef("other-instance", "namespace")
+
+	tests := []struct {
+		name        string
+		spec        DatabaseSpec
+		existing    []Database
+		expectedErr bool
+	}{
+		{
+			name:        "GivenValidSpec_WhenValidating_ThenAccept",
+			spec:        createDatabaseSpec("db", map[string]apiextensionsv1.JSON{"search.enabled": {Raw: []byte(`true`)}}, instance),
+			expectedErr: false,
+		},
+		{
+			name:        "GivenNullOptionValue_WhenValidating_ThenReject",
+			spec:        createDatabaseSpec("db", map[string]apiextensionsv1.JSON{"search.enabled": {Raw: []byte(`null`)}}, instance),
+			expectedErr: true,
+		},
+		{
+			name:        "GivenEmptyOptionName_WhenValidating_ThenReject",
+			spec:        createDatabaseSpec("db", map[string]apiextensionsv1.JSON{" ": {Raw: []byte(`true`)}}, instance),
+			expectedErr: true,
+		},
+		{
+			name: "GivenPerUserBasicAuthSecretTemplate_WhenValidating_ThenAccept",
+			spec: DatabaseSpec{DatabaseName: "db", StardogInstanceRefs: []StardogInstanceRef{instance}, NamedGraphPrefix: "https://example.com",
+				SecretTemplate: &v1alpha1.CredentialSecretTemplate{PerUser: true, Type: corev1.SecretTypeBasicAuth, ConnectionDetails: true}},
+			expectedErr: false,
+		},
+		{
+			name: "GivenSharedBasicAuthSecretTemplate_WhenValidating_ThenReject",
+			spec: DatabaseSpec{DatabaseName: "db", StardogInstanceRefs: []StardogInstanceRef{instance}, NamedGraphPrefix: "https://example.com",
+				SecretTemplate: &v1alpha1.CredentialSecretTemplate{Type: corev1.SecretTypeBasicAuth}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenBackupThenDeleteWithLocation_WhenValidating_ThenAccept",
+			spec: DatabaseSpec{DatabaseName: "db", StardogInstanceRefs: []StardogInstanceRef{instance}, NamedGraphPrefix: "https://example.com",
+				DeletionPolicy: DeletionPolicyBackupThenDelete, DeletionBackupLocation: "/var/opt/stardog/backups"},
+			expectedErr: false,
+		},
+		{
+			name: "GivenBackupThenDeleteWithoutLocation_WhenValidating_ThenReject",
+			spec: DatabaseSpec{DatabaseName: "db", StardogInstanceRefs: []StardogInstanceRef{instance}, NamedGraphPrefix: "https://example.com",
+				DeletionPolicy: DeletionPolicyBackupThenDelete},
+			expectedErr: true,
+		},
+		{
+			name: "GivenArchiveWithNegativeGracePeriod_WhenValidating_ThenReject",
+			spec: DatabaseSpec{DatabaseName: "db", StardogInstanceRefs: []StardogInstanceRef{instance}, NamedGraphPrefix: "https://example.com",
+				DeletionPolicy: DeletionPolicyArchive, ArchiveGracePeriod: &metav1.Duration{Duration: -time.Hour}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenUnknownState_WhenValidating_ThenReject",
+			spec: DatabaseSpec{DatabaseName: "db", StardogInstanceRefs: []StardogInstanceRef{instance}, NamedGraphPrefix: "https://example.com",
+				State: "Paused"},
+			expectedErr: true,
+		},
+		{
+			name: "GivenUnknownDeletionPolicy_WhenValidating_ThenReject",
+			spec: DatabaseSpec{DatabaseName: "db", StardogInstanceRefs: []StardogInstanceRef{instance}, NamedGraphPrefix: "https://example.com",
+				DeletionPolicy: "Orphan"},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenNoInstanceRefs_WhenValidating_ThenReject",
+			spec:        createDatabaseSpec("db", nil),
+			expectedErr: true,
+		},
+		{
+			name:        "GivenInstanceRefWithoutNamespace_WhenValidating_ThenReject",
+			spec:        createDatabaseSpec("db", nil, NewStardogInstanceRef("instance", "")),
+			expectedErr: true,
+		},
+		{
+			name:        "GivenSameDatabaseNameOnSameInstance_WhenValidating_ThenReject",
+			spec:        createDatabaseSpec("db", nil, instance),
+			existing:    []Database{{ObjectMeta: metav1.ObjectMeta{Name: "existing"}, Spec: createDatabaseSpec("db", nil, otherInstance, instance)}},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenSameDatabaseNameOnOtherInstance_WhenValidating_ThenAccept",
+			spec:        createDatabaseSpec("db", nil, instance),
+			existing:    []Database{{ObjectMeta: metav1.ObjectMeta{Name: "existing"}, Spec: createDatabaseSpec("db", nil, otherInstance)}},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenSameDatabaseOnUpdate_WhenValidating_ThenAccept",
+			spec:        createDatabaseSpec("db", nil, instance),
+			existing:    []Database{{ObjectMeta: metav1.ObjectMeta{Name: "database"}, Spec: createDatabaseSpec("db", nil, instance)}},
+			expectedErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			assert.NoError(t, AddToScheme(s))
+			builder := fake.NewClientBuilder().WithScheme(s)
+			for i := range tt.existing {
+				builder = builder.WithObjects(&tt.existing[i])
+			}
+			database := &Database{ObjectMeta: metav1.ObjectMeta{Name: "database"}, Spec: tt.spec}
+
+			_, err := (&DatabaseWebhook{Client: builder.Build()}).ValidateCreate(context.Background(), database)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func Test_DatabaseWebhook_Default(t *testing.T) {
+	database := &Database{ObjectMeta: metav1.ObjectMeta{Name: "database"}}
+
+	err := (&DatabaseWebhook{}).Default(context.Background(), database)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "database", database.Spec.DatabaseName)
+}
+
+func createDatabaseSpec(databaseName string, options map[string]apiextensionsv1.JSON, refs ...StardogInstanceRef) DatabaseSpec {
+	return DatabaseSpec{
+		DatabaseName:        databaseName,
+		Options:             options,
+		StardogInstanceRefs: refs,
+		NamedGraphPrefix:    "https://example.com",
+	}
+}
+
+func Test_DatabaseWebhook_ValidateUpdate(t *testing.T) {
+	instance := NewStardogInstanceRef("instance", "namespace")
+	otherInstance := NewStardogInstanceRef("other-instance", "namespace")
+	oldSpec := createDatabaseSpec("db", nil, instance)
+	duplicate := Database{ObjectMeta: metav1.ObjectMeta{Name: "duplicate"}, Spec: createDatabaseSpec("db", nil, instance, otherInstance)}
+	deletionTimestamp := metav1.Now()
+
+	tests := []struct {
+		name              string
+		spec              DatabaseSpec
+		existing          []Database
+		deletionTimestamp *metav1.Time
+		expectedErr       bool
+	}{
+		{
+			name:        "GivenAddedInstance_WhenUpdating_ThenAccept",
+			spec:        createDatabaseSpec("db", nil, instance, otherInstance),
+			expectedErr: false,
+		},
+		{
+			name:        "GivenChangedDatabaseName_WhenUpdating_ThenReject",
+			spec:        createDatabaseSpec("other-db", nil, instance),
+			expectedErr: true,
+		},
+		{
+			name: "GivenChangedNamedGraphPrefix_WhenUpdating_ThenReject",
+			spec: DatabaseSpec{DatabaseName: "db", StardogInstanceRefs: []StardogInstanceRef{instance},
+				NamedGraphPrefix: "https://other.example.com"},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenDuplicateDatabaseName_WhenAddingInstance_ThenReject",
+			spec:        createDatabaseSpec("db", nil, instance, NewStardogInstanceRef("third-instance", "namespace")),
+			existing:    []Database{duplicate},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenDuplicateDatabaseName_WhenInstancesAreUnchanged_ThenAccept",
+			spec:        createDatabaseSpec("db", nil, instance),
+			existing:    []Database{duplicate},
+			expectedErr: false,
+		},
+		{
+			name:              "GivenDuplicateDatabaseName_WhenDeleting_ThenAccept",
+			spec:              createDatabaseSpec("db", nil, instance, NewStardogInstanceRef("third-instance", "namespace")),
+			existing:          []Database{duplicate},
+			deletionTimestamp: &deletionTimestamp,
+			expectedErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			assert.NoError(t, AddToScheme(s))
+			builder := fake.NewClientBuilder().WithScheme(s)
+			for i := range tt.existing {
+				builder = builder.WithObjects(&tt.existing[i])
+			}
+			oldDatabase := &Database{ObjectMeta: metav1.ObjectMeta{Name: "database"}, Spec: oldSpec}
+			database := &Database{ObjectMeta: metav1.ObjectMeta{Name: "database", DeletionTimestamp: tt.deletionTimestamp}, Spec: tt.spec}
+
+			_, err := (&DatabaseWebhook{Client: builder.Build()}).ValidateUpdate(context.Background(), oldDatabase, database)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
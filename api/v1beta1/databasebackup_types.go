@@ -0,0 +1,98 @@
+package v1beta1
+
+import (
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DatabaseBackupSpec defines the desired state of the DatabaseBackup
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type DatabaseBackupSpec struct {
+	// +kubebuilder:validation:required
+	// DatabaseRef is the name of the Database that is backed up
+	DatabaseRef string `json:"databaseRef,omitempty"`
+
+	// +kubebuilder:validation:required
+	// Location is the directory on the Stardog servers the backup is written to. The backups of all DatabaseBackups with
+	// the same location are written to <location>/<instance namespace>/<instance name>, where Stardog creates a new
+	// subdirectory of <database name> for each backup and removes the oldest ones according to its
+	// backup.keep.last.number.backups server property.
+	Location string `json:"location,omitempty"`
+
+	// StardogInstanceRefs are the Stardog instances the database is backed up on. All instances of the Database are
+	// backed up if it is empty.
+	// +optional
+	StardogInstanceRefs []StardogInstanceRef `json:"stardogInstanceRefs,omitempty"`
+}
+
+// DatabaseBackupPhase is the state of a DatabaseBackup
+type DatabaseBackupPhase string
+
+const (
+	// DatabaseBackupRunning means that the database is being backed up on its instances
+	DatabaseBackupRunning DatabaseBackupPhase = "Running"
+	// DatabaseBackupCompleted means that the database has been backed up on all its instances
+	DatabaseBackupCompleted DatabaseBackupPhase = "Completed"
+	// DatabaseBackupFailed means that a Stardog server rejected the backup, e.g. because the location is invalid or
+	// the database does not exist. A failed backup is not retried.
+	DatabaseBackupFailed DatabaseBackupPhase = "Failed"
+)
+
+// DatabaseBackupInstanceStatus describes the backup of the database on one Stardog instance
+type DatabaseBackupInstanceStatus struct {
+	StardogInstanceRef StardogInstanceRef `json:"stardogInstanceRef"`
+	// Location is the directory on the Stardog server containing the backup
+	Location string `json:"location,omitempty"`
+	// Triples is the approximate number of triples of the database at the time of the backup. It is not set if the
+	// number of triples could not be retrieved.
+	Triples *int64 `json:"triples,omitempty"`
+	// Duration is the time the Stardog server took to create the backup
+	Duration metav1.Duration `json:"duration"`
+	// CompletionTime is the time the backup finished
+	CompletionTime metav1.Time `json:"completionTime"`
+}
+
+// DatabaseBackupStatus defines the observed state of the DatabaseBackup
+type DatabaseBackupStatus struct {
+	// Phase is the state of the backup
+	Phase DatabaseBackupPhase `json:"phase,omitempty"`
+	// DatabaseName is the name of the backed up database in the Stardog server
+	DatabaseName string `json:"databaseName,omitempty"`
+	// StartTime is the time the backup has been started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the backup has finished on all instances
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Instances contains the backups of the database on each of its Stardog instances
+	Instances []DatabaseBackupInstanceStatus `json:"instances,omitempty"`
+	// SkippedInstances are the Stardog instances which have not been backed up yet because they are disabled. The
+	// backup is not completed until all of them have been backed up.
+	SkippedInstances []StardogInstanceRef        `json:"skippedInstances,omitempty"`
+	Conditions       []v1alpha1.StardogCondition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// DatabaseBackup is the Schema for the databasebackups API. It backs up a Database once on all its Stardog instances.
+// Deleting a DatabaseBackup does not delete the backup files, they are rotated by the Stardog servers.
+type DatabaseBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseBackupSpec   `json:"spec,omitempty"`
+	Status DatabaseBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DatabaseBackupList contains a list of DatabaseBackup
+type DatabaseBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DatabaseBackup{}, &DatabaseBackupList{})
+}
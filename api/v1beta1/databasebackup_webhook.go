@@ -0,0 +1,64 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook of the DatabaseBackup
+func (r *DatabaseBackup) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&databaseBackupWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-stardog-vshn-ch-v1beta1-databasebackup,mutating=false,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=databasebackups,verbs=create;update,versions=v1beta1,name=vdatabasebackup.kb.io,admissionReviewVersions=v1
+
+type databaseBackupWebhook struct{}
+
+// ValidateCreate validates the DatabaseBackup on creation
+func (w *databaseBackupWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	backup, ok := obj.(*DatabaseBackup)
+	if !ok {
+		return nil, fmt.Errorf("expected a DatabaseBackup but got %T", obj)
+	}
+	return nil, backup.validate()
+}
+
+// ValidateUpdate validates the DatabaseBackup on update, the spec itself is immutable
+func (w *databaseBackupWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	backup, ok := newObj.(*DatabaseBackup)
+	if !ok {
+		return nil, fmt.Errorf("expected a DatabaseBackup but got %T", newObj)
+	}
+	return nil, backup.validate()
+}
+
+// ValidateDelete does not validate anything
+func (w *databaseBackupWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *DatabaseBackup) validate() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.DatabaseRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("databaseRef"), ""))
+	}
+	if r.Spec.Location == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("location"), ""))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("DatabaseBackup").GroupKind(), r.Name, allErrs)
+	}
+	return nil
+}
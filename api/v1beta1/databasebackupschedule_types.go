@@ -0,0 +1,91 @@
+package v1beta1
+
+import (
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DatabaseBackupScheduleLabel is set on the DatabaseBackups created by a DatabaseBackupSchedule and contains its name
+const DatabaseBackupScheduleLabel = "stardog.vshn.ch/backup-schedule"
+
+// DatabaseBackupScheduleSpec defines the desired state of the DatabaseBackupSchedule
+type DatabaseBackupScheduleSpec struct {
+	// +kubebuilder:validation:required
+	// DatabaseRef is the name of the Database that is backed up
+	DatabaseRef string `json:"databaseRef,omitempty"`
+
+	// +kubebuilder:validation:required
+	// Schedule is the cron expression (minute, hour, day of month, month, day of week) the backups are created at.
+	// Times are in UTC. The macros @hourly, @daily, @weekly, @monthly, @yearly and @every <duration> are supported as
+	// well.
+	Schedule string `json:"schedule,omitempty"`
+
+	// +kubebuilder:validation:required
+	// Location is the directory on the Stardog servers the backups are written to. All backups of the schedule are
+	// written to the same directory per instance, so Stardog's backup rotation applies to them.
+	Location string `json:"location,omitempty"`
+
+	// +kubebuilder:validation:optional
+	// +kubebuilder:default=false
+	// Suspend stops the creation of new backups, existing backups are still pruned
+	Suspend bool `json:"suspend"`
+
+	// +kubebuilder:validation:optional
+	// Retention defines which completed and failed DatabaseBackups are pruned
+	Retention DatabaseBackupRetention `json:"retention,omitempty"`
+}
+
+// DatabaseBackupRetention defines how long the DatabaseBackups of a DatabaseBackupSchedule are kept. Pruning deletes
+// the DatabaseBackup resources. The backup files are written to one directory per database and instance, in which
+// Stardog keeps the number of backups set by its backup.keep.last.number.backups server property. Set it to keepLast
+// to keep the backup files of all DatabaseBackups of the schedule.
+type DatabaseBackupRetention struct {
+	// +kubebuilder:validation:optional
+	// +kubebuilder:validation:Minimum=0
+	// KeepLast is the number of completed backups that are kept. 0 keeps all of them.
+	KeepLast int32 `json:"keepLast,omitempty"`
+
+	// +kubebuilder:validation:optional
+	// MaxAge is the duration completed backups are kept, e.g. 168h. The newest completed backup is always kept.
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// DatabaseBackupScheduleStatus defines the observed state of the DatabaseBackupSchedule
+type DatabaseBackupScheduleStatus struct {
+	// LastScheduleTime is the time the last DatabaseBackup has been created
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastSuccessfulTime is the completion time of the newest completed DatabaseBackup
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// NextScheduleTime is the time the next DatabaseBackup will be created
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+	// Backups are the names of the existing DatabaseBackups created by this schedule, ordered from oldest to newest
+	Backups    []string                    `json:"backups,omitempty"`
+	Conditions []v1alpha1.StardogCondition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// DatabaseBackupSchedule is the Schema for the databasebackupschedules API. It creates DatabaseBackups of a Database
+// according to a cron schedule and prunes old DatabaseBackups according to the retention.
+type DatabaseBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseBackupScheduleSpec   `json:"spec,omitempty"`
+	Status DatabaseBackupScheduleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DatabaseBackupScheduleList contains a list of DatabaseBackupSchedule
+type DatabaseBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseBackupSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DatabaseBackupSchedule{}, &DatabaseBackupScheduleList{})
+}
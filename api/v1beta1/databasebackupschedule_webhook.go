@@ -0,0 +1,78 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook of the DatabaseBackupSchedule
+func (r *DatabaseBackupSchedule) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&databaseBackupScheduleWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-stardog-vshn-ch-v1beta1-databasebackupschedule,mutating=false,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=databasebackupschedules,verbs=create;update,versions=v1beta1,name=vdatabasebackupschedule.kb.io,admissionReviewVersions=v1
+
+type databaseBackupScheduleWebhook struct{}
+
+// ValidateCreate validates the DatabaseBackupSchedule on creation
+func (w *databaseBackupScheduleWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	schedule, ok := obj.(*DatabaseBackupSchedule)
+	if !ok {
+		return nil, fmt.Errorf("expected a DatabaseBackupSchedule but got %T", obj)
+	}
+	return nil, schedule.validate()
+}
+
+// ValidateUpdate validates the DatabaseBackupSchedule on update
+func (w *databaseBackupScheduleWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	schedule, ok := newObj.(*DatabaseBackupSchedule)
+	if !ok {
+		return nil, fmt.Errorf("expected a DatabaseBackupSchedule but got %T", newObj)
+	}
+	return nil, schedule.validate()
+}
+
+// ValidateDelete does not validate anything
+func (w *databaseBackupScheduleWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *DatabaseBackupSchedule) validate() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	spec := r.Spec
+
+	if spec.DatabaseRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("databaseRef"), ""))
+	}
+	if spec.Location == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("location"), ""))
+	}
+	if spec.Schedule == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("schedule"), ""))
+	} else if _, err := cron.ParseStandard(spec.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("schedule"), spec.Schedule, err.Error()))
+	}
+	retentionPath := specPath.Child("retention")
+	if spec.Retention.KeepLast < 0 {
+		allErrs = append(allErrs, field.Invalid(retentionPath.Child("keepLast"), spec.Retention.KeepLast, "must not be negative"))
+	}
+	if spec.Retention.MaxAge != nil && spec.Retention.MaxAge.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(retentionPath.Child("maxAge"), spec.Retention.MaxAge.Duration.String(), "must be positive"))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("DatabaseBackupSchedule").GroupKind(), r.Name, allErrs)
+	}
+	return nil
+}
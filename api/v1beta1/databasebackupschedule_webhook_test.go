@@ -0,0 +1,56 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_DatabaseBackupScheduleWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        DatabaseBackupScheduleSpec
+		expectedErr bool
+	}{
+		{
+			name: "GivenValidSchedule_WhenValidating_ThenAccept",
+			spec: DatabaseBackupScheduleSpec{DatabaseRef: "database", Location: "/backup", Schedule: "0 2 * * *",
+				Retention: DatabaseBackupRetention{KeepLast: 7, MaxAge: &metav1.Duration{Duration: 168 * time.Hour}}},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenMacro_WhenValidating_ThenAccept",
+			spec:        DatabaseBackupScheduleSpec{DatabaseRef: "database", Location: "/backup", Schedule: "@daily"},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenInvalidCronExpression_WhenValidating_ThenReject",
+			spec:        DatabaseBackupScheduleSpec{DatabaseRef: "database", Location: "/backup", Schedule: "0 25 * * *"},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenMissingLocation_WhenValidating_ThenReject",
+			spec:        DatabaseBackupScheduleSpec{DatabaseRef: "database", Schedule: "@daily"},
+			expectedErr: true,
+		},
+		{
+			name: "GivenNegativeMaxAge_WhenValidating_ThenReject",
+			spec: DatabaseBackupScheduleSpec{DatabaseRef: "database", Location: "/backup", Schedule: "@daily",
+				Retention: DatabaseBackupRetention{MaxAge: &metav1.Duration{Duration: -time.Hour}}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &DatabaseBackupSchedule{ObjectMeta: metav1.ObjectMeta{Name: "schedule"}, Spec: tt.spec}
+
+			_, err := (&databaseBackupScheduleWebhook{}).ValidateCreate(context.Background(), schedule)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package v1beta1
+
+import (
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DatabaseMigrationAnnotation is set on resources created by a DatabaseMigration and contains its name
+const DatabaseMigrationAnnotation = "stardog.vshn.ch/database-migration"
+
+// DatabaseMigrationSpec defines the desired state of the DatabaseMigration
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type DatabaseMigrationSpec struct {
+	// +kubebuilder:validation:required
+	// SourceDatabaseRef is the name of the Database that is migrated
+	SourceDatabaseRef string `json:"sourceDatabaseRef,omitempty"`
+
+	// +kubebuilder:validation:required
+	// Target describes the Database the source is migrated to
+	Target DatabaseMigrationTarget `json:"target,omitempty"`
+
+	// +kubebuilder:validation:required
+	// BackupLocation is the directory on the Stardog servers the backup of the source database is written to and
+	// restored from. It has to be accessible by the source and all target instances, e.g. a shared volume.
+	BackupLocation string `json:"backupLocation,omitempty"`
+
+	// +kubebuilder:validation:optional
+	// +kubebuilder:default=false
+	// DropSource drops the source database and deletes the source Database once the migration succeeded
+	DropSource bool `json:"dropSource"`
+}
+
+// DatabaseMigrationTarget describes the database a DatabaseMigration creates
+type DatabaseMigrationTarget struct {
+	// +kubebuilder:validation:required
+	// DatabaseRef is the name of the Database created for the migrated database
+	DatabaseRef string `json:"databaseRef,omitempty"`
+
+	// +kubebuilder:validation:optional
+	// DatabaseName is the new name of the database in the Stardog server. Defaults to the name of the source database.
+	DatabaseName string `json:"databaseName,omitempty"`
+
+	// +kubebuilder:validation:optional
+	// StardogInstanceRefs contains the references to the Stardog instances the database is migrated to. Defaults to
+	// the instances of the source Database.
+	StardogInstanceRefs []StardogInstanceRef `json:"stardogInstanceRefs,omitempty"`
+}
+
+// DatabaseMigrationPhase is the step a DatabaseMigration is currently executing
+type DatabaseMigrationPhase string
+
+const (
+	// DatabaseMigrationPending means that the migration has not been started yet
+	DatabaseMigrationPending DatabaseMigrationPhase = "Pending"
+	// DatabaseMigrationBackingUp means that the source database is being backed up
+	DatabaseMigrationBackingUp DatabaseMigrationPhase = "BackingUp"
+	// DatabaseMigrationRestoring means that the backup is being restored under the target name and instances
+	DatabaseMigrationRestoring DatabaseMigrationPhase = "Restoring"
+	// DatabaseMigrationSynchronizing means that the target Database is created and its users and roles are synchronized
+	DatabaseMigrationSynchronizing DatabaseMigrationPhase = "Synchronizing"
+	// DatabaseMigrationRepointing means that the Organizations of the source Database are moved to the target Database
+	DatabaseMigrationRepointing DatabaseMigrationPhase = "RepointingOrganizations"
+	// DatabaseMigrationDroppingSource means that the source database and Database are being deleted
+	DatabaseMigrationDroppingSource DatabaseMigrationPhase = "DroppingSource"
+	// DatabaseMigrationCompleted means that the migration has finished successfully
+	DatabaseMigrationCompleted DatabaseMigrationPhase = "Completed"
+)
+
+// DatabaseMigrationStatus defines the observed state of the DatabaseMigration
+type DatabaseMigrationStatus struct {
+	// Phase is the step the migration is currently executing
+	Phase DatabaseMigrationPhase `json:"phase,omitempty"`
+	// SourceDatabaseName is the name of the migrated database in the Stardog server
+	SourceDatabaseName string `json:"sourceDatabaseName,omitempty"`
+	// BackupLocation is the directory on the Stardog servers the backup is restored from
+	BackupLocation string `json:"backupLocation,omitempty"`
+	// Organizations are the names of the Organizations moved to the target Database
+	Organizations []string                    `json:"organizations,omitempty"`
+	Conditions    []v1alpha1.StardogCondition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// DatabaseMigration is the Schema for the databasemigrations API. It moves a Database to a new name or to other
+// Stardog instances.
+type DatabaseMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseMigrationSpec   `json:"spec,omitempty"`
+	Status DatabaseMigrationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DatabaseMigrationList contains a list of DatabaseMigration
+type DatabaseMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DatabaseMigration{}, &DatabaseMigrationList{})
+}
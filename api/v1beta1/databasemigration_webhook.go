@@ -0,0 +1,84 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook of the DatabaseMigration
+func (r *DatabaseMigration) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&databaseMigrationWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-stardog-vshn-ch-v1beta1-databasemigration,mutating=false,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=databasemigrations,verbs=create;update,versions=v1beta1,name=vdatabasemigration.kb.io,admissionReviewVersions=v1
+
+type databaseMigrationWebhook struct{}
+
+// ValidateCreate validates the DatabaseMigration on creation
+func (w *databaseMigrationWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	migration, ok := obj.(*DatabaseMigration)
+	if !ok {
+		return nil, fmt.Errorf("expected a DatabaseMigration but got %T", obj)
+	}
+	return nil, migration.validate()
+}
+
+// ValidateUpdate validates the DatabaseMigration on update, the spec itself is immutable
+func (w *databaseMigrationWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	migration, ok := newObj.(*DatabaseMigration)
+	if !ok {
+		return nil, fmt.Errorf("expected a DatabaseMigration but got %T", newObj)
+	}
+	return nil, migration.validate()
+}
+
+// ValidateDelete does not validate anything
+func (w *databaseMigrationWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *DatabaseMigration) validate() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	spec := r.Spec
+
+	if spec.SourceDatabaseRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("sourceDatabaseRef"), ""))
+	}
+	if spec.BackupLocation == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("backupLocation"), ""))
+	}
+	targetPath := specPath.Child("target")
+	if spec.Target.DatabaseRef == "" {
+		allErrs = append(allErrs, field.Required(targetPath.Child("databaseRef"), ""))
+	} else if spec.Target.DatabaseRef == spec.SourceDatabaseRef {
+		allErrs = append(allErrs, field.Invalid(targetPath.Child("databaseRef"), spec.Target.DatabaseRef,
+			"must differ from the source Database"))
+	}
+	if spec.Target.DatabaseName == "" && len(spec.Target.StardogInstanceRefs) == 0 {
+		allErrs = append(allErrs, field.Required(targetPath,
+			"a new databaseName or stardogInstanceRefs are required to migrate the database"))
+	}
+	for i, ref := range spec.Target.StardogInstanceRefs {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(targetPath.Child("stardogInstanceRefs").Index(i).Child("name"), ""))
+		}
+		if ref.Namespace == "" {
+			allErrs = append(allErrs, field.Required(targetPath.Child("stardogInstanceRefs").Index(i).Child("namespace"), ""))
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("DatabaseMigration").GroupKind(), r.Name, allErrs)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_DatabaseMigrationWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        DatabaseMigrationSpec
+		expectedErr bool
+	}{
+		{
+			name: "GivenRename_WhenValidating_ThenAccept",
+			spec: DatabaseMigrationSpec{SourceDatabaseRef: "database", BackupLocation: "/backup",
+				Target: DatabaseMigrationTarget{DatabaseRef: "new-database", DatabaseName: "new-db"}},
+			expectedErr: false,
+		},
+		{
+			name: "GivenMoveToOtherInstance_WhenValidating_ThenAccept",
+			spec: DatabaseMigrationSpec{SourceDatabaseRef: "database", BackupLocation: "/backup",
+				Target: DatabaseMigrationTarget{DatabaseRef: "new-database", StardogInstanceRefs: []StardogInstanceRef{NewStardogInstanceRef("instance", "namespace")}}},
+			expectedErr: false,
+		},
+		{
+			name: "GivenSameDatabaseRef_WhenValidating_ThenReject",
+			spec: DatabaseMigrationSpec{SourceDatabaseRef: "database", BackupLocation: "/backup",
+				Target: DatabaseMigrationTarget{DatabaseRef: "database", DatabaseName: "new-db"}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenNeitherNameNorInstances_WhenValidating_ThenReject",
+			spec: DatabaseMigrationSpec{SourceDatabaseRef: "database", BackupLocation: "/backup",
+				Target: DatabaseMigrationTarget{DatabaseRef: "new-database"}},
+			expectedErr: true,
+		},
+		{
+			name: "GivenMissingBackupLocation_WhenValidating_ThenReject",
+			spec: DatabaseMigrationSpec{SourceDatabaseRef: "database",
+				Target: DatabaseMigrationTarget{DatabaseRef: "new-database", DatabaseName: "new-db"}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migration := &DatabaseMigration{ObjectMeta: metav1.ObjectMeta{Name: "migration"}, Spec: tt.spec}
+
+			_, err := (&databaseMigrationWebhook{}).ValidateCreate(context.Background(), migration)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package v1beta1
+
+import (
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DatabaseRestoreAnnotation is set on a Database while a DatabaseRestore restores it and contains the name of the
+// DatabaseRestore. The Database is not synchronized while the annotation refers to a running DatabaseRestore.
+const DatabaseRestoreAnnotation = "stardog.vshn.ch/database-restore"
+
+// DatabaseRestoreSpec defines the desired state of the DatabaseRestore
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+// +kubebuilder:validation:XValidation:rule="has(self.backupRef) != has(self.location)",message="exactly one of backupRef or location is required"
+type DatabaseRestoreSpec struct {
+	// +kubebuilder:validation:required
+	// DatabaseRef is the name of the Database whose database is overwritten by the backup
+	DatabaseRef string `json:"databaseRef,omitempty"`
+
+	// +kubebuilder:validation:optional
+	// BackupRef is the name of a completed DatabaseBackup. Each instance is restored from the backup of that instance.
+	BackupRef string `json:"backupRef,omitempty"`
+
+	// +kubebuilder:validation:optional
+	// Location is the directory on the Stardog servers containing the backup of the database. It is used for all
+	// instances of the Database.
+	Location string `json:"location,omitempty"`
+}
+
+// DatabaseRestorePhase is the step a DatabaseRestore is currently executing
+type DatabaseRestorePhase string
+
+const (
+	// DatabaseRestorePending means that the restore has not been started yet
+	DatabaseRestorePending DatabaseRestorePhase = "Pending"
+	// DatabaseRestoreRestoring means that the backup is being restored on the instances of the Database
+	DatabaseRestoreRestoring DatabaseRestorePhase = "Restoring"
+	// DatabaseRestoreSynchronizing means that the users, roles and permissions of the Database and its Organizations
+	// are applied again
+	DatabaseRestoreSynchronizing DatabaseRestorePhase = "Synchronizing"
+	// DatabaseRestoreCompleted means that the restore has finished successfully
+	DatabaseRestoreCompleted DatabaseRestorePhase = "Completed"
+)
+
+// DatabaseRestoreStatus defines the observed state of the DatabaseRestore
+type DatabaseRestoreStatus struct {
+	// Phase is the step the restore is currently executing
+	Phase DatabaseRestorePhase `json:"phase,omitempty"`
+	// DatabaseName is the name of the restored database in the Stardog server
+	DatabaseName string `json:"databaseName,omitempty"`
+	// RestoredInstances are the Stardog instances the backup has been restored on
+	RestoredInstances []StardogInstanceRef `json:"restoredInstances,omitempty"`
+	// StartTime is the time the restore has been started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the restore has finished
+	CompletionTime *metav1.Time                `json:"completionTime,omitempty"`
+	Conditions     []v1alpha1.StardogCondition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// DatabaseRestore is the Schema for the databaserestores API. It restores a backup into the database of a Database
+// and applies the users, roles and permissions of the Database and its Organizations again.
+type DatabaseRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseRestoreSpec   `json:"spec,omitempty"`
+	Status DatabaseRestoreStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DatabaseRestoreList contains a list of DatabaseRestore
+type DatabaseRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DatabaseRestore{}, &DatabaseRestoreList{})
+}
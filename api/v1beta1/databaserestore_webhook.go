@@ -0,0 +1,68 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook of the DatabaseRestore
+func (r *DatabaseRestore) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&databaseRestoreWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-stardog-vshn-ch-v1beta1-databaserestore,mutating=false,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=databaserestores,verbs=create;update,versions=v1beta1,name=vdatabaserestore.kb.io,admissionReviewVersions=v1
+
+type databaseRestoreWebhook struct{}
+
+// ValidateCreate validates the DatabaseRestore on creation
+func (w *databaseRestoreWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	restore, ok := obj.(*DatabaseRestore)
+	if !ok {
+		return nil, fmt.Errorf("expected a DatabaseRestore but got %T", obj)
+	}
+	return nil, restore.validate()
+}
+
+// ValidateUpdate validates the DatabaseRestore on update, the spec itself is immutable
+func (w *databaseRestoreWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	restore, ok := newObj.(*DatabaseRestore)
+	if !ok {
+		return nil, fmt.Errorf("expected a DatabaseRestore but got %T", newObj)
+	}
+	return nil, restore.validate()
+}
+
+// ValidateDelete does not validate anything
+func (w *databaseRestoreWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *DatabaseRestore) validate() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	spec := r.Spec
+
+	if spec.DatabaseRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("databaseRef"), ""))
+	}
+	if spec.BackupRef == "" && spec.Location == "" {
+		allErrs = append(allErrs, field.Required(specPath, "either backupRef or location is required"))
+	}
+	if spec.BackupRef != "" && spec.Location != "" {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("location"), "cannot be combined with backupRef"))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("DatabaseRestore").GroupKind(), r.Name, allErrs)
+	}
+	return nil
+}
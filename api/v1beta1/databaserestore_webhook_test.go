@@ -0,0 +1,53 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_DatabaseRestoreWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        DatabaseRestoreSpec
+		expectedErr bool
+	}{
+		{
+			name:        "GivenBackupRef_WhenValidating_ThenAccept",
+			spec:        DatabaseRestoreSpec{DatabaseRef: "database", BackupRef: "backup"},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenLocation_WhenValidating_ThenAccept",
+			spec:        DatabaseRestoreSpec{DatabaseRef: "database", Location: "/backup/database"},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenBackupRefAndLocation_WhenValidating_ThenReject",
+			spec:        DatabaseRestoreSpec{DatabaseRef: "database", BackupRef: "backup", Location: "/backup/database"},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenNoSource_WhenValidating_ThenReject",
+			spec:        DatabaseRestoreSpec{DatabaseRef: "database"},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenMissingDatabaseRef_WhenValidating_ThenReject",
+			spec:        DatabaseRestoreSpec{BackupRef: "backup"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := &DatabaseRestore{ObjectMeta: metav1.ObjectMeta{Name: "restore"}, Spec: tt.spec}
+
+			_, err := (&databaseRestoreWebhook{}).ValidateCreate(context.Background(), restore)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
@@ -7,8 +7,12 @@ import (
 )
 
 // OrganizationSpec defines the desired state of an Organization
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.name) || has(self.name)",message="name is immutable"
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.databaseRef) || has(self.databaseRef)",message="databaseRef is required"
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.databaseRef) || !has(self.databaseRef) || self.databaseRef == oldSelf.databaseRef || (has(self.databaseMigrationRef) && (!has(oldSelf.databaseMigrationRef) || self.databaseMigrationRef != oldSelf.databaseMigrationRef))",message="databaseRef is immutable, use a DatabaseMigration to move the organization"
 type OrganizationSpec struct {
 	// +kubebuilder:validation:required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="name is immutable"
 	// Name is the short name of an organization
 	Name string `json:"name,omitempty"`
 
@@ -17,9 +21,23 @@ type OrganizationSpec struct {
 	DisplayName string `json:"displayName,omitempty"`
 
 	// +kubebuilder:validation:required
-	// DatabaseRef is the name of the Database this Organization is assigned to
+	// DatabaseRef is the name of the Database this Organization is assigned to. It can only be changed together with
+	// DatabaseMigrationRef by a DatabaseMigration.
 	DatabaseRef string `json:"databaseRef,omitempty"`
 
+	// +kubebuilder:validation:optional
+	// DatabaseMigrationRef is the name of the DatabaseMigration that moved this Organization to its Database. It is set
+	// by the DatabaseMigration and must not be set manually.
+	DatabaseMigrationRef string `json:"databaseMigrationRef,omitempty"`
+
+	// +kubebuilder:validation:optional
+	// SecretTemplate defines the layout, type, metadata and additional keys of the credential Secrets
+	SecretTemplate *v1alpha1.CredentialSecretTemplate `json:"secretTemplate,omitempty"`
+
+	// +kubebuilder:validation:optional
+	// CredentialRotation rotates the passwords of the user created for the Organization periodically
+	CredentialRotation *v1alpha1.CredentialRotationSpec `json:"credentialRotation,omitempty"`
+
 	// +kubebuilder:validation:required
 	// NamedGraphs are the suffix graph names for this organization. The prefix can be found in the Database resource.
 	// The final graphs is defined as prefix + "/" + orgName + "/" suffix
@@ -40,12 +58,13 @@ type NamedGraph struct {
 
 // OrganizationStatus defines the observed state of the Organization
 type OrganizationStatus struct {
-	Name                string                      `json:"name,omitempty"`
-	DisplayName         string                      `json:"displayName,omitempty"`
-	DatabaseRef         string                      `json:"databaseRef,omitempty"`
-	NamedGraphs         []NamedGraph                `json:"namedGraphs,omitempty"`
-	StardogInstanceRefs []StardogInstanceRef        `json:"stardogInstanceRefs,omitempty"`
-	Conditions          []v1alpha1.StardogCondition `json:"conditions,omitempty"`
+	Name                   string                      `json:"name,omitempty"`
+	DisplayName            string                      `json:"displayName,omitempty"`
+	DatabaseRef            string                      `json:"databaseRef,omitempty"`
+	NamedGraphs            []NamedGraph                `json:"namedGraphs,omitempty"`
+	StardogInstanceRefs    []StardogInstanceRef        `json:"stardogInstanceRefs,omitempty"`
+	LastCredentialRotation *metav1.Time                `json:"lastCredentialRotation,omitempty"`
+	Conditions             []v1alpha1.StardogCondition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,163 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of the Organization
+func (r *Organization) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&OrganizationWebhook{}).
+		WithValidator(&OrganizationWebhook{Client: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-stardog-vshn-ch-v1beta1-organization,mutating=true,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=organizations,verbs=create;update,versions=v1beta1,name=morganization.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-stardog-vshn-ch-v1beta1-organization,mutating=false,failurePolicy=fail,sideEffects=None,groups=stardog.vshn.ch,resources=organizations,verbs=create;update,versions=v1beta1,name=vorganization.kb.io,admissionReviewVersions=v1
+
+// OrganizationWebhook defaults and validates Organizations. The Client is used to verify the DatabaseMigration which
+// moves an Organization to another Database.
+// +kubebuilder:object:generate=false
+type OrganizationWebhook struct {
+	Client client.Reader
+}
+
+// Default sets the organization name to the name of the Organization and the display name to the organization name.
+// NamedGraph.AddHidden defaults to false through the CRD schema.
+func (w *OrganizationWebhook) Default(_ context.Context, obj runtime.Object) error {
+	organization, ok := obj.(*Organization)
+	if !ok {
+		return fmt.Errorf("expected an Organization but got %T", obj)
+	}
+	if organization.Spec.Name == "" {
+		organization.Spec.Name = organization.Name
+	}
+	if organization.Spec.DisplayName == "" {
+		organization.Spec.DisplayName = organization.Spec.Name
+	}
+	return nil
+}
+
+// ValidateCreate validates the Organization on creation
+func (w *OrganizationWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	organization, ok := obj.(*Organization)
+	if !ok {
+		return nil, fmt.Errorf("expected an Organization but got %T", obj)
+	}
+	return nil, organization.validate(nil)
+}
+
+// ValidateUpdate validates the Organization on update and rejects changes to immutable fields. The databaseRef may
+// only be changed by the DatabaseMigration named in the databaseMigrationRef.
+func (w *OrganizationWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldOrganization, ok := oldObj.(*Organization)
+	if !ok {
+		return nil, fmt.Errorf("expected an Organization but got %T", oldObj)
+	}
+	organization, ok := newObj.(*Organization)
+	if !ok {
+		return nil, fmt.Errorf("expected an Organization but got %T", newObj)
+	}
+	if oldOrganization.Spec.DatabaseRef != organization.Spec.DatabaseRef {
+		if err := w.validateMigration(ctx, oldOrganization, organization); err != nil {
+			return nil, err
+		}
+		oldOrganization = oldOrganization.DeepCopy()
+		oldOrganization.Spec.DatabaseRef = organization.Spec.DatabaseRef
+	}
+	return nil, organization.validate(oldOrganization)
+}
+
+// ValidateDelete does not validate anything, deletion is guarded by finalizers
+func (w *OrganizationWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *Organization) validate(old *Organization) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	spec := r.Spec
+
+	if old != nil {
+		allErrs = append(allErrs, validateImmutable(specPath.Child("name"), old.Spec.Name, spec.Name)...)
+		allErrs = append(allErrs, validateImmutable(specPath.Child("databaseRef"), old.Spec.DatabaseRef, spec.DatabaseRef)...)
+	}
+
+	if spec.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("name"), ""))
+	} else if strings.Contains(spec.Name, "/") {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("name"), spec.Name, "must not contain '/'"))
+	}
+	if spec.DisplayName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("displayName"), ""))
+	}
+	if spec.DatabaseRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("databaseRef"), ""))
+	}
+
+	graphsPath := specPath.Child("namedGraphs")
+	if len(spec.NamedGraphs) == 0 {
+		allErrs = append(allErrs, field.Required(graphsPath, "at least one graph is required"))
+	}
+	seen := make(map[string]bool)
+	for i, graph := range spec.NamedGraphs {
+		namePath := graphsPath.Index(i).Child("name")
+		switch {
+		case graph.Name == "":
+			allErrs = append(allErrs, field.Required(namePath, ""))
+		case strings.Contains(graph.Name, "/"):
+			allErrs = append(allErrs, field.Invalid(namePath, graph.Name, "must not contain '/'"))
+		case seen[graph.Name]:
+			allErrs = append(allErrs, field.Duplicate(namePath, graph.Name))
+		}
+		seen[graph.Name] = true
+	}
+	allErrs = append(allErrs, spec.CredentialRotation.Validate(specPath.Child("credentialRotation"))...)
+	allErrs = append(allErrs, spec.SecretTemplate.Validate(specPath.Child("secretTemplate"), false)...)
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("Organization").GroupKind(), r.Name, allErrs)
+	}
+	return nil
+}
+
+// validateMigration allows to change the databaseRef if the Organization is annotated with a DatabaseMigration which
+// migrates the previous Database to the new one
+func (w *OrganizationWebhook) validateMigration(ctx context.Context, old, organization *Organization) error {
+	databaseRefPath := field.NewPath("spec", "databaseRef")
+	forbidden := func(detail string) error {
+		return apierrors.NewInvalid(GroupVersion.WithKind("Organization").GroupKind(), organization.Name, field.ErrorList{
+			field.Forbidden(databaseRefPath, fmt.Sprintf("field is immutable, cannot change it from %s to %s: %s",
+				old.Spec.DatabaseRef, organization.Spec.DatabaseRef, detail)),
+		})
+	}
+
+	migrationName := organization.Spec.DatabaseMigrationRef
+	if migrationName == "" || w.Client == nil {
+		return forbidden("use a DatabaseMigration to move the organization")
+	}
+	migration := &DatabaseMigration{}
+	err := w.Client.Get(ctx, types.NamespacedName{Name: migrationName}, migration)
+	if apierrors.IsNotFound(err) {
+		return forbidden(fmt.Sprintf("DatabaseMigration %s does not exist", migrationName))
+	}
+	if err != nil {
+		return apierrors.NewInternalError(fmt.Errorf("cannot get DatabaseMigration %s: %v", migrationName, err))
+	}
+	if migration.Spec.SourceDatabaseRef != old.Spec.DatabaseRef || migration.Spec.Target.DatabaseRef != organization.Spec.DatabaseRef {
+		return forbidden(fmt.Sprintf("DatabaseMigration %s does not migrate %s to %s", migrationName,
+			old.Spec.DatabaseRef, organization.Spec.DatabaseRef))
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_OrganizationWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		orgName     string
+		graphs      []NamedGraph
+		expectedErr bool
+	}{
+		{
+			name:        "GivenValidSpec_WhenValidating_ThenAccept",
+			orgName:     "org",
+			graphs:      []NamedGraph{{Name: "graph-1"}, {Name: "graph-2", AddHidden: true}},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenNameWithSlash_WhenValidating_ThenReject",
+			orgName:     "org/sub",
+			graphs:      []NamedGraph{{Name: "graph"}},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenGraphNameWithSlash_WhenValidating_ThenReject",
+			orgName:     "org",
+			graphs:      []NamedGraph{{Name: "graph/sub"}},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenDuplicateGraphNames_WhenValidating_ThenReject",
+			orgName:     "org",
+			graphs:      []NamedGraph{{Name: "graph"}, {Name: "graph"}},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenNoGraphs_WhenValidating_ThenReject",
+			orgName:     "org",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			organization := &Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "organization"},
+				Spec:       OrganizationSpec{Name: tt.orgName, DisplayName: "Organization", DatabaseRef: "database", NamedGraphs: tt.graphs},
+			}
+
+			_, err := (&OrganizationWebhook{}).ValidateCreate(context.Background(), organization)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func Test_OrganizationWebhook_Default(t *testing.T) {
+	organization := &Organization{ObjectMeta: metav1.ObjectMeta{Name: "organization"}}
+
+	err := (&OrganizationWebhook{}).Default(context.Background(), organization)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "organization", organization.Spec.Name)
+	assert.Equal(t, "organization", organization.Spec.DisplayName)
+}
+
+func Test_OrganizationWebhook_ValidateUpdate(t *testing.T) {
+	oldSpec := OrganizationSpec{Name: "org", DisplayName: "Organization", DatabaseRef: "database", NamedGraphs: []NamedGraph{{Name: "graph"}}}
+	migration := &DatabaseMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "migration"},
+		Spec: DatabaseMigrationSpec{
+			SourceDatabaseRef: "database",
+			Target:            DatabaseMigrationTarget{DatabaseRef: "new-database", DatabaseName: "new-db"},
+			BackupLocation:    "/backup",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		update      func(spec *OrganizationSpec)
+		expectedErr bool
+	}{
+		{
+			name: "GivenChangedDisplayNameAndGraphs_WhenUpdating_ThenAccept",
+			update: func(spec *OrganizationSpec) {
+				spec.DisplayName = "Org"
+				spec.NamedGraphs = []NamedGraph{{Name: "other"}}
+			},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenChangedName_WhenUpdating_ThenReject",
+			update:      func(spec *OrganizationSpec) { spec.Name = "other-org" },
+			expectedErr: true,
+		},
+		{
+			name:        "GivenChangedDatabaseRef_WhenUpdating_ThenReject",
+			update:      func(spec *OrganizationSpec) { spec.DatabaseRef = "other-database" },
+			expectedErr: true,
+		},
+		{
+			name: "GivenMigrationRef_WhenDatabaseRefIsMigrationTarget_ThenAccept",
+			update: func(spec *OrganizationSpec) {
+				spec.DatabaseRef = "new-database"
+				spec.DatabaseMigrationRef = "migration"
+			},
+			expectedErr: false,
+		},
+		{
+			name: "GivenMigrationRef_WhenDatabaseRefIsNotMigrationTarget_ThenReject",
+			update: func(spec *OrganizationSpec) {
+				spec.DatabaseRef = "other-database"
+				spec.DatabaseMigrationRef = "migration"
+			},
+			expectedErr: true,
+		},
+		{
+			name: "GivenUnknownMigrationRef_WhenDatabaseRefChanged_ThenReject",
+			update: func(spec *OrganizationSpec) {
+				spec.DatabaseRef = "new-database"
+				spec.DatabaseMigrationRef = "unknown"
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			assert.NoError(t, AddToScheme(s))
+			kubeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(migration).Build()
+			oldOrganization := &Organization{ObjectMeta: metav1.ObjectMeta{Name: "organization"}, Spec: oldSpec}
+			organization := oldOrganization.DeepCopy()
+			tt.update(&organization.Spec)
+
+			_, err := (&OrganizationWebhook{Client: kubeClient}).ValidateUpdate(context.Background(), oldOrganization, organization)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
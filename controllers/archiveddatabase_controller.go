@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	stardogv1alpha1 "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	stardogv1beta1 "github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	scheme "k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ArchivedDatabaseReconciler reconciles an ArchivedDatabase object
+type ArchivedDatabaseReconciler struct {
+	client.Client
+	Log            logr.Logger
+	Scheme         *scheme.Scheme
+	StardogClients *StardogClientPool
+}
+
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=archiveddatabases,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=archiveddatabases/status,verbs=get;update;patch
+
+// Reconcile drops the database of an ArchivedDatabase once its expiration time has passed
+func (r *ArchivedDatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	archive := &stardogv1beta1.ArchivedDatabase{}
+	err := r.Get(ctx, req.NamespacedName, archive)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Log.Info("ArchivedDatabase not found, ignoring reconcile.")
+			return ctrl.Result{Requeue: false}, nil
+		}
+		r.Log.Error(err, "Could not retrieve ArchivedDatabase.")
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, err
+	}
+
+	ar := &ArchivedDatabaseReconciliation{
+		reconciliationContext: &ReconciliationContext{
+			context:        ctx,
+			conditions:     make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
+			stardogClients: r.StardogClients,
+		},
+		resource: archive,
+	}
+
+	return r.reconcileArchivedDatabase(ar)
+}
+
+func (r *ArchivedDatabaseReconciler) reconcileArchivedDatabase(ar *ArchivedDatabaseReconciliation) (ctrl.Result, error) {
+	rc := ar.reconciliationContext
+	archive := ar.resource
+
+	r.Log.Info("reconciling", getLoggingKeysAndValuesForArchivedDatabase(archive)...)
+
+	if archive.GetDeletionTimestamp() != nil {
+		return ctrl.Result{Requeue: false}, nil
+	}
+
+	remaining := archive.Spec.ExpirationTime.Sub(now())
+	if remaining > 0 {
+		rc.SetStatusCondition(createStatusConditionReady(true, fmt.Sprintf("Archived until %s", archive.Spec.ExpirationTime.UTC().Format(time.RFC3339))))
+		return ctrl.Result{Requeue: true, RequeueAfter: remaining}, r.updateStatus(ar)
+	}
+
+	if err := r.drop(ar); err != nil {
+		r.Log.Error(err, "Archived database cannot be dropped")
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Archived database cannot be dropped"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(ar)
+	}
+	rc.SetStatusIfExisting(stardogv1alpha1.StardogErrored, v1.ConditionFalse)
+
+	if err := r.Delete(rc.context, archive); err != nil && !apierrors.IsNotFound(err) {
+		r.Log.Error(err, "Cannot delete ArchivedDatabase")
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Cannot delete ArchivedDatabase"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(ar)
+	}
+	return ctrl.Result{Requeue: false}, nil
+}
+
+func (r *ArchivedDatabaseReconciler) updateStatus(ar *ArchivedDatabaseReconciliation) error {
+	res := ar.resource
+	status := res.Status
+	status.Conditions = mergeWithExistingConditions(status.Conditions, ar.reconciliationContext.conditions)
+	res.Status = status
+
+	err := r.Client.Status().Update(ar.reconciliationContext.context, res)
+	if err != nil {
+		r.Log.Error(err, "could not update ArchivedDatabase", getLoggingKeysAndValuesForArchivedDatabase(res)...)
+		return err
+	}
+	r.Log.Info("updated ArchivedDatabase status", getLoggingKeysAndValuesForArchivedDatabase(res)...)
+	return nil
+}
+
+// drop drops the archived database and its users and roles from all its instances
+func (r *ArchivedDatabaseReconciler) drop(ar *ArchivedDatabaseReconciliation) error {
+	archive := ar.resource
+	dropped := &stardogv1beta1.Database{
+		Spec:   stardogv1beta1.DatabaseSpec{DatabaseName: archive.Spec.DatabaseName},
+		Status: stardogv1beta1.DatabaseStatus{AddUserForNonHiddenGraphs: archive.Spec.AddUserForNonHiddenGraphs},
+	}
+
+	for _, instance := range archive.Spec.StardogInstanceRefs {
+		stardogClient, disabled, err := ar.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+		if err != nil {
+			return fmt.Errorf("cannot initialize stardog client: %v", err)
+		}
+		if disabled {
+			r.Log.Info("skipping resource from reconciliation", "instance", instance.Name, "resource", archive.Name)
+			continue
+		}
+
+		if err := dropDatabase(ar.reconciliationContext.context, stardogClient, dropped); err != nil {
+			return fmt.Errorf("cannot drop archived database %s on instance %s/%s: %v", archive.Spec.DatabaseName, instance.Namespace, instance.Name, err)
+		}
+		r.Log.Info("dropped archived Stardog database", "name", archive.Spec.DatabaseName, "instance", instance.Name)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ArchivedDatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&stardogv1beta1.ArchivedDatabase{}).
+		Complete(r)
+}
+
+func getLoggingKeysAndValuesForArchivedDatabase(archive *stardogv1beta1.ArchivedDatabase) []interface{} {
+	return []interface{}{
+		"ArchivedDatabase", archive.Name,
+	}
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func Test_reconcileArchivedDatabase(t *testing.T) {
+	namespace := "namespace-test"
+	currentTime := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		expirationTime  time.Time
+		expectMocks     func(stardogMocked *mock.MockStardogAPI)
+		expectedRequeue time.Duration
+		expectedDeleted bool
+	}{
+		{
+			name:            "GivenArchivedDatabase_WhenNotExpired_ThenRequeueAtExpiration",
+			expirationTime:  currentTime.Add(time.Hour),
+			expectedRequeue: time.Hour,
+		},
+		{
+			name:           "GivenArchivedDatabase_WhenExpired_ThenDropDatabaseAndDeleteArchive",
+			expirationTime: currentTime.Add(-time.Hour),
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().DeleteUserRole(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(3)
+				stardogMocked.EXPECT().DeleteRole(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				stardogMocked.EXPECT().DeleteUser(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				stardogMocked.EXPECT().DropDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+			},
+			expectedDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return currentTime }
+			defer func() { now = time.Now }()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			if tt.expectMocks != nil {
+				tt.expectMocks(stardogMocked)
+			}
+
+			archive := &v1beta1.ArchivedDatabase{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-test"},
+				Spec: v1beta1.ArchivedDatabaseSpec{
+					DatabaseName:        "db-test",
+					StardogInstanceRefs: []v1beta1.StardogInstanceRef{v1beta1.NewStardogInstanceRef("instance-test", namespace)},
+					ExpirationTime:      metav1.NewTime(tt.expirationTime),
+				},
+			}
+			fakeKubeClient, err := createKubeFakeClientWithSub(archive,
+				createStardogInstance(namespace, "instance-test", "secret-test", "http://url-test.ch"),
+				createFullSecret(namespace, "secret-test", "admin", "1234"),
+			)
+			assert.NoError(t, err)
+			r := ArchivedDatabaseReconciler{Log: testr.New(t), Scheme: scheme.Scheme, Client: fakeKubeClient}
+			ar := &ArchivedDatabaseReconciliation{
+				resource: archive,
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+			}
+
+			result, err := r.reconcileArchivedDatabase(ar)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRequeue, result.RequeueAfter)
+			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: "db-test"}, &v1beta1.ArchivedDatabase{})
+			assert.Equal(t, tt.expectedDeleted, apierrors.IsNotFound(err))
+		})
+	}
+}
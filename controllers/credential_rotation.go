@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	. "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CredentialsRotatedAnnotation is set on the pod template of Deployments selected by a CredentialRotationSpec to
+// restart them after the passwords have been rotated
+const CredentialsRotatedAnnotation = "stardog.vshn.ch/credentials-rotated-at"
+
+// isCredentialRotationDue returns true if the interval or the next scheduled time of the policy has passed since the
+// last rotation. Credentials which have never been rotated are measured from the given creation time.
+func isCredentialRotationDue(policy *CredentialRotationSpec, lastRotation *metav1.Time, created metav1.Time) (bool, error) {
+	if policy == nil {
+		return false, nil
+	}
+	last := created.Time
+	if lastRotation != nil {
+		last = lastRotation.Time
+	}
+
+	if policy.Schedule != "" {
+		schedule, err := cron.ParseStandard(policy.Schedule)
+		if err != nil {
+			return false, fmt.Errorf("cannot parse credential rotation schedule: %v", err)
+		}
+		next := schedule.Next(last)
+		return !next.IsZero() && !now().Before(next), nil
+	}
+	return policy.Interval != nil && !now().Before(last.Add(policy.Interval.Duration)), nil
+}
+
+// credentialRotation holds the new passwords of a rotation, so a user gets the same password on all instances
+type credentialRotation struct {
+	time      metav1.Time
+	passwords map[string]string
+}
+
+func newCredentialRotation() *credentialRotation {
+	return &credentialRotation{time: metav1.NewTime(now()), passwords: map[string]string{}}
+}
+
+// password returns the new password of the user, which is generated on first use
+func (c *credentialRotation) password(user string) (string, error) {
+	if pwd, ok := c.passwords[user]; ok {
+		return pwd, nil
+	}
+	pwd, err := generatePassword()
+	if err != nil {
+		return "", err
+	}
+	c.passwords[user] = pwd
+	return pwd, nil
+}
+
+// rotatePasswords changes the passwords of the given users in Stardog and writes them to the credential Secrets. The
+// passwords which have been changed are written to the Secrets even if changing another one fails.
+func rotatePasswords(ctx context.Context, kubeClient client.Client, stardogClient stardogapi.StardogAPI, rotation *credentialRotation, secrets *credentialSecrets, users []string) error {
+	changed := map[string]string{}
+	var changeErr error
+	for _, user := range users {
+		pwd, err := rotation.password(user)
+		if err != nil {
+			changeErr = err
+			break
+		}
+		if err := stardogClient.ChangePassword(ctx, user, pwd); err != nil {
+			changeErr = fmt.Errorf("cannot change password of user %s: %v", user, err)
+			break
+		}
+		changed[user] = pwd
+	}
+	if len(changed) == 0 {
+		return changeErr
+	}
+
+	if err := secrets.writePasswords(ctx, kubeClient, changed); err != nil {
+		return fmt.Errorf("cannot store rotated passwords: %v", err)
+	}
+	return changeErr
+}
+
+// recoverCredentials resets the passwords of the users which are missing in the credential Secrets and writes them to
+// the Secrets, which are recreated with the owner if they have been deleted. The names of the recovered users are
+// returned.
+func recoverCredentials(ctx context.Context, kubeClient client.Client, stardogClient stardogapi.StardogAPI, secrets *credentialSecrets, users []string) ([]string, error) {
+	missing := make([]string, 0)
+	for _, user := range users {
+		present, err := secrets.hasPassword(ctx, kubeClient, user)
+		if err != nil {
+			return nil, err
+		}
+		if !present {
+			missing = append(missing, user)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	if err := rotatePasswords(ctx, kubeClient, stardogClient, newCredentialRotation(), secrets, missing); err != nil {
+		return nil, fmt.Errorf("cannot reset passwords of users missing in credential Secret %s/%s: %v", secrets.namespace, secrets.name, err)
+	}
+	return missing, nil
+}
+
+// restartDeployments restarts the Deployments in the namespace which match the selector by annotating their pod
+// template with the rotation time
+func restartDeployments(ctx context.Context, kubeClient client.Client, namespace string, selector *metav1.LabelSelector, rotationTime time.Time) error {
+	if selector == nil {
+		return nil
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return fmt.Errorf("cannot parse rollout selector: %v", err)
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := kubeClient.List(ctx, deployments, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		return fmt.Errorf("cannot list Deployments in %s: %v", namespace, err)
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		patch := client.MergeFrom(deployment.DeepCopy())
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+		deployment.Spec.Template.Annotations[CredentialsRotatedAnnotation] = rotationTime.UTC().Format(time.RFC3339)
+		if err := kubeClient.Patch(ctx, deployment, patch); err != nil {
+			return fmt.Errorf("cannot restart Deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func Test_isCredentialRotationDue(t *testing.T) {
+	current := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(current.Add(-100 * 24 * time.Hour))
+	interval := &metav1.Duration{Duration: 90 * 24 * time.Hour}
+
+	tests := []struct {
+		name         string
+		policy       *v1alpha1.CredentialRotationSpec
+		lastRotation *metav1.Time
+		expectedDue  bool
+	}{
+		{
+			name:        "GivenNoPolicy_WhenChecking_ThenNotDue",
+			expectedDue: false,
+		},
+		{
+			name:        "GivenIntervalAndNoRotation_WhenCreatedBeforeInterval_ThenDue",
+			policy:      &v1alpha1.CredentialRotationSpec{Interval: interval},
+			expectedDue: true,
+		},
+		{
+			name:         "GivenInterval_WhenRotatedWithinInterval_ThenNotDue",
+			policy:       &v1alpha1.CredentialRotationSpec{Interval: interval},
+			lastRotation: &metav1.Time{Time: current.Add(-30 * 24 * time.Hour)},
+			expectedDue:  false,
+		},
+		{
+			name:         "GivenSchedule_WhenScheduledTimePassedSinceRotation_ThenDue",
+			policy:       &v1alpha1.CredentialRotationSpec{Schedule: "0 3 1 * *"},
+			lastRotation: &metav1.Time{Time: current.Add(-48 * time.Hour)},
+			expectedDue:  true,
+		},
+		{
+			name:         "GivenSchedule_WhenRotatedAfterScheduledTime_ThenNotDue",
+			policy:       &v1alpha1.CredentialRotationSpec{Schedule: "0 3 1 * *"},
+			lastRotation: &metav1.Time{Time: current.Add(-time.Hour)},
+			expectedDue:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return current }
+			defer func() { now = time.Now }()
+
+			due, err := isCredentialRotationDue(tt.policy, tt.lastRotation, created)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDue, due)
+		})
+	}
+}
+
+func Test_rotatePasswords(t *testing.T) {
+	namespace := "namespace-test"
+
+	tests := []struct {
+		name              string
+		secretExists      bool
+		expectMocks       func(stardogMocked *mock.MockStardogAPI)
+		expectedErr       bool
+		expectedRotations []string
+	}{
+		{
+			name:         "GivenSecret_WhenRotating_ThenChangePasswordsAndUpdateSecret",
+			secretExists: true,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().ChangePassword(gomock.Any(), "db_read", gomock.Any()).Return(nil).Times(1)
+				stardogMocked.EXPECT().ChangePassword(gomock.Any(), "db_write", gomock.Any()).Return(nil).Times(1)
+			},
+			expectedRotations: []string{"db_read", "db_write"},
+		},
+		{
+			name:         "GivenFailingPasswordChange_WhenRotating_ThenStoreChangedPasswordsAndError",
+			secretExists: true,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().ChangePassword(gomock.Any(), "db_read", gomock.Any()).Return(nil).Times(1)
+				stardogMocked.EXPECT().ChangePassword(gomock.Any(), "db_write", gomock.Any()).Return(errors.New("unavailable")).Times(1)
+			},
+			expectedErr:       true,
+			expectedRotations: []string{"db_read"},
+		},
+		{
+			name:         "GivenMissingSecret_WhenRotating_ThenChangePasswordsAndCreateSecret",
+			secretExists: false,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().ChangePassword(gomock.Any(), "db_read", gomock.Any()).Return(nil).Times(1)
+				stardogMocked.EXPECT().ChangePassword(gomock.Any(), "db_write", gomock.Any()).Return(nil).Times(1)
+			},
+			expectedRotations: []string{"db_read", "db_write"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			tt.expectMocks(stardogMocked)
+			secret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: namespace},
+				Data:       map[string][]byte{"db_read": []byte("old"), "db_write": []byte("old")},
+			}
+			fakeKubeClient, err := createKubeFakeClient()
+			assert.NoError(t, err)
+			if tt.secretExists {
+				assert.NoError(t, fakeKubeClient.Create(context.Background(), secret))
+			}
+			rotation := newCredentialRotation()
+			secrets := &credentialSecrets{
+				namespace: namespace,
+				name:      "db-credentials",
+				owner:     createStardogDB("db", "", v1beta1.NewStardogInstanceRef("instance", namespace)),
+				scheme:    scheme.Scheme,
+			}
+
+			err = rotatePasswords(context.Background(), fakeKubeClient, stardogMocked, rotation, secrets, []string{"db_read", "db_write"})
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+			updated := &v1.Secret{}
+			assert.NoError(t, fakeKubeClient.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: "db-credentials"}, updated))
+			for _, user := range []string{"db_read", "db_write"} {
+				if contains(tt.expectedRotations, user) {
+					assert.Equal(t, rotation.passwords[user], string(updated.Data[user]))
+				} else {
+					assert.Equal(t, "old", string(updated.Data[user]))
+				}
+			}
+		})
+	}
+}
+
+func Test_restartDeployments(t *testing.T) {
+	namespace := "namespace-test"
+	rotationTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	selected := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "selected", Namespace: namespace, Labels: map[string]string{"app": "api"}}}
+	other := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace, Labels: map[string]string{"app": "web"}}}
+	fakeKubeClient, err := createKubeFakeClient(selected, other)
+	assert.NoError(t, err)
+
+	err = restartDeployments(context.Background(), fakeKubeClient, namespace, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}, rotationTime)
+
+	assert.NoError(t, err)
+	assert.NoError(t, fakeKubeClient.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: "selected"}, selected))
+	assert.Equal(t, "2024-06-01T12:00:00Z", selected.Spec.Template.Annotations[CredentialsRotatedAnnotation])
+	assert.NoError(t, fakeKubeClient.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: "other"}, other))
+	assert.NotContains(t, other.Spec.Template.Annotations, CredentialsRotatedAnnotation)
+}
+
+func Test_recoverCredentials(t *testing.T) {
+	namespace := "namespace-test"
+	secretName := "db-instance-credentials"
+
+	tests := []struct {
+		name              string
+		secret            *v1.Secret
+		expectedRecovered []string
+	}{
+		{
+			name:              "GivenCompleteSecret_WhenRecovering_ThenDoNothing",
+			secret:            &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}, Data: map[string][]byte{"db_read": []byte("read"), "db_write": []byte("write")}},
+			expectedRecovered: nil,
+		},
+		{
+			name:              "GivenIncompleteSecret_WhenRecovering_ThenResetMissingUser",
+			secret:            &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}, Data: map[string][]byte{"db_read": []byte("read")}},
+			expectedRecovered: []string{"db_write"},
+		},
+		{
+			name:              "GivenMissingSecret_WhenRecovering_ThenRecreateSecretAndResetUsers",
+			expectedRecovered: []string{"db_read", "db_write"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			for _, user := range tt.expectedRecovered {
+				stardogMocked.EXPECT().ChangePassword(gomock.Any(), user, gomock.Any()).Return(nil).Times(1)
+			}
+			database := createStardogDB("db", "", v1beta1.NewStardogInstanceRef("instance", namespace))
+			objects := []runtime.Object{database}
+			if tt.secret != nil {
+				objects = append(objects, tt.secret)
+			}
+			fakeKubeClient, err := createKubeFakeClient(objects...)
+			assert.NoError(t, err)
+
+			secrets := &credentialSecrets{namespace: namespace, name: secretName, owner: database, scheme: scheme.Scheme}
+
+			recovered, err := recoverCredentials(context.Background(), fakeKubeClient, stardogMocked, secrets, []string{"db_read", "db_write"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRecovered, recovered)
+			secret := &v1.Secret{}
+			assert.NoError(t, fakeKubeClient.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: secretName}, secret))
+			assert.Len(t, secret.Data, 2)
+			if tt.secret == nil {
+				assert.True(t, metav1.IsControlledBy(secret, database))
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	. "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var invalidSecretNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// credentialSecrets are the Secrets which hold the generated passwords of the users of a resource. Without a template
+// a single Secret contains the password of each user keyed by the username, otherwise the template may define a
+// Secret per user with the keys "username" and "password".
+type credentialSecrets struct {
+	namespace string
+	name      string
+	owner     client.Object
+	scheme    *runtime.Scheme
+	template  *CredentialSecretTemplate
+	// connection contains the additional keys of the connection details
+	connection map[string]string
+}
+
+// secretName returns the name of the Secret which contains the password of the user
+func (c *credentialSecrets) secretName(user string) string {
+	if c.template == nil || !c.template.PerUser {
+		return c.name
+	}
+	return c.name + "-" + strings.Trim(invalidSecretNameChars.ReplaceAllString(strings.ToLower(user), "-"), "-.")
+}
+
+// passwordKey returns the key of the password of the user in its Secret
+func (c *credentialSecrets) passwordKey(user string) string {
+	if c.template == nil || !c.template.PerUser {
+		return user
+	}
+	return v1.BasicAuthPasswordKey
+}
+
+// hasPassword returns whether the password of the user is present in its Secret
+func (c *credentialSecrets) hasPassword(ctx context.Context, kubeClient client.Client, user string) (bool, error) {
+	secret := &v1.Secret{}
+	err := kubeClient.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: c.secretName(user)}, secret)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot get credential Secret %s/%s: %v", c.namespace, c.secretName(user), err)
+	}
+	_, err = getSecretData(*secret, c.passwordKey(user))
+	return err == nil, nil
+}
+
+// writePasswords writes the passwords by username to their Secrets, which are created with the owner, type and
+// metadata of the template if they do not exist
+func (c *credentialSecrets) writePasswords(ctx context.Context, kubeClient client.Client, passwords map[string]string) error {
+	usersBySecret := map[string][]string{}
+	for user := range passwords {
+		name := c.secretName(user)
+		usersBySecret[name] = append(usersBySecret[name], user)
+	}
+	names := make([]string, 0, len(usersBySecret))
+	for name := range usersBySecret {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		users := usersBySecret[name]
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			secret := &v1.Secret{}
+			err := kubeClient.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: name}, secret)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			create := apierrors.IsNotFound(err)
+			if create {
+				secret = &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: c.namespace}}
+				if c.template != nil && c.template.Type != "" {
+					secret.Type = c.template.Type
+				}
+				if err := controllerutil.SetControllerReference(c.owner, secret, c.scheme); err != nil {
+					return err
+				}
+			}
+			c.apply(secret, users, passwords)
+			if create {
+				return kubeClient.Create(ctx, secret)
+			}
+			return kubeClient.Update(ctx, secret)
+		})
+		if err != nil {
+			return fmt.Errorf("cannot write credential Secret %s/%s: %v", c.namespace, name, err)
+		}
+	}
+	return nil
+}
+
+// apply writes the metadata of the template, the passwords of the users and the connection details to the Secret
+func (c *credentialSecrets) apply(secret *v1.Secret, users []string, passwords map[string]string) {
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	if c.template != nil {
+		for k, v := range c.template.Labels {
+			metav1.SetMetaDataLabel(&secret.ObjectMeta, k, v)
+		}
+		for k, v := range c.template.Annotations {
+			metav1.SetMetaDataAnnotation(&secret.ObjectMeta, k, v)
+		}
+	}
+	for _, user := range users {
+		key := c.passwordKey(user)
+		if c.template != nil && c.template.PerUser {
+			secret.Data[v1.BasicAuthUsernameKey] = []byte(user)
+			delete(secret.StringData, v1.BasicAuthUsernameKey)
+		}
+		secret.Data[key] = []byte(passwords[user])
+		delete(secret.StringData, key)
+	}
+	for k, v := range c.connection {
+		secret.Data[k] = []byte(v)
+	}
+}
+
+// getConnectionDetails returns the endpoint of the StardogInstance and, if a database name is given, the name and
+// SPARQL endpoints of the database
+func getConnectionDetails(ctx context.Context, kubeClient client.Client, instance v1beta1.StardogInstanceRef, dbName string) (map[string]string, error) {
+	stardogInstance := &StardogInstance{}
+	err := kubeClient.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, stardogInstance)
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve stardogInstanceRef %s/%s: %v", instance.Namespace, instance.Name, err)
+	}
+	endpoint, err := getBaseURL(stardogInstance.Spec.ServerUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]string{"endpoint": endpoint}
+	if dbName != "" {
+		details["database"] = dbName
+		details["queryUrl"] = fmt.Sprintf("%s/%s/query", endpoint, dbName)
+		details["updateUrl"] = fmt.Sprintf("%s/%s/update", endpoint, dbName)
+	}
+	return details, nil
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func Test_writePasswords(t *testing.T) {
+	namespace := "namespace-test"
+	connection := map[string]string{"endpoint": "https://stardog.example.com", "database": "db"}
+
+	tests := []struct {
+		name            string
+		template        *v1alpha1.CredentialSecretTemplate
+		expectedSecrets map[string]map[string]string
+		expectedType    v1.SecretType
+	}{
+		{
+			name: "GivenNoTemplate_WhenWritingPasswords_ThenWriteSharedSecretByUsername",
+			expectedSecrets: map[string]map[string]string{
+				"db-credentials": {"db_read": "read", "db_write": "write"},
+			},
+		},
+		{
+			name:     "GivenPerUserTemplate_WhenWritingPasswords_ThenWriteBasicAuthSecretPerUser",
+			template: &v1alpha1.CredentialSecretTemplate{PerUser: true, Type: v1.SecretTypeBasicAuth, Labels: map[string]string{"app": "api"}, ConnectionDetails: true},
+			expectedSecrets: map[string]map[string]string{
+				"db-credentials-db-read":  {"username": "db_read", "password": "read", "endpoint": "https://stardog.example.com", "database": "db"},
+				"db-credentials-db-write": {"username": "db_write", "password": "write", "endpoint": "https://stardog.example.com", "database": "db"},
+			},
+			expectedType: v1.SecretTypeBasicAuth,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKubeClient, err := createKubeFakeClient()
+			assert.NoError(t, err)
+			database := createStardogDB("db", "", v1beta1.NewStardogInstanceRef("instance", namespace))
+			secrets := &credentialSecrets{namespace: namespace, name: "db-credentials", owner: database, scheme: scheme.Scheme, template: tt.template}
+			if tt.template != nil && tt.template.ConnectionDetails {
+				secrets.connection = connection
+			}
+
+			err = secrets.writePasswords(context.Background(), fakeKubeClient, map[string]string{"db_read": "read", "db_write": "write"})
+
+			assert.NoError(t, err)
+			secretList := &v1.SecretList{}
+			assert.NoError(t, fakeKubeClient.List(context.Background(), secretList))
+			assert.Len(t, secretList.Items, len(tt.expectedSecrets))
+			for name, expectedData := range tt.expectedSecrets {
+				secret := &v1.Secret{}
+				assert.NoError(t, fakeKubeClient.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, secret))
+				data := map[string]string{}
+				for k, v := range secret.Data {
+					data[k] = string(v)
+				}
+				assert.Equal(t, expectedData, data)
+				assert.True(t, metav1.IsControlledBy(secret, database))
+				if tt.template != nil {
+					assert.Equal(t, tt.template.Labels, secret.Labels)
+				}
+				assert.Equal(t, tt.expectedType, secret.Type)
+			}
+		})
+	}
+}
+
+func Test_getConnectionDetails(t *testing.T) {
+	namespace := "namespace-test"
+	fakeKubeClient, err := createKubeFakeClient(createStardogInstance(namespace, "instance", "admin-secret", "https://stardog.example.com/"))
+	assert.NoError(t, err)
+
+	details, err := getConnectionDetails(context.Background(), fakeKubeClient, v1beta1.NewStardogInstanceRef("instance", namespace), "db")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"endpoint":  "https://stardog.example.com",
+		"database":  "db",
+		"queryUrl":  "https://stardog.example.com/db/query",
+		"updateUrl": "https://stardog.example.com/db/update",
+	}, details)
+}
@@ -3,57 +3,61 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-logr/logr"
-	"github.com/go-openapi/runtime"
 	"github.com/sethvargo/go-password/password"
 	stardogv1alpha1 "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
 	stardogv1beta1 "github.com/vshn/stardog-userrole-operator/api/v1beta1"
-	stardog "github.com/vshn/stardog-userrole-operator/stardogrest/client"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/db"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles_permissions"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
 	"k8s.io/api/core/v1"
-	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	scheme "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/utils/pointer"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/strings/slices"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sort"
+	"strings"
+	"time"
 )
 
 const databaseFinalizer = "finalizer.stardog.databases"
 
-var defaultDBOptions = map[string]interface{}{
-	"transaction.write.conflict.strategy": "abort_on_conflict",
-	"index.aggregate":                     "On",
-	"spatial.enabled":                     "true",
-	"transaction.logging":                 "true",
-	"query.all.graphs":                    "true",
-	"preserve.bnode.ids":                  "false",
-}
-
-type stardogDatabaseCreate struct {
-	Name    string                 `json:"dbname,omitempty"`
-	Options map[string]interface{} `json:"options,omitempty"`
+// getDefaultDBOptions returns the options every database is created with unless they are set in the spec. A new map is
+// returned on every call, so it can be modified by the caller.
+func getDefaultDBOptions() map[string]any {
+	return map[string]any{
+		"transaction.write.conflict.strategy": "abort_on_conflict",
+		"index.aggregate":                     "On",
+		"spatial.enabled":                     "true",
+		"transaction.logging":                 "true",
+		"query.all.graphs":                    "true",
+		"preserve.bnode.ids":                  "false",
+	}
 }
 
 // DatabaseReconciler reconciles a Database object
 type DatabaseReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *scheme.Scheme
+	Log            logr.Logger
+	Scheme         *scheme.Scheme
+	Recorder       record.EventRecorder
+	StardogClients *StardogClientPool
 }
 
 //+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databases,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databases/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databaserestores,verbs=get;list;watch
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databasebackups,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=archiveddatabases,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile manages the Stardog resources for a Database object
 func (r *DatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -70,9 +74,9 @@ func (r *DatabaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	dr := &DatabaseReconciliation{
 		reconciliationContext: &ReconciliationContext{
-			context:       ctx,
-			conditions:    make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
-			stardogClient: stardog.NewHTTPClient(nil),
+			context:        ctx,
+			conditions:     make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
+			stardogClients: r.StardogClients,
 		},
 		resource: database,
 	}
@@ -89,15 +93,25 @@ func (r *DatabaseReconciler) reconcileDatabase(dr *DatabaseReconciliation) (ctrl
 	isStardogDatabaseMarkedToBeDeleted := database.GetDeletionTimestamp() != nil
 	if isStardogDatabaseMarkedToBeDeleted {
 		if err := r.deleteDatabases(dr); err != nil {
-			r.Log.Error(err, "StardogDatabase cannot be deleted")
-			rc.SetStatusCondition(createStatusConditionTerminating(err))
+			var pending *deletionPendingError
+			switch {
+			case errors.Is(err, errDeletionProtected):
+				r.Log.Info("StardogDatabase is protected from deletion", getLoggingKeysAndValuesForDatabase(database)...)
+				rc.SetStatusCondition(createStatusConditionDeletionBlocked(err))
+			case errors.As(err, &pending):
+				r.Log.Info("StardogDatabase deletion is pending", "reason", pending.reason, "message", err.Error())
+				rc.SetStatusCondition(createStatusConditionTerminatingWithReason(pending.reason, err))
+			default:
+				r.Log.Error(err, "StardogDatabase cannot be deleted")
+				rc.SetStatusCondition(createStatusConditionTerminating(err))
+			}
 			rc.SetStatusCondition(createStatusConditionReady(false, "StardogDatabase cannot be deleted"))
 			return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(dr)
 		}
 		return ctrl.Result{Requeue: false}, nil
 	}
 
-	if err := r.validateSpecification(dr.reconciliationContext.context, dr.resource); err != nil {
+	if err := r.validateSpecification(dr.resource); err != nil {
 		r.Log.Error(err, "Specification cannot be validated")
 		rc.SetStatusCondition(createStatusConditionInvalid(err))
 		rc.SetStatusCondition(createStatusConditionReady(false, "Specification cannot be validated"))
@@ -105,6 +119,37 @@ func (r *DatabaseReconciler) reconcileDatabase(dr *DatabaseReconciliation) (ctrl
 	}
 	rc.SetStatusIfExisting(stardogv1alpha1.StardogInvalid, v1.ConditionFalse)
 
+	restoring, err := isRestoreRunning(rc.context, r.Client, database)
+	if err != nil {
+		r.Log.Error(err, "Cannot determine whether the database is being restored")
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Cannot determine whether the database is being restored"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(dr)
+	}
+	if restoring {
+		r.Log.Info("skipping synchronization while the database is being restored", getLoggingKeysAndValuesForDatabase(database)...)
+		rc.SetStatusCondition(createStatusConditionReady(false, "Restore in progress"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(dr)
+	}
+
+	if err := r.restoreArchivedDatabases(dr); err != nil {
+		r.Log.Error(err, "Cannot restore archived database")
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Cannot restore archived database"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(dr)
+	}
+
+	rotate, err := isCredentialRotationDue(database.Spec.CredentialRotation, database.Status.LastCredentialRotation, database.CreationTimestamp)
+	if err != nil {
+		r.Log.Error(err, "Cannot determine whether the credentials have to be rotated")
+		rc.SetStatusCondition(createStatusConditionInvalid(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Specification cannot be validated"))
+		return ctrl.Result{Requeue: false}, r.updateStatus(dr)
+	}
+	if rotate {
+		dr.credentialRotation = newCredentialRotation()
+	}
+
 	if err := r.syncDB(dr); err != nil {
 		r.Log.Error(err, "Synchronization failed")
 		rc.SetStatusCondition(createStatusConditionErrored(err))
@@ -124,10 +169,25 @@ func (r *DatabaseReconciler) reconcileDatabase(dr *DatabaseReconciliation) (ctrl
 		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(dr)
 	}
 
+	database.Status.DatabaseName = database.Spec.DatabaseName
+	database.Status.NamedGraphPrefix = database.Spec.NamedGraphPrefix
+	database.Status.Options = database.Spec.Options
 	database.Status.StardogInstanceRefs = database.Spec.StardogInstanceRefs
 	database.Status.AddUserForNonHiddenGraphs = database.Spec.AddUserForNonHiddenGraphs
+	database.Status.Instances = dr.instances
+	if dr.credentialRotation != nil {
+		database.Status.LastCredentialRotation = &dr.credentialRotation.time
+	}
 	rc.SetStatusCondition(createStatusConditionReady(true, "Synchronized"))
-	return ctrl.Result{Requeue: true, RequeueAfter: ReconFreq}, r.updateStatus(dr)
+	return ctrl.Result{Requeue: true, RequeueAfter: getDatabaseRequeueAfter()}, r.updateStatus(dr)
+}
+
+// getDatabaseRequeueAfter returns ReconFreq, or StatsFreq if the refresh of the statistics is enabled and due earlier
+func getDatabaseRequeueAfter() time.Duration {
+	if StatsFreq > 0 && (ReconFreq == 0 || StatsFreq < ReconFreq) {
+		return StatsFreq
+	}
+	return ReconFreq
 }
 
 func (r *DatabaseReconciler) updateStatus(dr *DatabaseReconciliation) error {
@@ -147,9 +207,35 @@ func (r *DatabaseReconciler) updateStatus(dr *DatabaseReconciliation) error {
 	return nil
 }
 
+// deletionPendingError is returned if the deletion policy of a Database defers the deletion of the Stardog database,
+// e.g. until it is empty or has been backed up
+type deletionPendingError struct {
+	reason  string
+	message string
+}
+
+func (e *deletionPendingError) Error() string {
+	return e.message
+}
+
 func (r *DatabaseReconciler) deleteDatabases(dr *DatabaseReconciliation) error {
 	instances := dr.resource.Spec.StardogInstanceRefs
 	database := dr.resource
+	policy := database.GetDeletionPolicy()
+
+	if stardogv1alpha1.IsDeletionProtected(database) {
+		return errDeletionProtected
+	}
+
+	if policy == stardogv1beta1.DeletionPolicyRetain {
+		r.Log.Info(fmt.Sprintf("retaining Stardog Database %s on instances %s due to deletionPolicy %s", database.Spec.DatabaseName, instances, policy))
+		controllerutil.RemoveFinalizer(database, databaseFinalizer)
+		if err := r.Update(dr.reconciliationContext.context, database); err != nil {
+			return fmt.Errorf("cannot update database: %v", err)
+		}
+		deleteDatabaseStatistics(database)
+		return nil
+	}
 	r.Log.Info(fmt.Sprintf("checking if Stardog Database %s is deletable for each instance %s", dr.resource.Name, instances))
 
 	// Do not delete the database unless there are no organizations
@@ -162,17 +248,34 @@ func (r *DatabaseReconciler) deleteDatabases(dr *DatabaseReconciliation) error {
 	// Count the organizations linked to this database
 	dbOrgs := make([]stardogv1beta1.Organization, 0)
 	for _, item := range orgs.Items {
-		if item.Spec.DatabaseRef == database.Spec.DatabaseName {
+		if item.Spec.DatabaseRef == database.Name {
 			dbOrgs = append(dbOrgs, item)
 		}
 	}
 	if len(dbOrgs) > 0 {
-		return fmt.Errorf("cannot delete database while having %d organizations", len(orgs.Items))
+		return fmt.Errorf("cannot delete database while having %d organizations", len(dbOrgs))
+	}
+
+	if policy == stardogv1beta1.DeletionPolicyBackupThenDelete {
+		if err := r.backupBeforeDeletion(dr, getDeletionBackupName(database), nil); err != nil {
+			return err
+		}
+	}
+	if policy == stardogv1beta1.DeletionPolicyArchive {
+		err := r.createArchivedDatabase(dr, database.Name, instances, database.GetDeletionTimestamp().Time)
+		if err != nil {
+			return err
+		}
 	}
 
 	for _, instance := range instances {
-		if err := r.deleteDatabase(dr, instance); err != nil {
-			return fmt.Errorf("cannot delete database: %v", err)
+		if policy == stardogv1beta1.DeletionPolicyArchive {
+			err = r.archiveDatabase(dr, instance)
+		} else {
+			err = r.deleteDatabase(dr, instance)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot delete database: %w", err)
 		}
 		database.Status.StardogInstanceRefs = removeStardogInstanceRef(database.Status.StardogInstanceRefs, instance)
 	}
@@ -182,6 +285,7 @@ func (r *DatabaseReconciler) deleteDatabases(dr *DatabaseReconciliation) error {
 	if err != nil {
 		return fmt.Errorf("cannot update database: %v", err)
 	}
+	deleteDatabaseStatistics(database)
 
 	return nil
 }
@@ -190,7 +294,7 @@ func (r *DatabaseReconciler) deleteDatabase(dr *DatabaseReconciliation, instance
 	database := dr.resource
 
 	r.Log.V(1).Info("setup Stardog Client from ", "ref", instance)
-	auth, disabled, err := dr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+	stardogClient, disabled, err := dr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
 	if err != nil {
 		return fmt.Errorf("cannot initialize stardog client: %v", err)
 	}
@@ -199,81 +303,269 @@ func (r *DatabaseReconciler) deleteDatabase(dr *DatabaseReconciliation, instance
 		return nil
 	}
 
-	stardogClient := dr.reconciliationContext.stardogClient
+	ctx := dr.reconciliationContext.context
 	dbName := database.Spec.DatabaseName
 
 	// Do not delete the database unless it's empty
-	sizeParams := db.NewGetDBSizeParams().WithDb(dbName).WithExact(pointer.Bool(false))
-	dbSize, err := stardogClient.Db.GetDBSize(sizeParams, auth)
-	if err != nil && NotFound(err) {
+	policy := database.GetDeletionPolicy()
+	if policy == stardogv1beta1.DeletionPolicyDeleteIfEmpty {
+		dbSize, err := stardogClient.GetDatabaseSize(ctx, dbName)
+		if err != nil && stardogapi.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot determine the size of the database %s: %v", dbName, err)
+		}
+		if dbSize != 0 {
+			return &deletionPendingError{
+				reason: stardogv1alpha1.ReasonNotEmpty,
+				message: fmt.Sprintf("deletionPolicy %s: database %s on instance %s/%s contains %d triples",
+					policy, dbName, instance.Namespace, instance.Name, dbSize),
+			}
+		}
+	}
+
+	r.Log.Info(fmt.Sprintf("dropping Stardog Database %s on instance %s/%s due to deletionPolicy %s", dbName, instance.Namespace, instance.Name, policy))
+	return dropDatabase(ctx, stardogClient, database)
+}
+
+// createArchivedDatabase records the database on the given instances in an ArchivedDatabase, which drops the database
+// once the archive grace period since the archive time has expired
+func (r *DatabaseReconciler) createArchivedDatabase(dr *DatabaseReconciliation, name string, instances []stardogv1beta1.StardogInstanceRef, archiveTime time.Time) error {
+	ctx := dr.reconciliationContext.context
+	database := dr.resource
+
+	archive := &stardogv1beta1.ArchivedDatabase{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: stardogv1beta1.ArchivedDatabaseSpec{
+			DatabaseName:              database.Spec.DatabaseName,
+			AddUserForNonHiddenGraphs: database.Status.AddUserForNonHiddenGraphs,
+			StardogInstanceRefs:       instances,
+			ExpirationTime:            metav1.NewTime(archiveTime.Add(database.GetArchiveGracePeriod())),
+		},
+	}
+	err := r.Create(ctx, archive)
+	if apierrors.IsAlreadyExists(err) {
+		existing := &stardogv1beta1.ArchivedDatabase{}
+		if err := r.Get(ctx, types.NamespacedName{Name: archive.Name}, existing); err != nil {
+			return fmt.Errorf("cannot get archived database %s: %v", archive.Name, err)
+		}
+		// The archive has been created by an earlier reconciliation of the same deletion
+		if existing.Spec.DatabaseName != archive.Spec.DatabaseName || !reflect.DeepEqual(existing.Spec.StardogInstanceRefs, archive.Spec.StardogInstanceRefs) {
+			return fmt.Errorf("cannot archive database %s, ArchivedDatabase %s of database %s already exists",
+				database.Spec.DatabaseName, existing.Name, existing.Spec.DatabaseName)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot create archived database %s: %v", archive.Name, err)
+	}
+	r.Log.Info("created ArchivedDatabase", "name", archive.Name, "expirationTime", archive.Spec.ExpirationTime)
+	return nil
+}
+
+// archiveDatabase takes the database offline and disables its users on the instance
+func (r *DatabaseReconciler) archiveDatabase(dr *DatabaseReconciliation, instance stardogv1beta1.StardogInstanceRef) error {
+	database := dr.resource
+
+	stardogClient, disabled, err := dr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+	if err != nil {
+		return fmt.Errorf("cannot initialize stardog client: %v", err)
+	}
+	if disabled {
+		r.Log.Info("skipping resource from reconciliation", "instance", instance.Name, "resource", database.Name)
 		return nil
 	}
+
+	err = setArchived(dr.reconciliationContext.context, stardogClient, database.Spec.DatabaseName, database.Status.AddUserForNonHiddenGraphs, true)
 	if err != nil {
-		return fmt.Errorf("cannot determine the size of the database %s: %v", dbName, err)
+		return err
+	}
+	r.Log.Info(fmt.Sprintf("archived Stardog Database %s on instance %s/%s due to deletionPolicy %s",
+		database.Spec.DatabaseName, instance.Namespace, instance.Name, stardogv1beta1.DeletionPolicyArchive))
+	return nil
+}
+
+// restoreArchivedDatabases brings archived databases with the name of the Database back online on the instances of the
+// Database. ArchivedDatabases are deleted once all their instances have been restored.
+func (r *DatabaseReconciler) restoreArchivedDatabases(dr *DatabaseReconciliation) error {
+	ctx := dr.reconciliationContext.context
+	database := dr.resource
+
+	archives := &stardogv1beta1.ArchivedDatabaseList{}
+	if err := r.List(ctx, archives); err != nil {
+		return fmt.Errorf("cannot list archived databases: %v", err)
+	}
+	for i := range archives.Items {
+		archive := &archives.Items[i]
+		if archive.Spec.DatabaseName != database.Spec.DatabaseName || archive.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		remaining := make([]stardogv1beta1.StardogInstanceRef, 0)
+		for _, instance := range archive.Spec.StardogInstanceRefs {
+			if !containsStardogInstanceRef(database.Spec.StardogInstanceRefs, instance) {
+				remaining = append(remaining, instance)
+				continue
+			}
+			stardogClient, disabled, err := dr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+			if err != nil {
+				return fmt.Errorf("cannot initialize stardog client: %v", err)
+			}
+			if disabled {
+				remaining = append(remaining, instance)
+				continue
+			}
+			if err := setArchived(ctx, stardogClient, archive.Spec.DatabaseName, archive.Spec.AddUserForNonHiddenGraphs, false); err != nil {
+				return err
+			}
+			r.Log.Info("restored archived Stardog database", "name", archive.Spec.DatabaseName, "instance", instance.Name)
+		}
+
+		switch {
+		case len(remaining) == 0:
+			if err := r.Delete(ctx, archive); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("cannot delete archived database %s: %v", archive.Name, err)
+			}
+		case len(remaining) != len(archive.Spec.StardogInstanceRefs):
+			archive.Spec.StardogInstanceRefs = remaining
+			if err := r.Update(ctx, archive); err != nil {
+				return fmt.Errorf("cannot update archived database %s: %v", archive.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setArchived takes the database offline and disables its read, write and custom users, or brings the database back
+// online and enables the users
+func setArchived(ctx context.Context, stardogClient stardogapi.StardogAPI, dbName, customUser string, archived bool) error {
+	var err error
+	if archived {
+		err = stardogClient.OfflineDatabase(ctx, dbName)
+	} else {
+		err = stardogClient.OnlineDatabase(ctx, dbName)
+	}
+	if err != nil && !stardogapi.IsNotFound(err) {
+		return fmt.Errorf("cannot set database %s archived=%t: %v", dbName, archived, err)
+	}
+
+	read, write := getUserRoleNames(dbName)
+	users := []string{read, write}
+	if customUser != "" {
+		users = append(users, customUser)
+	}
+	for _, user := range users {
+		err := stardogClient.SetUserEnabled(ctx, user, !archived)
+		if err != nil && !stardogapi.IsNotFound(err) {
+			return fmt.Errorf("cannot set user %s enabled=%t: %v", user, !archived, err)
+		}
+	}
+	return nil
+}
+
+// backupBeforeDeletion creates a DatabaseBackup of the deleted Database and returns a deletionPendingError until it has
+// completed. The DatabaseBackup is not owned by the Database, so that it outlives it.
+func (r *DatabaseReconciler) backupBeforeDeletion(dr *DatabaseReconciliation, name string, instances []stardogv1beta1.StardogInstanceRef) error {
+	ctx := dr.reconciliationContext.context
+	database := dr.resource
+	if database.Status.DatabaseName == "" {
+		r.Log.Info("skipping backup of a Database which has never been synchronized", getLoggingKeysAndValuesForDatabase(database)...)
+		return nil
 	}
-	if dbSize.Payload != "0" {
-		return fmt.Errorf("cannot delete non empty database %s", dbName)
+
+	backup := &stardogv1beta1.DatabaseBackup{}
+	err := r.Get(ctx, types.NamespacedName{Name: name}, backup)
+	if apierrors.IsNotFound(err) {
+		backup = &stardogv1beta1.DatabaseBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: stardogv1beta1.DatabaseBackupSpec{
+				DatabaseRef:         database.Name,
+				Location:            database.Spec.DeletionBackupLocation,
+				StardogInstanceRefs: instances,
+			},
+		}
+		if err := r.Create(ctx, backup); err != nil {
+			return fmt.Errorf("cannot create backup %s: %v", name, err)
+		}
+		r.Log.Info("created DatabaseBackup before deletion", "name", name, "database", database.Name)
+	} else if err != nil {
+		return fmt.Errorf("cannot get backup %s: %v", name, err)
+	}
+
+	if backup.Status.Phase == stardogv1beta1.DatabaseBackupFailed {
+		return fmt.Errorf("deletionPolicy %s: DatabaseBackup %s failed, delete it to back up the database again",
+			stardogv1beta1.DeletionPolicyBackupThenDelete, name)
 	}
+	if backup.Status.Phase != stardogv1beta1.DatabaseBackupCompleted {
+		return &deletionPendingError{
+			reason: stardogv1alpha1.ReasonBackupPending,
+			message: fmt.Sprintf("deletionPolicy %s: waiting for DatabaseBackup %s to complete",
+				stardogv1beta1.DeletionPolicyBackupThenDelete, name),
+		}
+	}
+	return nil
+}
 
+// dropDatabase removes the database and the users and roles created for it from the Stardog server
+func dropDatabase(ctx context.Context, stardogClient stardogapi.StardogAPI, database *stardogv1beta1.Database) error {
 	read, write := getUserRoleNames(database.Spec.DatabaseName)
 	customUser := database.Status.AddUserForNonHiddenGraphs
 
 	// Remove assigned roles to users
-	param := users_roles.NewRemoveRoleOfUserParams()
-	_, err = stardogClient.UsersRoles.RemoveRoleOfUser(param.WithUser(read).WithRole(read), auth)
-	if err != nil && !NotFound(err) {
+	err := stardogClient.DeleteUserRole(ctx, read, read)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove assigned role %s from user %s: %v", read, read, err)
 	}
-	_, err = stardogClient.UsersRoles.RemoveRoleOfUser(param.WithUser(write).WithRole(read), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteUserRole(ctx, write, read)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove assigned role %s from user %s: %v", read, write, err)
 	}
-	_, err = stardogClient.UsersRoles.RemoveRoleOfUser(param.WithUser(write).WithRole(write), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteUserRole(ctx, write, write)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove assigned role %s from user %s: %v", write, write, err)
 	}
 
 	// Remove read and write roles
-	roleParam := roles.NewRemoveRoleParams()
-	_, err = stardogClient.Roles.RemoveRole(roleParam.WithRole(read), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteRole(ctx, read)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove read role %s: %v", read, err)
 	}
-	_, err = stardogClient.Roles.RemoveRole(roleParam.WithRole(write), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteRole(ctx, write)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove write role %s: %v", write, err)
 	}
 
 	// Remove read and write users
-	userParam := users.NewRemoveUserParams()
-	_, err = stardogClient.Users.RemoveUser(userParam.WithUser(read), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteUser(ctx, read)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove read user %s: %v", read, err)
 	}
-	_, err = stardogClient.Users.RemoveUser(userParam.WithUser(write), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteUser(ctx, write)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove write user %s: %v", write, err)
 	}
 
 	// remove the custom user associated with this db
-	err = deleteCustomUser(stardogClient, customUser, auth)
-	if err != nil {
-		return fmt.Errorf("cannot delete customUser user %s: %v", customUser, err)
+	if customUser != "" {
+		err = deleteCustomUser(ctx, stardogClient, customUser)
+		if err != nil {
+			return fmt.Errorf("cannot delete customUser user %s: %v", customUser, err)
+		}
 	}
 	// Remove database
-	params := db.NewDropDatabaseParams().WithDb(database.Spec.DatabaseName)
-	_, err = stardogClient.Db.DropDatabase(params, auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DropDatabase(ctx, database.Spec.DatabaseName)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("error dropping database %s: %w", database.Name, err)
 	}
 
 	return nil
 }
 
-func (r *DatabaseReconciler) validateSpecification(ctx context.Context, database *stardogv1beta1.Database) error {
-	r.Log.V(1).Info("validating StardogRoleSpec")
-	spec := &database.Spec
-	status := &database.Status
+func (r *DatabaseReconciler) validateSpecification(database *stardogv1beta1.Database) error {
+	r.Log.V(1).Info("validating DatabaseSpec")
+	spec := database.Spec
+	status := database.Status
 
 	if len(spec.StardogInstanceRefs) == 0 {
 		return fmt.Errorf(".spec.StardogInstanceRefs is required to have at least one instance")
@@ -285,24 +577,14 @@ func (r *DatabaseReconciler) validateSpecification(ctx context.Context, database
 		return fmt.Errorf(".spec.NamedGraphPrefix is required")
 	}
 
-	// If status is not set for database name then we treat it as a creation (first object reconciliation)
-	if status.DatabaseName == "" {
-		status.NamedGraphPrefix = spec.NamedGraphPrefix
-		status.AddUserForNonHiddenGraphs = spec.AddUserForNonHiddenGraphs
-		status.StardogInstanceRefs = spec.StardogInstanceRefs
-		status.DatabaseName = spec.DatabaseName
-		status.Options = spec.Options
-
-		err := r.Client.Status().Update(ctx, database)
-		if err != nil {
-			r.Log.Error(err, "could not update Database Status", getLoggingKeysAndValuesForDatabase(database)...)
-			return err
-		}
+	// The API server rejects changes to immutable fields, an object whose spec differs from the synchronized status
+	// has bypassed the validation.
+	if status.DatabaseName != "" && status.DatabaseName != spec.DatabaseName {
+		return fmt.Errorf(".spec.DatabaseName is immutable, cannot change it from %s to %s", status.DatabaseName, spec.DatabaseName)
+	}
+	if status.NamedGraphPrefix != "" && status.NamedGraphPrefix != spec.NamedGraphPrefix {
+		return fmt.Errorf(".spec.NamedGraphPrefix is immutable, cannot change it from %s to %s", status.NamedGraphPrefix, spec.NamedGraphPrefix)
 	}
-
-	spec.NamedGraphPrefix = status.NamedGraphPrefix
-	spec.DatabaseName = status.DatabaseName
-	spec.Options = status.Options
 
 	return nil
 }
@@ -320,24 +602,56 @@ func (r *DatabaseReconciler) syncDB(dr *DatabaseReconciliation) error {
 		}
 	}
 
+	if len(dr.pendingOptions) > 0 {
+		dr.reconciliationContext.SetStatusCondition(createStatusConditionOptionsSynchronized(false,
+			fmt.Sprintf("Options cannot be applied: %s", strings.Join(dr.pendingOptions, ", "))))
+	} else {
+		dr.reconciliationContext.SetStatusCondition(createStatusConditionOptionsSynchronized(true, "Options synchronized"))
+	}
+
 	// Remove a database for any removed instance from spec.StardogInstanceRefs
 	for _, instance := range getRemovedInstances(specRefs, statusRefs) {
-		if err := r.deleteDatabase(dr, instance); err != nil {
-			return fmt.Errorf("cannot delete database %s for instance %s: %v", dbName, instance.Name, err)
+		if err := r.removeDatabase(dr, instance); err != nil {
+			return fmt.Errorf("cannot delete database %s for instance %s: %w", dbName, instance.Name, err)
 		}
+		deleteInstanceStatistics(dbName, instance)
 	}
 
 	return nil
 }
 
+// removeDatabase applies the deletion policy to the database on an instance which has been removed from
+// spec.StardogInstanceRefs
+func (r *DatabaseReconciler) removeDatabase(dr *DatabaseReconciliation, instance stardogv1beta1.StardogInstanceRef) error {
+	database := dr.resource
+	instances := []stardogv1beta1.StardogInstanceRef{instance}
+
+	switch policy := database.GetDeletionPolicy(); policy {
+	case stardogv1beta1.DeletionPolicyRetain:
+		r.Log.Info(fmt.Sprintf("retaining Stardog Database %s on removed instance %s/%s due to deletionPolicy %s",
+			database.Spec.DatabaseName, instance.Namespace, instance.Name, policy))
+		return nil
+	case stardogv1beta1.DeletionPolicyBackupThenDelete:
+		if err := r.backupBeforeDeletion(dr, getRemovalBackupName(database, instance), instances); err != nil {
+			return err
+		}
+	case stardogv1beta1.DeletionPolicyArchive:
+		if err := r.createArchivedDatabase(dr, getRemovalArchiveName(database, instance), instances, now()); err != nil {
+			return err
+		}
+		return r.archiveDatabase(dr, instance)
+	}
+	return r.deleteDatabase(dr, instance)
+}
+
 func (r *DatabaseReconciler) sync(dr *DatabaseReconciliation, instance stardogv1beta1.StardogInstanceRef) error {
 	rc := dr.reconciliationContext
-	stardogClient := dr.reconciliationContext.stardogClient
+	ctx := rc.context
 	database := dr.resource
 	customUser := database.Spec.AddUserForNonHiddenGraphs
 	customUserEnabled := customUser != ""
 
-	auth, disabled, err := rc.initStardogClientFromRef(r.Client, instance)
+	stardogClient, disabled, err := rc.initStardogClientFromRef(r.Client, instance)
 	if err != nil {
 		return fmt.Errorf("cannot initialize stardog client: %v", err)
 	}
@@ -347,24 +661,61 @@ func (r *DatabaseReconciler) sync(dr *DatabaseReconciliation, instance stardogv1
 	}
 
 	// Generate and save credentials in k8s
-	secretName := getUsersCredentialSecret(database.Spec.DatabaseName, instance.Name)
+	secrets, err := r.credentialSecrets(dr, instance)
+	if err != nil {
+		return err
+	}
 	readName, writeName := getUserRoleNames(database.Spec.DatabaseName)
 
 	// Create database in Stardog if it does not exist
-	liveDatabases, err := stardogClient.Db.ListDatabases(nil, auth)
+	liveDatabases, err := stardogClient.ListDatabases(ctx)
 	if err != nil {
 		r.Log.Error(err, "error listing databases")
 		return err
 	}
 
-	if !slices.Contains(liveDatabases.Payload.Databases, database.Spec.DatabaseName) {
-		err = createDatabase(database, stardogClient, auth)
+	options, err := decodeDBOptions(database.Spec.Options)
+	if err != nil {
+		return err
+	}
+	properties := map[string]stardogapi.ConfigProperty{}
+	if len(options) > 0 {
+		properties, err = stardogClient.GetConfigProperties(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot get config properties: %v", err)
+		}
+		if err := validateDBOptions(options, properties); err != nil {
+			return err
+		}
+	}
+
+	created := !slices.Contains(liveDatabases, database.Spec.DatabaseName)
+	if created {
+		err = createDatabase(ctx, database, stardogClient)
 		if err != nil {
 			return fmt.Errorf("failed to create database %v", err)
 		}
 		r.Log.Info("created Stardog database", "name", database.Spec.DatabaseName)
 	}
 
+	state, err := r.syncState(dr, stardogClient, instance, created)
+	if err != nil {
+		return fmt.Errorf("cannot synchronize state of database %s: %v", database.Spec.DatabaseName, err)
+	}
+	instanceStatus := stardogv1beta1.DatabaseInstanceStatus{StardogInstanceRef: instance, State: state}
+	r.refreshStatistics(dr, stardogClient, &instanceStatus)
+	dr.instances = append(dr.instances, instanceStatus)
+
+	if !created {
+		pendingOptions, err := r.syncOptions(ctx, stardogClient, database, options, properties)
+		if err != nil {
+			return fmt.Errorf("cannot synchronize options of database %s: %v", database.Spec.DatabaseName, err)
+		}
+		for _, option := range pendingOptions {
+			dr.pendingOptions = append(dr.pendingOptions, fmt.Sprintf("%s on instance %s/%s", option, instance.Namespace, instance.Name))
+		}
+	}
+
 	// create default read and write users
 	readPwd, err := generatePassword()
 	if err != nil {
@@ -374,39 +725,60 @@ func (r *DatabaseReconciler) sync(dr *DatabaseReconciliation, instance stardogv1
 	if err != nil {
 		return err
 	}
-	usrs := []models.User{
-		{Password: []string{readPwd}, Username: &readName},
-		{Password: []string{writePwd}, Username: &writeName},
-	}
-	rolenames := []models.Rolename{
-		{Rolename: &readName},
-		{Rolename: &writeName},
+	usrs := []stardogapi.UserCredentials{
+		{Name: readName, Password: readPwd},
+		{Name: writeName, Password: writePwd},
 	}
+	rolenames := []string{readName, writeName}
 	if customUserEnabled {
 		customUsrPwd, err := generatePassword()
 		if err != nil {
 			return err
 		}
-		usrs = append(usrs, models.User{Password: []string{customUsrPwd}, Username: &customUser})
-		rolenames = append(rolenames, models.Rolename{Rolename: &customUser})
+		usrs = append(usrs, stardogapi.UserCredentials{Name: customUser, Password: customUsrPwd})
+		rolenames = append(rolenames, customUser)
 	}
 
-	createdUsrs, err := createDefaultUsersForDB(stardogClient, auth, usrs)
+	createdUsrs, err := createDefaultUsersForDB(ctx, stardogClient, usrs)
 	if err != nil {
-		r.Log.Error(err, "error creating users", "users", usrs)
+		r.Log.Error(err, "error creating users", "users", getUserNames(usrs))
 		return err
 	}
 	// don't create any credential secret if no users have been created in Stardog
 	if len(createdUsrs) != 0 {
-		err = r.createCredentials(dr, secretName, createdUsrs)
+		err = r.createCredentials(dr, secrets, createdUsrs)
+		if err != nil {
+			r.Log.Error(err, "error creating secret credentials", "users", getUserNames(createdUsrs))
+			return err
+		}
+	}
+
+	recovered, err := recoverCredentials(ctx, r.Client, stardogClient, secrets, getUserNames(usrs))
+	if err != nil {
+		return err
+	}
+	if len(recovered) > 0 {
+		r.Log.Info("recovered credential secret", "namespace", rc.namespace, "name", secrets.name, "users", recovered)
+		rc.setCredentialsRecovered(rc.namespace, secrets.name, recovered)
+	}
+
+	if dr.credentialRotation != nil {
+		existingUsrs := slices.Filter(nil, getUserNames(usrs), func(name string) bool {
+			return !slices.Contains(getUserNames(createdUsrs), name)
+		})
+		err = rotatePasswords(ctx, r.Client, stardogClient, dr.credentialRotation, secrets, existingUsrs)
+		if err != nil {
+			return fmt.Errorf("cannot rotate credentials: %v", err)
+		}
+		err = restartDeployments(ctx, r.Client, rc.namespace, database.Spec.CredentialRotation.RolloutSelector, dr.credentialRotation.time.Time)
 		if err != nil {
-			r.Log.Error(err, "error creating secret credentials", "users", createdUsrs)
 			return err
 		}
+		r.Log.Info("rotated credentials", "namespace", rc.namespace, "secret", secrets.name, "users", existingUsrs)
 	}
 
 	// create default read and write roles
-	err = createDefaultRolesForDB(stardogClient, auth, rolenames)
+	err = createDefaultRolesForDB(ctx, stardogClient, rolenames)
 	if err != nil {
 		r.Log.Error(err, "error creating roles", "roles", rolenames)
 		return err
@@ -416,13 +788,13 @@ func (r *DatabaseReconciler) sync(dr *DatabaseReconciliation, instance stardogv1
 	readPerms := getDBReadPermissions(database.Spec.DatabaseName)
 	writePerms := getDBWritePermissions(database.Spec.DatabaseName)
 
-	err = createDefaultPermissions(stardogClient, auth, readName, readPerms)
+	err = createDefaultPermissions(ctx, stardogClient, readName, readPerms)
 	if err != nil {
 		r.Log.Error(err, "adding permission to role failed", "role", readName, "permission", readPerms)
 		return err
 	}
 
-	err = createDefaultPermissions(stardogClient, auth, writeName, append(writePerms, readPerms...))
+	err = createDefaultPermissions(ctx, stardogClient, writeName, append(writePerms, readPerms...))
 	if err != nil {
 		r.Log.Error(err, "adding permission to role failed", "role", writeName, "permission", writePerms)
 		return err
@@ -430,7 +802,7 @@ func (r *DatabaseReconciler) sync(dr *DatabaseReconciliation, instance stardogv1
 
 	if customUserEnabled {
 		perms := readPerms
-		err = createDefaultPermissions(stardogClient, auth, customUser, perms)
+		err = createDefaultPermissions(ctx, stardogClient, customUser, perms)
 		if err != nil {
 			r.Log.Error(err, "adding permission to role failed", "role", writeName, "permission", writePerms)
 			return err
@@ -438,7 +810,7 @@ func (r *DatabaseReconciler) sync(dr *DatabaseReconciliation, instance stardogv1
 	}
 
 	// assign roles to users
-	err = assignDefaultRoles(stardogClient, auth, usrs)
+	err = assignDefaultRoles(ctx, stardogClient, usrs)
 	if err != nil {
 		r.Log.Error(err, "error assigning roles to users")
 		return err
@@ -447,7 +819,7 @@ func (r *DatabaseReconciler) sync(dr *DatabaseReconciliation, instance stardogv1
 	// delete custom user in case it has been removed or changed from the resource
 	statusCustomUser := database.Status.AddUserForNonHiddenGraphs
 	if (statusCustomUser != "" && customUser == "") || (statusCustomUser != "" && statusCustomUser != customUser) {
-		err = deleteCustomUser(stardogClient, statusCustomUser, auth)
+		err = deleteCustomUser(ctx, stardogClient, statusCustomUser)
 		if err != nil {
 			return fmt.Errorf("cannot delete custom user %s: %v", customUser, err)
 		}
@@ -456,39 +828,195 @@ func (r *DatabaseReconciler) sync(dr *DatabaseReconciliation, instance stardogv1
 	return nil
 }
 
-func deleteCustomUser(stardogClient *stardog.Stardog, name string, auth runtime.ClientAuthInfoWriter) error {
-	_, err := stardogClient.UsersRoles.RemoveRoleOfUser(users_roles.NewRemoveRoleOfUserParams().WithUser(name).WithRole(name), auth)
-	if err != nil && !NotFound(err) {
+// syncOptions applies the options of the spec which differ from the live options of the database. Options which
+// cannot be changed while the database is online are only applied if the OptionsUpdatePolicy allows to take the
+// database offline, immutable options are never applied. The options which have not been applied are returned.
+func (r *DatabaseReconciler) syncOptions(ctx context.Context, stardogClient stardogapi.StardogAPI, database *stardogv1beta1.Database,
+	desired map[string]any, properties map[string]stardogapi.ConfigProperty) ([]string, error) {
+	dbName := database.Spec.DatabaseName
+	if len(desired) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	live, err := stardogClient.GetDatabaseOptions(ctx, dbName, names)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get options: %v", err)
+	}
+
+	direct := map[string]any{}
+	offline := map[string]any{}
+	var pending []string
+	for _, name := range names {
+		if optionValuesEqual(live[name], desired[name]) {
+			continue
+		}
+		property := properties[name]
+		switch {
+		case property.MutableWhileOnline:
+			direct[name] = desired[name]
+		case !property.Mutable:
+			pending = append(pending, fmt.Sprintf("%s (immutable)", name))
+		case database.GetState() == stardogv1beta1.DatabaseOffline:
+			// The database has been taken offline by syncState already
+			direct[name] = desired[name]
+		case database.Spec.OptionsUpdatePolicy == stardogv1beta1.OptionsUpdateOfflineAllowed:
+			offline[name] = desired[name]
+		default:
+			pending = append(pending, fmt.Sprintf("%s (requires offline)", name))
+		}
+	}
+
+	if len(direct) > 0 {
+		if err := stardogClient.SetDatabaseOptions(ctx, dbName, direct); err != nil {
+			return nil, fmt.Errorf("cannot set options: %v", err)
+		}
+		r.Log.Info("updated options of Stardog database", "name", dbName, "options", direct)
+	}
+	if len(offline) > 0 {
+		if err := setOptionsOffline(ctx, stardogClient, dbName, offline); err != nil {
+			return nil, err
+		}
+		r.Log.Info("updated options of Stardog database offline", "name", dbName, "options", offline)
+	}
+	return pending, nil
+}
+
+// syncState brings the database on the instance into the desired state and returns its live state. A database which is
+// found offline although it has been online before is brought back online and a warning Event is recorded.
+func (r *DatabaseReconciler) syncState(dr *DatabaseReconciliation, stardogClient stardogapi.StardogAPI, instance stardogv1beta1.StardogInstanceRef, created bool) (stardogv1beta1.DatabaseState, error) {
+	ctx := dr.reconciliationContext.context
+	database := dr.resource
+	dbName := database.Spec.DatabaseName
+	desired := database.GetState()
+
+	// Stardog creates databases online
+	online := true
+	if !created {
+		var err error
+		online, err = stardogClient.IsDatabaseOnline(ctx, dbName)
+		if err != nil {
+			return "", fmt.Errorf("cannot get state: %v", err)
+		}
+	}
+
+	switch {
+	case desired == stardogv1beta1.DatabaseOnline && !online:
+		if err := stardogClient.OnlineDatabase(ctx, dbName); err != nil {
+			return "", fmt.Errorf("cannot bring database online: %v", err)
+		}
+		r.Log.Info("brought Stardog database online", "name", dbName, "instance", instance.Name)
+		if previous := getDatabaseInstanceStatus(database.Status.Instances, instance); previous == nil || previous.State != stardogv1beta1.DatabaseOffline {
+			r.Recorder.Eventf(database, v1.EventTypeWarning, "UnexpectedOffline",
+				"Database %s was offline unexpectedly on StardogInstance %s/%s and has been brought back online", dbName, instance.Namespace, instance.Name)
+		}
+	case desired == stardogv1beta1.DatabaseOffline && online:
+		if err := stardogClient.OfflineDatabase(ctx, dbName); err != nil {
+			return "", fmt.Errorf("cannot take database offline: %v", err)
+		}
+		r.Log.Info("took Stardog database offline", "name", dbName, "instance", instance.Name)
+	}
+	return desired, nil
+}
+
+// getDatabaseInstanceStatus returns the status of the database on the instance, or nil if there is none
+func getDatabaseInstanceStatus(instances []stardogv1beta1.DatabaseInstanceStatus, instance stardogv1beta1.StardogInstanceRef) *stardogv1beta1.DatabaseInstanceStatus {
+	for i := range instances {
+		if instances[i].StardogInstanceRef == instance {
+			return &instances[i]
+		}
+	}
+	return nil
+}
+
+// refreshStatistics refreshes the statistics of the database on the instance once StatsFreq has passed since the last
+// refresh and exports them as metrics. Otherwise, and for offline databases, the last known statistics are kept. A
+// failed refresh is logged only, as it must not block the synchronization.
+func (r *DatabaseReconciler) refreshStatistics(dr *DatabaseReconciliation, stardogClient stardogapi.StardogAPI, status *stardogv1beta1.DatabaseInstanceStatus) {
+	ctx := dr.reconciliationContext.context
+	dbName := dr.resource.Spec.DatabaseName
+	instance := status.StardogInstanceRef
+
+	if previous := getDatabaseInstanceStatus(dr.resource.Status.Instances, instance); previous != nil {
+		status.Triples = previous.Triples
+		status.StatisticsRefreshTime = previous.StatisticsRefreshTime
+	}
+	defer func() { exportDatabaseStatistics(dbName, *status) }()
+
+	if StatsFreq == 0 || status.State == stardogv1beta1.DatabaseOffline {
+		return
+	}
+	if status.StatisticsRefreshTime != nil && now().Before(status.StatisticsRefreshTime.Add(StatsFreq)) {
+		return
+	}
+
+	triples, err := stardogClient.GetDatabaseSize(ctx, dbName)
+	if err != nil {
+		r.Log.Error(err, "cannot refresh statistics of Stardog database", "name", dbName, "instance", instance.Name)
+		return
+	}
+	refreshTime := metav1.NewTime(now())
+	status.Triples = &triples
+	status.StatisticsRefreshTime = &refreshTime
+}
+
+// setOptionsOffline takes the database offline, sets the options and brings it back online, even if the options
+// cannot be set
+func setOptionsOffline(ctx context.Context, stardogClient stardogapi.StardogAPI, dbName string, options map[string]any) error {
+	if err := stardogClient.OfflineDatabase(ctx, dbName); err != nil {
+		return fmt.Errorf("cannot take database offline: %v", err)
+	}
+	setErr := stardogClient.SetDatabaseOptions(ctx, dbName, options)
+	if err := stardogClient.OnlineDatabase(ctx, dbName); err != nil {
+		return fmt.Errorf("cannot bring database online: %v", err)
+	}
+	if setErr != nil {
+		return fmt.Errorf("cannot set options: %v", setErr)
+	}
+	return nil
+}
+
+// optionValuesEqual compares option values independent of their JSON type, Stardog returns e.g. booleans for options
+// which are commonly given as strings
+func optionValuesEqual(live, desired any) bool {
+	return strings.EqualFold(fmt.Sprint(live), fmt.Sprint(desired))
+}
+
+func deleteCustomUser(ctx context.Context, stardogClient stardogapi.StardogAPI, name string) error {
+	err := stardogClient.DeleteUserRole(ctx, name, name)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove assigned role %s from user %s: %v", name, name, err)
 	}
-	_, err = stardogClient.Roles.RemoveRole(roles.NewRemoveRoleParams().WithRole(name), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteRole(ctx, name)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove customUser role %s: %v", name, err)
 	}
-	_, err = stardogClient.Users.RemoveUser(users.NewRemoveUserParams().WithUser(name), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteUser(ctx, name)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove customUser user %s: %v", name, err)
 	}
 	return nil
 }
 
-func assignDefaultRoles(stardogClient *stardog.Stardog, auth runtime.ClientAuthInfoWriter, usrs []models.User) error {
+func assignDefaultRoles(ctx context.Context, stardogClient stardogapi.StardogAPI, usrs []stardogapi.UserCredentials) error {
 	for _, usr := range usrs {
-		username := *usr.Username
+		username := usr.Name
 		//role name is the same as username
-		rolename := *usr.Username
-		resp, err := stardogClient.UsersRoles.ListUserRoles(users_roles.NewListUserRolesParams().WithUser(username), auth)
-		if err != nil || !resp.IsSuccess() {
+		rolename := usr.Name
+		userRoles, err := stardogClient.GetUserRoles(ctx, username)
+		if err != nil {
 			return fmt.Errorf("error getting user roles for user %s: %v", username, err)
 		}
 
-		if !slices.Contains(resp.Payload.Roles, username) {
-			params := users_roles.NewAddRoleParams().
-				WithUser(username).
-				WithRole(&models.Rolename{Rolename: &rolename})
-			roleResp, err := stardogClient.UsersRoles.AddRole(params, auth)
-			if err != nil || !roleResp.IsSuccess() {
-				return fmt.Errorf("error assigning role %s to user %s: %v", username, rolename, err)
+		if !slices.Contains(userRoles, rolename) {
+			err = stardogClient.AddUserRole(ctx, username, rolename)
+			if err != nil {
+				return fmt.Errorf("error assigning role %s to user %s: %v", rolename, username, err)
 			}
 		}
 	}
@@ -507,8 +1035,12 @@ func getRemovedInstances(RefsA []stardogv1beta1.StardogInstanceRef, RefsB []star
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *DatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&stardogv1beta1.Database{}).
+	if err := indexField(mgr, &stardogv1beta1.Database{}, instanceIndex, indexDatabaseInstances); err != nil {
+		return err
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&stardogv1beta1.Database{})
+	return watchInstanceDependents(b, r.Client, func() client.ObjectList { return &stardogv1beta1.DatabaseList{} }).
 		Complete(r)
 }
 
@@ -520,94 +1052,101 @@ func generatePassword() (string, error) {
 	return pass, nil
 }
 
-// Generate and save credentials for Database
-func (r *DatabaseReconciler) createCredentials(dr *DatabaseReconciliation, secretName string, users []models.User) error {
+// credentialSecrets returns the credential Secrets of the users of the Database on the given instance, including the
+// connection details if requested by the secret template
+func (r *DatabaseReconciler) credentialSecrets(dr *DatabaseReconciliation, instance stardogv1beta1.StardogInstanceRef) (*credentialSecrets, error) {
 	database := dr.resource
 	rc := dr.reconciliationContext
-	ctx := dr.reconciliationContext.context
-
-	secret := &corev1.Secret{}
-	err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: rc.namespace}, secret)
+	secrets := &credentialSecrets{
+		namespace: rc.namespace,
+		name:      getUsersCredentialSecret(database.Spec.DatabaseName, instance.Name),
+		owner:     database,
+		scheme:    r.Scheme,
+		template:  database.Spec.SecretTemplate,
+	}
+	if secrets.template != nil && secrets.template.ConnectionDetails {
+		connection, err := getConnectionDetails(rc.context, r.Client, instance, database.Spec.DatabaseName)
+		if err != nil {
+			return nil, err
+		}
+		secrets.connection = connection
+	}
+	return secrets, nil
+}
 
-	if err == nil {
-		return nil
-	} else if !apierrors.IsNotFound(err) {
-		r.Log.Error(err, fmt.Sprintf("error getting Secret %s/%s", rc.namespace, secretName))
+// Generate and save credentials for Database
+func (r *DatabaseReconciler) createCredentials(dr *DatabaseReconciliation, secrets *credentialSecrets, users []stardogapi.UserCredentials) error {
+	passwords := map[string]string{}
+	for _, user := range users {
+		passwords[user.Name] = user.Password
+	}
+	if err := secrets.writePasswords(dr.reconciliationContext.context, r.Client, passwords); err != nil {
 		return err
 	}
 
-	secret = &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: dr.reconciliationContext.namespace,
-		}}
-	err = controllerutil.SetControllerReference(database, secret, r.Scheme)
+	r.Log.Info("stored credentials", "namespace", secrets.namespace, "secret", secrets.name, "users", getUserNames(users))
+	return nil
+}
+
+func createDatabase(ctx context.Context, database *stardogv1beta1.Database, stardogClient stardogapi.StardogAPI) error {
+	dbName := database.Spec.DatabaseName
+	options, err := decodeDBOptions(database.Spec.Options)
 	if err != nil {
 		return err
 	}
 
-	secret.StringData = map[string]string{}
-	for _, user := range users {
-		secret.StringData[*user.Username] = user.Password[0]
+	dbOptions := getDefaultDBOptions()
+	for name, value := range options {
+		dbOptions[name] = value
 	}
 
-	err = r.Create(ctx, secret)
-	if err != nil && apierrors.IsAlreadyExists(err) {
-		err = r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: secret.Namespace}, secret)
-		if err != nil {
-			r.Log.Error(err, fmt.Sprintf("error creating secret %s/%s", rc.namespace, secretName))
-			return fmt.Errorf("cannot get existing credentials from secret %s: %v", secretName, err)
-		}
-		return nil
-	}
+	err = stardogClient.CreateDatabase(ctx, dbName, dbOptions)
 	if err != nil {
-		r.Log.Error(err, fmt.Sprintf("error creating secret %s/%s", rc.namespace, secretName))
-		return err
+		return fmt.Errorf("error creating database %s: %v", dbName, err)
 	}
-
-	r.Log.Info("created credential secret", "namespace", secret.Namespace, "name", secret.Name)
 	return nil
 }
 
-func createDatabase(database *stardogv1beta1.Database, stardogClient *stardog.Stardog, auth runtime.ClientAuthInfoWriter) error {
-	dbName := database.Spec.DatabaseName
-	options := database.Spec.Options
-
-	if options != "" {
-		err := json.Unmarshal([]byte(options), &defaultDBOptions)
-		if err != nil {
-			return fmt.Errorf("cannot unmarshal options of json type: %v", err)
+// decodeDBOptions decodes the JSON values of the options of a Database spec
+func decodeDBOptions(options map[string]apiextensionsv1.JSON) (map[string]any, error) {
+	decoded := make(map[string]any, len(options))
+	for name, value := range options {
+		var v any
+		if err := json.Unmarshal(value.Raw, &v); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal value of option %s: %v", name, err)
 		}
+		decoded[name] = v
 	}
+	return decoded, nil
+}
 
-	payload, err := json.Marshal(stardogDatabaseCreate{
-		Name:    dbName,
-		Options: defaultDBOptions,
-	})
-	if err != nil {
-		return fmt.Errorf("cannot marshal root parameter to create database %s: %v", dbName, err)
+// validateDBOptions returns an error if any of the options is not a configuration property known to Stardog
+func validateDBOptions(options map[string]any, properties map[string]stardogapi.ConfigProperty) error {
+	unknown := make([]string, 0)
+	for name := range options {
+		if _, ok := properties[name]; !ok {
+			unknown = append(unknown, name)
+		}
 	}
-
-	params := db.NewCreateNewDatabaseParams().WithRoot(string(payload))
-	newDatabaseResp, err := stardogClient.Db.CreateNewDatabase(params, auth)
-	if err != nil || !newDatabaseResp.IsSuccess() {
-		return fmt.Errorf("error creating database %s: %v", dbName, err)
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown database options: %s", strings.Join(unknown, ", "))
 	}
 	return nil
 }
 
-func createDefaultUsersForDB(stardogClient *stardog.Stardog, auth runtime.ClientAuthInfoWriter, usrs []models.User) ([]models.User, error) {
-	existingUsers, err := stardogClient.Users.ListUsers(nil, auth)
-	if err != nil || !existingUsers.IsSuccess() {
-		return []models.User{}, fmt.Errorf("error listing users: %w", err)
+func createDefaultUsersForDB(ctx context.Context, stardogClient stardogapi.StardogAPI, usrs []stardogapi.UserCredentials) ([]stardogapi.UserCredentials, error) {
+	existingUsers, err := stardogClient.ListUsers(ctx)
+	if err != nil {
+		return []stardogapi.UserCredentials{}, fmt.Errorf("error listing users: %w", err)
 	}
 
-	createdUsers := make([]models.User, 0)
+	createdUsers := make([]stardogapi.UserCredentials, 0)
 	for _, user := range usrs {
-		if !slices.Contains(existingUsers.Payload.Users, *user.Username) {
-			createUserResp, err := stardogClient.Users.CreateUser(users.NewCreateUserParams().WithUser(&user), auth)
-			if err != nil || !createUserResp.IsSuccess() {
-				return []models.User{}, fmt.Errorf("error create database user %s: %w", *user.Username, err)
+		if !slices.Contains(existingUsers, user.Name) {
+			err = stardogClient.AddUser(ctx, user.Name, user.Password)
+			if err != nil {
+				return []stardogapi.UserCredentials{}, fmt.Errorf("error create database user %s: %w", user.Name, err)
 			}
 			createdUsers = append(createdUsers, user)
 		}
@@ -615,18 +1154,16 @@ func createDefaultUsersForDB(stardogClient *stardog.Stardog, auth runtime.Client
 	return createdUsers, nil
 }
 
-func createDefaultPermissions(stardogClient *stardog.Stardog, auth runtime.ClientAuthInfoWriter, role string, perms []models.Permission) error {
-	listParams := roles_permissions.NewListRolePermissionsParams().WithRole(role)
-	existingPermissionsResp, err := stardogClient.RolesPermissions.ListRolePermissions(listParams, auth)
-	if err != nil || !existingPermissionsResp.IsSuccess() {
+func createDefaultPermissions(ctx context.Context, stardogClient stardogapi.StardogAPI, role string, perms []stardogapi.Permission) error {
+	existingPermissions, err := stardogClient.GetRolePermissions(ctx, role)
+	if err != nil {
 		return fmt.Errorf("error listing role permissions: %w", err)
 	}
 
 	for _, perm := range perms {
-		if !containsPermission(existingPermissionsResp.Payload.Permissions, perm) {
-			params := roles_permissions.NewAddRolePermissionParams().WithRole(role).WithPermission(&perm)
-			permissionResp, err := stardogClient.RolesPermissions.AddRolePermission(params, auth)
-			if err != nil || !permissionResp.IsSuccess() {
+		if !containsPermission(existingPermissions, perm) {
+			err = stardogClient.AddRolePermission(ctx, role, perm)
+			if err != nil {
 				return fmt.Errorf("error create permission %#v for role %s: %w", perm, role, err)
 			}
 		}
@@ -634,49 +1171,49 @@ func createDefaultPermissions(stardogClient *stardog.Stardog, auth runtime.Clien
 	return nil
 }
 
-func createDefaultRolesForDB(stardogClient *stardog.Stardog, auth runtime.ClientAuthInfoWriter, rolenames []models.Rolename) error {
-	existingRoles, err := stardogClient.Roles.ListRoles(nil, auth)
-	if err != nil || !existingRoles.IsSuccess() {
+func createDefaultRolesForDB(ctx context.Context, stardogClient stardogapi.StardogAPI, rolenames []string) error {
+	existingRoles, err := stardogClient.GetRoles(ctx)
+	if err != nil {
 		return fmt.Errorf("error getting roles: %w", err)
 	}
 
 	for _, role := range rolenames {
-		if !slices.Contains(existingRoles.Payload.Roles, *role.Rolename) {
-			createRoleResp, err := stardogClient.Roles.CreateRole(roles.NewCreateRoleParams().WithRole(&role), auth)
-			if err != nil || !createRoleResp.IsSuccess() {
-				return fmt.Errorf("error creating role %s: %w", *role.Rolename, err)
+		if !slices.Contains(existingRoles, role) {
+			err = stardogClient.AddRole(ctx, role)
+			if err != nil {
+				return fmt.Errorf("error creating role %s: %w", role, err)
 			}
 		}
 	}
 	return nil
 }
 
-func getDBWritePermissions(database string) []models.Permission {
-	return []models.Permission{
+func getDBWritePermissions(database string) []stardogapi.Permission {
+	return []stardogapi.Permission{
 		{
-			Action:       pointer.String("WRITE"),
-			Resource:     []string{database},
-			ResourceType: pointer.String("db"),
+			Action:       "WRITE",
+			Resources:    []string{database},
+			ResourceType: "db",
 		},
 		{
-			Action:       pointer.String("WRITE"),
-			Resource:     []string{database},
-			ResourceType: pointer.String("metadata"),
+			Action:       "WRITE",
+			Resources:    []string{database},
+			ResourceType: "metadata",
 		},
 	}
 }
 
-func getDBReadPermissions(database string) []models.Permission {
-	return []models.Permission{
+func getDBReadPermissions(database string) []stardogapi.Permission {
+	return []stardogapi.Permission{
 		{
-			Action:       pointer.String("READ"),
-			Resource:     []string{database},
-			ResourceType: pointer.String("db"),
+			Action:       "READ",
+			Resources:    []string{database},
+			ResourceType: "db",
 		},
 		{
-			Action:       pointer.String("READ"),
-			Resource:     []string{database},
-			ResourceType: pointer.String("metadata"),
+			Action:       "READ",
+			Resources:    []string{database},
+			ResourceType: "metadata",
 		},
 	}
 }
@@ -691,6 +1228,29 @@ func getUserRoleNames(dbName string) (read, write string) {
 	return fmt.Sprintf("%s-read", dbName), fmt.Sprintf("%s-write", dbName)
 }
 
+// getDeletionBackupName returns the name of the DatabaseBackup created before the deletion of the Database
+func getDeletionBackupName(database *stardogv1beta1.Database) string {
+	return fmt.Sprintf("%s-deletion-%d", database.Name, database.GetDeletionTimestamp().Unix())
+}
+
+// getRemovalBackupName returns the name of the backup taken before the database is dropped on an instance removed from
+// the spec. The generation distinguishes the backups if the instance is added and removed again.
+func getRemovalBackupName(database *stardogv1beta1.Database, instance stardogv1beta1.StardogInstanceRef) string {
+	return fmt.Sprintf("%s-%s-%s-removal-%d", database.Name, instance.Namespace, instance.Name, database.Generation)
+}
+
+func getRemovalArchiveName(database *stardogv1beta1.Database, instance stardogv1beta1.StardogInstanceRef) string {
+	return fmt.Sprintf("%s-%s-%s", database.Name, instance.Namespace, instance.Name)
+}
+
 func getUsersCredentialSecret(dbName, instance string) string {
 	return fmt.Sprintf("%s-%s-credentials", dbName, instance)
 }
+
+func getUserNames(users []stardogapi.UserCredentials) []string {
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names
+}
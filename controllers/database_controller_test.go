@@ -2,23 +2,26 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"github.com/go-logr/logr/testr"
-	"github.com/go-openapi/runtime"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
 	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
-	db2 "github.com/vshn/stardog-userrole-operator/stardogrest/client/db"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles_permissions"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_roles"
-	stardogmock "github.com/vshn/stardog-userrole-operator/stardogrest/mocks"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
 	v1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"testing"
+	"time"
 )
 
 func Test_createCustomUser(t *testing.T) {
@@ -36,8 +39,7 @@ func Test_createCustomUser(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 	db := createStardogDB("test-db", "hidden-user", v1beta1.StardogInstanceRef{
 		Name:      stardogInstanceName,
 		Namespace: namespace,
@@ -67,9 +69,9 @@ func Test_createCustomUser(t *testing.T) {
 			dr: DatabaseReconciliation{
 				resource: db,
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 			},
 			expectedCustomUser:       true,
@@ -91,56 +93,53 @@ func Test_createCustomUser(t *testing.T) {
 			customUserExists := false
 			writePermissions := false
 			stardogMocked.EXPECT().
-				SetTransport(gomock.Any()).
-				AnyTimes()
-			stardogMocked.EXPECT().
-				ListDatabases(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(&db2.ListDatabasesOK{Payload: &models.Databases{}}, nil).
+				ListDatabases(gomock.Any()).
+				Return([]string{}, nil).
 				Times(1)
 			stardogMocked.EXPECT().
-				CreateNewDatabase(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(db2.NewCreateNewDatabaseCreated(), nil).
+				CreateDatabase(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
 				Times(1)
 			stardogMocked.EXPECT().
-				ListUsers(gomock.Any(), gomock.Any()).
-				Return(&users.ListUsersOK{Payload: &models.Users{}}, nil).
+				ListUsers(gomock.Any()).
+				Return([]string{}, nil).
 				Times(1)
 			stardogMocked.EXPECT().
-				CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(params *users.CreateUserParams, authInfo runtime.ClientAuthInfoWriter, opts ...users.ClientOption) (*users.CreateUserCreated, error) {
-					if *params.User.Username == "hidden-user" {
+				AddUser(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, name, _ string) error {
+					if name == "hidden-user" {
 						customUserExists = true
 					}
-					return users.NewCreateUserCreated(), nil
+					return nil
 				}).
 				Times(3)
 			stardogMocked.EXPECT().
-				ListRoles(gomock.Any(), gomock.Any()).
-				Return(&roles.ListRolesOK{Payload: &models.Roles{}}, nil).
+				GetRoles(gomock.Any()).
+				Return([]string{}, nil).
 				Times(1)
 			stardogMocked.EXPECT().
-				CreateRole(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(roles.NewCreateRoleCreated(), nil).
+				AddRole(gomock.Any(), gomock.Any()).
+				Return(nil).
 				Times(3)
 			stardogMocked.EXPECT().
-				ListRolePermissions(gomock.Any(), gomock.Any()).
-				Return(&roles_permissions.ListRolePermissionsOK{Payload: &models.Permissions{}}, nil).
+				GetRolePermissions(gomock.Any(), gomock.Any()).
+				Return([]stardogapi.Permission{}, nil).
 				Times(3)
 			stardogMocked.EXPECT().
-				ListUserRoles(gomock.Any(), gomock.Any()).
-				Return(&users_roles.ListUserRolesOK{Payload: &models.Roles{}}, nil).
+				GetUserRoles(gomock.Any(), gomock.Any()).
+				Return([]string{}, nil).
 				Times(3)
 			stardogMocked.EXPECT().
-				AddRole(gomock.Any(), gomock.Any()).
-				Return(users_roles.NewAddRoleNoContent(), nil).
+				AddUserRole(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
 				Times(3)
 			stardogMocked.EXPECT().
 				AddRolePermission(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(params *roles_permissions.AddRolePermissionParams, authInfo runtime.ClientAuthInfoWriter, opts ...users_roles.ClientOption) (*roles_permissions.AddRolePermissionCreated, error) {
-					if *params.Permission.Action == "WRITE" && params.Role == "hidden-user" {
+				DoAndReturn(func(_ context.Context, role string, permission stardogapi.Permission) error {
+					if permission.Action == "WRITE" && role == "hidden-user" {
 						writePermissions = true
 					}
-					return roles_permissions.NewAddRolePermissionCreated(), nil
+					return nil
 				}).AnyTimes()
 
 			_, err = r.reconcileDatabase(&tt.dr)
@@ -163,3 +162,840 @@ func createStardogDB(name, hiddenUser string, instanceRef v1beta1.StardogInstanc
 		},
 	}
 }
+
+func Test_validateSpecificationDatabase(t *testing.T) {
+	instanceRef := v1beta1.NewStardogInstanceRef("instance-test", "namespace-test")
+
+	tests := []struct {
+		name        string
+		database    *v1beta1.Database
+		status      v1beta1.DatabaseStatus
+		expectedErr bool
+	}{
+		{
+			name:        "GivenNewDatabase_WhenValidating_ThenNoError",
+			database:    createStardogDB("test-db", "", instanceRef),
+			expectedErr: false,
+		},
+		{
+			name:        "GivenSynchronizedDatabase_WhenSpecIsUnchanged_ThenNoError",
+			database:    createStardogDB("test-db", "", instanceRef),
+			status:      v1beta1.DatabaseStatus{DatabaseName: "test-db", NamedGraphPrefix: "https://graph.ch"},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenSynchronizedDatabase_WhenDatabaseNameChanged_ThenError",
+			database:    createStardogDB("test-db", "", instanceRef),
+			status:      v1beta1.DatabaseStatus{DatabaseName: "old-db", NamedGraphPrefix: "https://graph.ch"},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenSynchronizedDatabase_WhenNamedGraphPrefixChanged_ThenError",
+			database:    createStardogDB("test-db", "", instanceRef),
+			status:      v1beta1.DatabaseStatus{DatabaseName: "test-db", NamedGraphPrefix: "https://old-graph.ch"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := DatabaseReconciler{Log: testr.New(t)}
+			tt.database.Status = tt.status
+
+			err := r.validateSpecification(tt.database)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			assert.Equal(t, "test-db", tt.database.Spec.DatabaseName)
+		})
+	}
+}
+
+func Test_syncOptions(t *testing.T) {
+	properties := map[string]stardogapi.ConfigProperty{
+		"search.enabled":     {Name: "search.enabled", Mutable: true, MutableWhileOnline: false},
+		"query.timeout":      {Name: "query.timeout", Mutable: true, MutableWhileOnline: true},
+		"index.named.graphs": {Name: "index.named.graphs", Mutable: false},
+	}
+
+	tests := []struct {
+		name            string
+		options         map[string]any
+		policy          v1beta1.OptionsUpdatePolicy
+		state           v1beta1.DatabaseState
+		live            map[string]any
+		expectMocks     func(stardogMocked *mock.MockStardogAPI)
+		expectedPending []string
+		expectedErr     string
+	}{
+		{
+			name:    "GivenUnchangedOptions_WhenSyncing_ThenDoNothing",
+			options: map[string]any{"search.enabled": true, "query.timeout": "5m"},
+			live:    map[string]any{"search.enabled": true, "query.timeout": "5m"},
+		},
+		{
+			name:    "GivenOnlineMutableOption_WhenSyncing_ThenSetOption",
+			options: map[string]any{"search.enabled": true, "query.timeout": "10m"},
+			live:    map[string]any{"search.enabled": true, "query.timeout": "5m"},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().SetDatabaseOptions(gomock.Any(), "db-test", map[string]any{"query.timeout": "10m"}).Return(nil).Times(1)
+			},
+		},
+		{
+			name:            "GivenOfflineOption_WhenOnlineOnly_ThenReportPending",
+			options:         map[string]any{"search.enabled": "true"},
+			live:            map[string]any{"search.enabled": false},
+			expectedPending: []string{"search.enabled (requires offline)"},
+		},
+		{
+			name:    "GivenOfflineOption_WhenOfflineAllowed_ThenSetOptionOffline",
+			options: map[string]any{"search.enabled": true},
+			policy:  v1beta1.OptionsUpdateOfflineAllowed,
+			live:    map[string]any{"search.enabled": false},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				gomock.InOrder(
+					stardogMocked.EXPECT().OfflineDatabase(gomock.Any(), "db-test").Return(nil).Times(1),
+					stardogMocked.EXPECT().SetDatabaseOptions(gomock.Any(), "db-test", map[string]any{"search.enabled": true}).Return(nil).Times(1),
+					stardogMocked.EXPECT().OnlineDatabase(gomock.Any(), "db-test").Return(nil).Times(1),
+				)
+			},
+		},
+		{
+			name:    "GivenOfflineOption_WhenOnlineFailsAfterOffline_ThenReturnErrorForNextSync",
+			options: map[string]any{"search.enabled": true},
+			policy:  v1beta1.OptionsUpdateOfflineAllowed,
+			live:    map[string]any{"search.enabled": false},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				gomock.InOrder(
+					stardogMocked.EXPECT().OfflineDatabase(gomock.Any(), "db-test").Return(nil).Times(1),
+					stardogMocked.EXPECT().SetDatabaseOptions(gomock.Any(), "db-test", map[string]any{"search.enabled": true}).Return(nil).Times(1),
+					stardogMocked.EXPECT().OnlineDatabase(gomock.Any(), "db-test").Return(errors.New("timeout")).Times(1),
+				)
+			},
+			expectedErr: "cannot bring database online",
+		},
+		{
+			name:    "GivenOfflineOption_WhenDatabaseOffline_ThenSetOptionDirectly",
+			options: map[string]any{"search.enabled": true},
+			state:   v1beta1.DatabaseOffline,
+			live:    map[string]any{"search.enabled": false},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().SetDatabaseOptions(gomock.Any(), "db-test", map[string]any{"search.enabled": true}).Return(nil).Times(1)
+			},
+		},
+		{
+			name:            "GivenImmutableOption_WhenOfflineAllowed_ThenReportPending",
+			options:         map[string]any{"index.named.graphs": false},
+			policy:          v1beta1.OptionsUpdateOfflineAllowed,
+			live:            map[string]any{"index.named.graphs": true},
+			expectedPending: []string{"index.named.graphs (immutable)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			stardogMocked.EXPECT().GetDatabaseOptions(gomock.Any(), "db-test", gomock.Any()).Return(tt.live, nil).Times(1)
+			if tt.expectMocks != nil {
+				tt.expectMocks(stardogMocked)
+			}
+			database := createStardogDB("db-test", "", v1beta1.NewStardogInstanceRef("instance-test", "namespace-test"))
+			database.Spec.OptionsUpdatePolicy = tt.policy
+			database.Spec.State = tt.state
+			r := DatabaseReconciler{Log: testr.New(t)}
+
+			pending, err := r.syncOptions(context.Background(), stardogMocked, database, tt.options, properties)
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPending, pending)
+		})
+	}
+}
+
+func Test_syncState(t *testing.T) {
+	instance := v1beta1.NewStardogInstanceRef("instance-test", "namespace-test")
+
+	tests := []struct {
+		name           string
+		state          v1beta1.DatabaseState
+		previousState  v1beta1.DatabaseState
+		created        bool
+		expectMocks    func(stardogMocked *mock.MockStardogAPI)
+		expectedEvents int
+	}{
+		{
+			name:    "GivenCreatedDatabase_WhenOnlineDesired_ThenDoNothing",
+			created: true,
+		},
+		{
+			name:    "GivenCreatedDatabase_WhenOfflineDesired_ThenTakeOffline",
+			state:   v1beta1.DatabaseOffline,
+			created: true,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().OfflineDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+			},
+		},
+		{
+			name: "GivenOnlineDatabase_WhenOnlineDesired_ThenDoNothing",
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().IsDatabaseOnline(gomock.Any(), "db-test").Return(true, nil).Times(1)
+			},
+		},
+		{
+			name:          "GivenUnexpectedlyOfflineDatabase_WhenOnlineDesired_ThenBringOnlineAndRecordEvent",
+			previousState: v1beta1.DatabaseOnline,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().IsDatabaseOnline(gomock.Any(), "db-test").Return(false, nil).Times(1)
+				stardogMocked.EXPECT().OnlineDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+			},
+			expectedEvents: 1,
+		},
+		{
+			name:          "GivenOfflineDatabase_WhenSwitchedToOnline_ThenBringOnlineWithoutEvent",
+			previousState: v1beta1.DatabaseOffline,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().IsDatabaseOnline(gomock.Any(), "db-test").Return(false, nil).Times(1)
+				stardogMocked.EXPECT().OnlineDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+			},
+		},
+		{
+			name:  "GivenOnlineDatabase_WhenOfflineDesired_ThenTakeOffline",
+			state: v1beta1.DatabaseOffline,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().IsDatabaseOnline(gomock.Any(), "db-test").Return(true, nil).Times(1)
+				stardogMocked.EXPECT().OfflineDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			if tt.expectMocks != nil {
+				tt.expectMocks(stardogMocked)
+			}
+			database := createStardogDB("db-test", "", instance)
+			database.Spec.State = tt.state
+			if tt.previousState != "" {
+				database.Status.Instances = []v1beta1.DatabaseInstanceStatus{{StardogInstanceRef: instance, State: tt.previousState}}
+			}
+			recorder := record.NewFakeRecorder(1)
+			r := DatabaseReconciler{Log: testr.New(t), Recorder: recorder}
+			dr := &DatabaseReconciliation{
+				resource:              database,
+				reconciliationContext: &ReconciliationContext{context: context.Background()},
+			}
+
+			state, err := r.syncState(dr, stardogMocked, instance, tt.created)
+
+			assert.NoError(t, err)
+			assert.Equal(t, database.GetState(), state)
+			assert.Len(t, recorder.Events, tt.expectedEvents)
+		})
+	}
+}
+
+func Test_refreshStatistics(t *testing.T) {
+	instance := v1beta1.NewStardogInstanceRef("instance-test", "namespace-test")
+	refreshTime := metav1.NewTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	previousTriples := int64(21)
+
+	tests := []struct {
+		name            string
+		state           v1beta1.DatabaseState
+		statsFreq       time.Duration
+		previousRefresh *metav1.Time
+		expectMocks     func(stardogMocked *mock.MockStardogAPI)
+		expectedTriples *int64
+		expectedRefresh *metav1.Time
+	}{
+		{
+			name:      "GivenNoStatistics_WhenRefreshing_ThenGetSize",
+			statsFreq: time.Minute,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(int64(42), nil).Times(1)
+			},
+			expectedTriples: ptr.To(int64(42)),
+			expectedRefresh: &refreshTime,
+		},
+		{
+			name:            "GivenDueStatistics_WhenRefreshing_ThenGetSize",
+			statsFreq:       time.Minute,
+			previousRefresh: ptr.To(metav1.NewTime(refreshTime.Add(-time.Minute))),
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(int64(42), nil).Times(1)
+			},
+			expectedTriples: ptr.To(int64(42)),
+			expectedRefresh: &refreshTime,
+		},
+		{
+			name:            "GivenRecentStatistics_WhenRefreshing_ThenKeepStatistics",
+			statsFreq:       time.Minute,
+			previousRefresh: ptr.To(metav1.NewTime(refreshTime.Add(-time.Second))),
+			expectedTriples: &previousTriples,
+			expectedRefresh: ptr.To(metav1.NewTime(refreshTime.Add(-time.Second))),
+		},
+		{
+			name:            "GivenOfflineDatabase_WhenRefreshing_ThenKeepStatistics",
+			state:           v1beta1.DatabaseOffline,
+			statsFreq:       time.Minute,
+			previousRefresh: ptr.To(metav1.NewTime(refreshTime.Add(-time.Hour))),
+			expectedTriples: &previousTriples,
+			expectedRefresh: ptr.To(metav1.NewTime(refreshTime.Add(-time.Hour))),
+		},
+		{
+			name: "GivenDisabledRefresh_WhenRefreshing_ThenDoNothing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return refreshTime.Time }
+			defer func() { now = time.Now }()
+			StatsFreq = tt.statsFreq
+			defer func() { StatsFreq = 0 }()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			if tt.expectMocks != nil {
+				tt.expectMocks(stardogMocked)
+			}
+			database := createStardogDB("db-test", "", instance)
+			if tt.previousRefresh != nil {
+				database.Status.Instances = []v1beta1.DatabaseInstanceStatus{
+					{StardogInstanceRef: instance, Triples: &previousTriples, StatisticsRefreshTime: tt.previousRefresh},
+				}
+			}
+			r := DatabaseReconciler{Log: testr.New(t)}
+			dr := &DatabaseReconciliation{
+				resource:              database,
+				reconciliationContext: &ReconciliationContext{context: context.Background()},
+			}
+			status := v1beta1.DatabaseInstanceStatus{StardogInstanceRef: instance, State: tt.state}
+			deleteInstanceStatistics("db-test", instance)
+			defer deleteInstanceStatistics("db-test", instance)
+
+			r.refreshStatistics(dr, stardogMocked, &status)
+
+			assert.Equal(t, tt.expectedTriples, status.Triples)
+			assert.Equal(t, tt.expectedRefresh, status.StatisticsRefreshTime)
+			if tt.expectedTriples != nil {
+				assert.Equal(t, float64(*tt.expectedTriples), testutil.ToFloat64(databaseTriples.WithLabelValues("namespace-test", "db-test", "instance-test")))
+			} else {
+				assert.False(t, databaseTriples.DeleteLabelValues("namespace-test", "db-test", "instance-test"))
+			}
+		})
+	}
+}
+
+func Test_createDatabase(t *testing.T) {
+	withDefaults := func(options map[string]any) map[string]any {
+		merged := getDefaultDBOptions()
+		for name, value := range options {
+			merged[name] = value
+		}
+		return merged
+	}
+
+	// The cases run in order against the same defaults, so options of one database must not leak into the next one
+	tests := []struct {
+		name            string
+		options         map[string]apiextensionsv1.JSON
+		expectedOptions map[string]any
+		expectedErr     bool
+	}{
+		{
+			name:            "GivenOptions_WhenCreating_ThenMergeOptionsWithDefaults",
+			options:         map[string]apiextensionsv1.JSON{"search.enabled": {Raw: []byte(`true`)}},
+			expectedOptions: withDefaults(map[string]any{"search.enabled": true}),
+		},
+		{
+			name:            "GivenNoOptions_WhenCreatingAfterDatabaseWithOptions_ThenUseDefaultsOnly",
+			expectedOptions: getDefaultDBOptions(),
+		},
+		{
+			name:            "GivenDefaultOverride_WhenCreating_ThenOverrideDefault",
+			options:         map[string]apiextensionsv1.JSON{"query.all.graphs": {Raw: []byte(`"false"`)}},
+			expectedOptions: withDefaults(map[string]any{"query.all.graphs": "false"}),
+		},
+		{
+			name:        "GivenInvalidOption_WhenCreating_ThenReturnError",
+			options:     map[string]apiextensionsv1.JSON{"search.enabled": {Raw: []byte(`{`)}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			if !tt.expectedErr {
+				stardogMocked.EXPECT().CreateDatabase(gomock.Any(), "db-test", tt.expectedOptions).Return(nil).Times(1)
+			}
+			database := createStardogDB("db-test", "", v1beta1.NewStardogInstanceRef("instance-test", "namespace-test"))
+			database.Spec.Options = tt.options
+
+			err := createDatabase(context.Background(), database, stardogMocked)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+		})
+	}
+}
+
+func Test_validateDBOptions(t *testing.T) {
+	properties := map[string]stardogapi.ConfigProperty{"search.enabled": {Name: "search.enabled"}}
+
+	tests := []struct {
+		name        string
+		options     map[string]any
+		expectedErr bool
+	}{
+		{
+			name:        "GivenKnownOptions_WhenValidating_ThenNoError",
+			options:     map[string]any{"search.enabled": true},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenUnknownOption_WhenValidating_ThenError",
+			options:     map[string]any{"search.enabled": true, "search.enabeld": true},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDBOptions(tt.options, properties)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+		})
+	}
+}
+
+func Test_deleteDatabases(t *testing.T) {
+	namespace := "namespace-test"
+	instanceRef := v1beta1.NewStardogInstanceRef("instance-test", namespace)
+	expectDrop := func(stardogMocked *mock.MockStardogAPI) {
+		stardogMocked.EXPECT().DeleteUserRole(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(3)
+		stardogMocked.EXPECT().DeleteRole(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		stardogMocked.EXPECT().DeleteUser(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		stardogMocked.EXPECT().DropDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+	}
+
+	tests := []struct {
+		name            string
+		policy          v1beta1.DeletionPolicy
+		annotations     map[string]string
+		databaseName    string
+		organizations   []client.Object
+		backupPhase     v1beta1.DatabaseBackupPhase
+		expectMocks     func(stardogMocked *mock.MockStardogAPI)
+		expectedReason  string
+		expectedErr     error
+		expectedErrMsg  string
+		expectedBackup  bool
+		expectedArchive bool
+		expectedDeleted bool
+	}{
+		{
+			name:        "GivenDeletionProtection_WhenDeleting_ThenBlockDeletion",
+			policy:      v1beta1.DeletionPolicyForce,
+			annotations: map[string]string{v1alpha1.DeletionProtectionAnnotation: "true"},
+			expectedErr: errDeletionProtected,
+		},
+		{
+			name:         "GivenOrganizationsOfOtherDatabases_WhenDeleting_ThenBlockDeletionByOwnOrganizations",
+			policy:       v1beta1.DeletionPolicyForce,
+			databaseName: "migrated-db-test",
+			organizations: []client.Object{
+				createOrg("org-test", "db-test", []v1beta1.NamedGraph{{Name: "graph1"}}),
+				createOrg("other-org-test", "migrated-db-test", []v1beta1.NamedGraph{{Name: "graph1"}}),
+				createOrg("another-org-test", "other-db-test", []v1beta1.NamedGraph{{Name: "graph1"}}),
+			},
+			expectedErrMsg: "cannot delete database while having 1 organizations",
+		},
+		{
+			name:            "GivenRetainPolicy_WhenDeleting_ThenReleaseFinalizerWithoutDropping",
+			policy:          v1beta1.DeletionPolicyRetain,
+			expectedDeleted: true,
+		},
+		{
+			name:   "GivenDeleteIfEmptyPolicy_WhenDatabaseIsEmpty_ThenDropDatabase",
+			policy: v1beta1.DeletionPolicyDeleteIfEmpty,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(int64(0), nil).Times(1)
+				expectDrop(stardogMocked)
+			},
+			expectedDeleted: true,
+		},
+		{
+			name: "GivenDefaultPolicy_WhenDatabaseIsNotEmpty_ThenKeepDatabase",
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(int64(42), nil).Times(1)
+			},
+			expectedReason: v1alpha1.ReasonNotEmpty,
+		},
+		{
+			name:   "GivenForcePolicy_WhenDatabaseIsNotEmpty_ThenDropDatabase",
+			policy: v1beta1.DeletionPolicyForce,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				expectDrop(stardogMocked)
+			},
+			expectedDeleted: true,
+		},
+		{
+			name:           "GivenBackupThenDeletePolicy_WhenNoBackupExists_ThenCreateBackupAndWait",
+			policy:         v1beta1.DeletionPolicyBackupThenDelete,
+			expectedReason: v1alpha1.ReasonBackupPending,
+			expectedBackup: true,
+		},
+		{
+			name:           "GivenBackupThenDeletePolicy_WhenBackupIsRunning_ThenWait",
+			policy:         v1beta1.DeletionPolicyBackupThenDelete,
+			backupPhase:    v1beta1.DatabaseBackupRunning,
+			expectedReason: v1alpha1.ReasonBackupPending,
+			expectedBackup: true,
+		},
+		{
+			name:           "GivenBackupThenDeletePolicy_WhenBackupFailed_ThenKeepDatabase",
+			policy:         v1beta1.DeletionPolicyBackupThenDelete,
+			backupPhase:    v1beta1.DatabaseBackupFailed,
+			expectedErrMsg: "deletionPolicy BackupThenDelete: DatabaseBackup db-test-deletion-1704067200 failed, delete it to back up the database again",
+			expectedBackup: true,
+		},
+		{
+			name:   "GivenArchivePolicy_WhenDeleting_ThenTakeDatabaseOfflineAndRecordArchive",
+			policy: v1beta1.DeletionPolicyArchive,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().OfflineDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+				stardogMocked.EXPECT().SetUserEnabled(gomock.Any(), "db-test-read", false).Return(nil).Times(1)
+				stardogMocked.EXPECT().SetUserEnabled(gomock.Any(), "db-test-write", false).Return(nil).Times(1)
+			},
+			expectedArchive: true,
+			expectedDeleted: true,
+		},
+		{
+			name:        "GivenBackupThenDeletePolicy_WhenBackupIsCompleted_ThenDropDatabase",
+			policy:      v1beta1.DeletionPolicyBackupThenDelete,
+			backupPhase: v1beta1.DatabaseBackupCompleted,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				expectDrop(stardogMocked)
+			},
+			expectedBackup:  true,
+			expectedDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			if tt.expectMocks != nil {
+				tt.expectMocks(stardogMocked)
+			}
+
+			db := createStardogDB("db-test", "", instanceRef)
+			db.Spec.DeletionPolicy = tt.policy
+			db.Annotations = tt.annotations
+			if tt.databaseName != "" {
+				db.Spec.DatabaseName = tt.databaseName
+			}
+			db.Spec.DeletionBackupLocation = "/backups"
+			db.Status.DatabaseName = "db-test"
+			db.Finalizers = []string{databaseFinalizer}
+			db.DeletionTimestamp = &metav1.Time{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			objects := []client.Object{db,
+				createStardogInstance(namespace, "instance-test", "secret-test", "http://url-test.ch"),
+				createFullSecret(namespace, "secret-test", "admin", "1234"),
+			}
+			objects = append(objects, tt.organizations...)
+			if tt.backupPhase != "" {
+				objects = append(objects, &v1beta1.DatabaseBackup{
+					ObjectMeta: metav1.ObjectMeta{Name: getDeletionBackupName(db)},
+					Spec:       v1beta1.DatabaseBackupSpec{DatabaseRef: "db-test", Location: "/backups"},
+					Status:     v1beta1.DatabaseBackupStatus{Phase: tt.backupPhase},
+				})
+			}
+			fakeKubeClient, err := createKubeFakeClientWithSub(objects...)
+			assert.NoError(t, err)
+			r := DatabaseReconciler{Log: testr.New(t), Scheme: scheme.Scheme, Client: fakeKubeClient}
+			dr := &DatabaseReconciliation{
+				resource: db,
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+			}
+
+			err = r.deleteDatabases(dr)
+
+			var pending *deletionPendingError
+			switch {
+			case tt.expectedErr != nil:
+				assert.ErrorIs(t, err, tt.expectedErr)
+			case tt.expectedErrMsg != "":
+				assert.EqualError(t, err, tt.expectedErrMsg)
+			case tt.expectedReason != "":
+				assert.ErrorAs(t, err, &pending)
+				assert.Equal(t, tt.expectedReason, pending.reason)
+			default:
+				assert.NoError(t, err)
+			}
+			backup := &v1beta1.DatabaseBackup{}
+			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: getDeletionBackupName(db)}, backup)
+			assert.Equal(t, tt.expectedBackup, err == nil)
+			archive := &v1beta1.ArchivedDatabase{}
+			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: "db-test"}, archive)
+			assert.Equal(t, tt.expectedArchive, err == nil)
+			if tt.expectedArchive {
+				assert.Equal(t, db.DeletionTimestamp.Add(7*24*time.Hour), archive.Spec.ExpirationTime.Time)
+			}
+			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: "db-test"}, &v1beta1.Database{})
+			assert.Equal(t, tt.expectedDeleted, apierrors.IsNotFound(err))
+		})
+	}
+}
+
+func Test_removeDatabase(t *testing.T) {
+	namespace := "namespace-test"
+	instanceRef := v1beta1.NewStardogInstanceRef("instance-test", namespace)
+	remainingInstanceRef := v1beta1.NewStardogInstanceRef("remaining-instance-test", namespace)
+	removalTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectDrop := func(stardogMocked *mock.MockStardogAPI) {
+		stardogMocked.EXPECT().DeleteUserRole(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(3)
+		stardogMocked.EXPECT().DeleteRole(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		stardogMocked.EXPECT().DeleteUser(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		stardogMocked.EXPECT().DropDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+	}
+
+	tests := []struct {
+		name            string
+		policy          v1beta1.DeletionPolicy
+		backupPhase     v1beta1.DatabaseBackupPhase
+		expectMocks     func(stardogMocked *mock.MockStardogAPI)
+		expectedReason  string
+		expectedBackup  bool
+		expectedArchive bool
+	}{
+		{
+			name:   "GivenRetainPolicy_WhenInstanceRemoved_ThenKeepDatabase",
+			policy: v1beta1.DeletionPolicyRetain,
+		},
+		{
+			name:   "GivenDeleteIfEmptyPolicy_WhenDatabaseIsEmpty_ThenDropDatabase",
+			policy: v1beta1.DeletionPolicyDeleteIfEmpty,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(int64(0), nil).Times(1)
+				expectDrop(stardogMocked)
+			},
+		},
+		{
+			name:   "GivenDeleteIfEmptyPolicy_WhenDatabaseIsNotEmpty_ThenKeepDatabase",
+			policy: v1beta1.DeletionPolicyDeleteIfEmpty,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(int64(42), nil).Times(1)
+			},
+			expectedReason: v1alpha1.ReasonNotEmpty,
+		},
+		{
+			name:   "GivenForcePolicy_WhenInstanceRemoved_ThenDropDatabase",
+			policy: v1beta1.DeletionPolicyForce,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				expectDrop(stardogMocked)
+			},
+		},
+		{
+			name:           "GivenBackupThenDeletePolicy_WhenNoBackupExists_ThenCreateBackupOfInstanceAndWait",
+			policy:         v1beta1.DeletionPolicyBackupThenDelete,
+			expectedReason: v1alpha1.ReasonBackupPending,
+			expectedBackup: true,
+		},
+		{
+			name:           "GivenBackupThenDeletePolicy_WhenBackupIsRunning_ThenWait",
+			policy:         v1beta1.DeletionPolicyBackupThenDelete,
+			backupPhase:    v1beta1.DatabaseBackupRunning,
+			expectedReason: v1alpha1.ReasonBackupPending,
+			expectedBackup: true,
+		},
+		{
+			name:        "GivenBackupThenDeletePolicy_WhenBackupIsCompleted_ThenDropDatabase",
+			policy:      v1beta1.DeletionPolicyBackupThenDelete,
+			backupPhase: v1beta1.DatabaseBackupCompleted,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				expectDrop(stardogMocked)
+			},
+			expectedBackup: true,
+		},
+		{
+			name:   "GivenArchivePolicy_WhenInstanceRemoved_ThenTakeDatabaseOfflineAndRecordArchive",
+			policy: v1beta1.DeletionPolicyArchive,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().OfflineDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+				stardogMocked.EXPECT().SetUserEnabled(gomock.Any(), "db-test-read", false).Return(nil).Times(1)
+				stardogMocked.EXPECT().SetUserEnabled(gomock.Any(), "db-test-write", false).Return(nil).Times(1)
+			},
+			expectedArchive: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return removalTime }
+			defer func() { now = time.Now }()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			if tt.expectMocks != nil {
+				tt.expectMocks(stardogMocked)
+			}
+
+			db := createStardogDB("db-test", "", remainingInstanceRef)
+			db.Spec.DeletionPolicy = tt.policy
+			db.Spec.DeletionBackupLocation = "/backups"
+			db.Status.DatabaseName = "db-test"
+			db.Status.StardogInstanceRefs = []v1beta1.StardogInstanceRef{remainingInstanceRef, instanceRef}
+			objects := []client.Object{db,
+				createStardogInstance(namespace, "instance-test", "secret-test", "http://url-test.ch"),
+				createFullSecret(namespace, "secret-test", "admin", "1234"),
+			}
+			if tt.backupPhase != "" {
+				objects = append(objects, &v1beta1.DatabaseBackup{
+					ObjectMeta: metav1.ObjectMeta{Name: getRemovalBackupName(db, instanceRef)},
+					Spec:       v1beta1.DatabaseBackupSpec{DatabaseRef: "db-test", Location: "/backups"},
+					Status:     v1beta1.DatabaseBackupStatus{Phase: tt.backupPhase},
+				})
+			}
+			fakeKubeClient, err := createKubeFakeClientWithSub(objects...)
+			assert.NoError(t, err)
+			r := DatabaseReconciler{Log: testr.New(t), Scheme: scheme.Scheme, Client: fakeKubeClient}
+			dr := &DatabaseReconciliation{
+				resource: db,
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+			}
+
+			err = r.removeDatabase(dr, instanceRef)
+
+			var pending *deletionPendingError
+			if tt.expectedReason != "" {
+				assert.ErrorAs(t, err, &pending)
+				assert.Equal(t, tt.expectedReason, pending.reason)
+			} else {
+				assert.NoError(t, err)
+			}
+			backup := &v1beta1.DatabaseBackup{}
+			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: getRemovalBackupName(db, instanceRef)}, backup)
+			assert.Equal(t, tt.expectedBackup, err == nil)
+			if tt.expectedBackup && tt.backupPhase == "" {
+				assert.Equal(t, []v1beta1.StardogInstanceRef{instanceRef}, backup.Spec.StardogInstanceRefs)
+			}
+			archive := &v1beta1.ArchivedDatabase{}
+			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: getRemovalArchiveName(db, instanceRef)}, archive)
+			assert.Equal(t, tt.expectedArchive, err == nil)
+			if tt.expectedArchive {
+				assert.Equal(t, []v1beta1.StardogInstanceRef{instanceRef}, archive.Spec.StardogInstanceRefs)
+				assert.True(t, removalTime.Add(7*24*time.Hour).Equal(archive.Spec.ExpirationTime.Time))
+			}
+		})
+	}
+}
+
+func Test_restoreArchivedDatabases(t *testing.T) {
+	namespace := "namespace-test"
+	instanceRef := v1beta1.NewStardogInstanceRef("instance-test", namespace)
+	otherInstanceRef := v1beta1.NewStardogInstanceRef("other-instance-test", namespace)
+
+	tests := []struct {
+		name              string
+		archivedInstances []v1beta1.StardogInstanceRef
+		archivedDatabase  string
+		expectMocks       func(stardogMocked *mock.MockStardogAPI)
+		expectedRemaining []v1beta1.StardogInstanceRef
+	}{
+		{
+			name:              "GivenArchivedDatabase_WhenRecreated_ThenBringOnlineAndDeleteArchive",
+			archivedInstances: []v1beta1.StardogInstanceRef{instanceRef},
+			archivedDatabase:  "db-test",
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().OnlineDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+				stardogMocked.EXPECT().SetUserEnabled(gomock.Any(), "db-test-read", true).Return(nil).Times(1)
+				stardogMocked.EXPECT().SetUserEnabled(gomock.Any(), "db-test-write", true).Return(nil).Times(1)
+				stardogMocked.EXPECT().SetUserEnabled(gomock.Any(), "hidden-user", true).Return(nil).Times(1)
+			},
+		},
+		{
+			name:              "GivenArchivedDatabaseOnOtherInstance_WhenRecreated_ThenKeepArchiveOfOtherInstance",
+			archivedInstances: []v1beta1.StardogInstanceRef{instanceRef, otherInstanceRef},
+			archivedDatabase:  "db-test",
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().OnlineDatabase(gomock.Any(), "db-test").Return(nil).Times(1)
+				stardogMocked.EXPECT().SetUserEnabled(gomock.Any(), gomock.Any(), true).Return(nil).Times(3)
+			},
+			expectedRemaining: []v1beta1.StardogInstanceRef{otherInstanceRef},
+		},
+		{
+			name:              "GivenArchiveOfOtherDatabase_WhenCreated_ThenKeepArchive",
+			archivedInstances: []v1beta1.StardogInstanceRef{instanceRef},
+			archivedDatabase:  "other-db",
+			expectedRemaining: []v1beta1.StardogInstanceRef{instanceRef},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			if tt.expectMocks != nil {
+				tt.expectMocks(stardogMocked)
+			}
+
+			archive := &v1beta1.ArchivedDatabase{
+				ObjectMeta: metav1.ObjectMeta{Name: "archive-test"},
+				Spec: v1beta1.ArchivedDatabaseSpec{
+					DatabaseName:              tt.archivedDatabase,
+					AddUserForNonHiddenGraphs: "hidden-user",
+					StardogInstanceRefs:       tt.archivedInstances,
+				},
+			}
+			fakeKubeClient, err := createKubeFakeClientWithSub(archive,
+				createStardogInstance(namespace, "instance-test", "secret-test", "http://url-test.ch"),
+				createFullSecret(namespace, "secret-test", "admin", "1234"),
+			)
+			assert.NoError(t, err)
+			r := DatabaseReconciler{Log: testr.New(t), Scheme: scheme.Scheme, Client: fakeKubeClient}
+			dr := &DatabaseReconciliation{
+				resource: createStardogDB("db-test", "", instanceRef),
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+			}
+
+			err = r.restoreArchivedDatabases(dr)
+
+			assert.NoError(t, err)
+			actual := &v1beta1.ArchivedDatabase{}
+			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: "archive-test"}, actual)
+			if tt.expectedRemaining == nil {
+				assert.True(t, apierrors.IsNotFound(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedRemaining, actual.Spec.StardogInstanceRefs)
+			}
+		})
+	}
+}
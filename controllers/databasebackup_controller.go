@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/go-logr/logr"
+	stardogv1alpha1 "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	stardogv1beta1 "github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	scheme "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+// DatabaseBackupReconciler reconciles a DatabaseBackup object
+type DatabaseBackupReconciler struct {
+	client.Client
+	Log            logr.Logger
+	Scheme         *scheme.Scheme
+	StardogClients *StardogClientPool
+}
+
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databasebackups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databasebackups/status,verbs=get;update;patch
+
+// Reconcile backs up the Database of a DatabaseBackup on all its Stardog instances. The backup requests are sent
+// synchronously and block the worker until Stardog has finished them, so up to MaxConcurrentBackups backups are
+// reconciled in parallel.
+func (r *DatabaseBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	backup := &stardogv1beta1.DatabaseBackup{}
+	err := r.Get(ctx, req.NamespacedName, backup)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Log.Info("DatabaseBackup not found, ignoring reconcile.")
+			return ctrl.Result{Requeue: false}, nil
+		}
+		r.Log.Error(err, "Could not retrieve DatabaseBackup.")
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, err
+	}
+
+	br := &DatabaseBackupReconciliation{
+		reconciliationContext: &ReconciliationContext{
+			context:        ctx,
+			conditions:     make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
+			stardogClients: r.StardogClients,
+		},
+		resource: backup,
+	}
+
+	return r.reconcileDatabaseBackup(br)
+}
+
+func (r *DatabaseBackupReconciler) reconcileDatabaseBackup(br *DatabaseBackupReconciliation) (ctrl.Result, error) {
+	rc := br.reconciliationContext
+	backup := br.resource
+
+	r.Log.Info("reconciling", getLoggingKeysAndValuesForDatabaseBackup(backup)...)
+
+	if isBackupFinished(backup) || backup.GetDeletionTimestamp() != nil {
+		return ctrl.Result{Requeue: false}, nil
+	}
+
+	if err := r.validateSpecification(br); err != nil {
+		r.Log.Error(err, "Specification cannot be validated")
+		rc.SetStatusCondition(createStatusConditionInvalid(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Specification cannot be validated"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(br)
+	}
+	rc.SetStatusIfExisting(stardogv1alpha1.StardogInvalid, v1.ConditionFalse)
+
+	if err := r.backup(br); err != nil {
+		r.Log.Error(err, "Backup failed")
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Backup failed"))
+		if backup.Status.Phase == stardogv1beta1.DatabaseBackupFailed {
+			return ctrl.Result{Requeue: false}, r.updateStatus(br)
+		}
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(br)
+	}
+	rc.SetStatusIfExisting(stardogv1alpha1.StardogErrored, v1.ConditionFalse)
+
+	if backup.Status.Phase != stardogv1beta1.DatabaseBackupCompleted {
+		r.Log.Info("waiting for disabled instances to be backed up", "instances", backup.Status.SkippedInstances)
+		rc.SetStatusCondition(createStatusConditionReady(false, "Waiting for disabled instances"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(br)
+	}
+
+	rc.SetStatusCondition(createStatusConditionReady(true, "Backed up"))
+	return ctrl.Result{Requeue: false}, r.updateStatus(br)
+}
+
+func (r *DatabaseBackupReconciler) updateStatus(br *DatabaseBackupReconciliation) error {
+	res := br.resource
+	status := res.Status
+	status.Conditions = mergeWithExistingConditions(status.Conditions, br.reconciliationContext.conditions)
+	res.Status = status
+
+	err := r.Client.Status().Update(br.reconciliationContext.context, res)
+	if err != nil {
+		r.Log.Error(err, "could not update DatabaseBackup", getLoggingKeysAndValuesForDatabaseBackup(res)...)
+		return err
+	}
+	r.Log.Info("updated DatabaseBackup status", getLoggingKeysAndValuesForDatabaseBackup(res)...)
+	return nil
+}
+
+// validateSpecification checks the spec and retrieves the backed up Database, which has to be synchronized already
+func (r *DatabaseBackupReconciler) validateSpecification(br *DatabaseBackupReconciliation) error {
+	r.Log.V(1).Info("validating DatabaseBackupSpec")
+	spec := br.resource.Spec
+
+	if spec.DatabaseRef == "" {
+		return fmt.Errorf(".spec.DatabaseRef is required")
+	}
+	if spec.Location == "" {
+		return fmt.Errorf(".spec.Location is required")
+	}
+
+	br.database = &stardogv1beta1.Database{}
+	err := r.Get(br.reconciliationContext.context, types.NamespacedName{Name: spec.DatabaseRef}, br.database)
+	if err != nil {
+		return fmt.Errorf("cannot get database %s: %v", spec.DatabaseRef, err)
+	}
+	if br.database.Status.DatabaseName == "" {
+		return fmt.Errorf("database %s has not been synchronized yet", spec.DatabaseRef)
+	}
+	return nil
+}
+
+// backup backs up the database on every requested instance which has not been backed up yet and records each backup in the
+// status. The backup is completed once all instances have been backed up, disabled instances are recorded as skipped
+// until they are enabled again. If a Stardog server rejects the backup, the backup is marked as failed.
+func (r *DatabaseBackupReconciler) backup(br *DatabaseBackupReconciliation) error {
+	ctx := br.reconciliationContext.context
+	backup := br.resource
+	status := &backup.Status
+	dbName := br.database.Status.DatabaseName
+
+	if status.Phase == "" {
+		startTime := metav1.NewTime(now())
+		status.Phase = stardogv1beta1.DatabaseBackupRunning
+		status.StartTime = &startTime
+		status.DatabaseName = dbName
+	}
+
+	instances := backup.Spec.StardogInstanceRefs
+	if len(instances) == 0 {
+		instances = br.database.Spec.StardogInstanceRefs
+	}
+	status.SkippedInstances = nil
+	for _, instance := range instances {
+		if containsBackupOfInstance(status.Instances, instance) {
+			continue
+		}
+		stardogClient, disabled, err := br.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+		if err != nil {
+			return fmt.Errorf("cannot initialize stardog client: %v", err)
+		}
+		if disabled {
+			r.Log.Info("skipping resource from reconciliation", "instance", instance.Name, "resource", backup.Name)
+			status.SkippedInstances = append(status.SkippedInstances, instance)
+			continue
+		}
+
+		// All backups of a location share one directory per instance, so that Stardog rotates them
+		location := path.Join(backup.Spec.Location, instance.Namespace, instance.Name)
+		started := now()
+		err = stardogClient.BackupDatabase(ctx, dbName, location)
+		if err != nil {
+			if isPermanentBackupError(err) {
+				completionTime := metav1.NewTime(now())
+				status.Phase = stardogv1beta1.DatabaseBackupFailed
+				status.CompletionTime = &completionTime
+			}
+			return fmt.Errorf("cannot back up database %s on instance %s/%s: %v", dbName, instance.Namespace, instance.Name, err)
+		}
+		completed := now()
+
+		instanceStatus := stardogv1beta1.DatabaseBackupInstanceStatus{
+			StardogInstanceRef: instance,
+			Location:           path.Join(location, dbName),
+			Duration:           metav1.Duration{Duration: completed.Sub(started).Round(time.Millisecond)},
+			CompletionTime:     metav1.NewTime(completed),
+		}
+		// The backup exists at this point, the number of triples is informational only
+		triples, err := stardogClient.GetDatabaseSize(ctx, dbName)
+		if err != nil {
+			r.Log.Error(err, "cannot get number of triples of backed up database", "name", dbName, "instance", instance.Name)
+		} else {
+			instanceStatus.Triples = &triples
+		}
+		status.Instances = append(status.Instances, instanceStatus)
+		r.Log.Info("backed up Stardog database", "name", dbName, "instance", instance.Name, "location", location)
+	}
+
+	if len(status.SkippedInstances) > 0 {
+		return nil
+	}
+	completionTime := metav1.NewTime(now())
+	status.Phase = stardogv1beta1.DatabaseBackupCompleted
+	status.CompletionTime = &completionTime
+	return nil
+}
+
+// isPermanentBackupError returns true if the Stardog server rejected the backup request, so that retrying it cannot
+// succeed, e.g. because the database does not exist or the location is invalid
+func isPermanentBackupError(err error) bool {
+	var apiErr *stardogapi.Error
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusNotFound)
+}
+
+// isBackupFinished returns true if the backup has completed or failed and will not be changed anymore
+func isBackupFinished(backup *stardogv1beta1.DatabaseBackup) bool {
+	return backup.Status.Phase == stardogv1beta1.DatabaseBackupCompleted || backup.Status.Phase == stardogv1beta1.DatabaseBackupFailed
+}
+
+func containsBackupOfInstance(backups []stardogv1beta1.DatabaseBackupInstanceStatus, instance stardogv1beta1.StardogInstanceRef) bool {
+	for _, backup := range backups {
+		if backup.StardogInstanceRef == instance {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DatabaseBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&stardogv1beta1.DatabaseBackup{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: MaxConcurrentBackups}).
+		Complete(r)
+}
+
+func getLoggingKeysAndValuesForDatabaseBackup(backup *stardogv1beta1.DatabaseBackup) []interface{} {
+	return []interface{}{
+		"DatabaseBackup", backup.Name,
+	}
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func Test_reconcileDatabaseBackup(t *testing.T) {
+	namespace := "namespace-test"
+	secretName := "secret-test"
+	instanceRef := v1beta1.NewStardogInstanceRef("instance-test", namespace)
+	otherInstanceRef := v1beta1.NewStardogInstanceRef("other-instance-test", namespace)
+	disabledInstanceRef := v1beta1.NewStardogInstanceRef("disabled-instance-test", namespace)
+	triples := int64(42)
+
+	tests := []struct {
+		name              string
+		unsynchronized    bool
+		disabledInstance  bool
+		instances         []v1beta1.DatabaseBackupInstanceStatus
+		expectMocks       func(stardogMocked *mock.MockStardogAPI)
+		expectedPhase     v1beta1.DatabaseBackupPhase
+		expectedLocations []string
+		expectedTriples   []*int64
+		expectedSkipped   []v1beta1.StardogInstanceRef
+		expectedRequeue   bool
+		expectedInvalid   bool
+	}{
+		{
+			name: "GivenNewBackup_WhenReconciling_ThenBackupOnAllInstances",
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().BackupDatabase(gomock.Any(), "db-test", "/backup/namespace-test/instance-test").Return(nil).Times(1)
+				stardogMocked.EXPECT().BackupDatabase(gomock.Any(), "db-test", "/backup/namespace-test/other-instance-test").Return(nil).Times(1)
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(triples, nil).Times(2)
+			},
+			expectedPhase: v1beta1.DatabaseBackupCompleted,
+			expectedLocations: []string{
+				"/backup/namespace-test/instance-test/db-test",
+				"/backup/namespace-test/other-instance-test/db-test",
+			},
+			expectedTriples: []*int64{&triples, &triples},
+		},
+		{
+			name:      "GivenBackupOnFirstInstance_WhenReconcilingAgain_ThenOnlyBackupRemainingInstance",
+			instances: []v1beta1.DatabaseBackupInstanceStatus{{StardogInstanceRef: instanceRef, Location: "/backup/namespace-test/instance-test/db-test", Triples: &triples}},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().BackupDatabase(gomock.Any(), "db-test", "/backup/namespace-test/other-instance-test").Return(nil).Times(1)
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(triples, nil).Times(1)
+			},
+			expectedPhase: v1beta1.DatabaseBackupCompleted,
+			expectedLocations: []string{
+				"/backup/namespace-test/instance-test/db-test",
+				"/backup/namespace-test/other-instance-test/db-test",
+			},
+			expectedTriples: []*int64{&triples, &triples},
+		},
+		{
+			name: "GivenFailingTripleCount_WhenBackedUp_ThenRecordBackupWithoutTriples",
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().BackupDatabase(gomock.Any(), "db-test", gomock.Any()).Return(nil).Times(2)
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(int64(0), errors.New("timeout")).Times(2)
+			},
+			expectedPhase: v1beta1.DatabaseBackupCompleted,
+			expectedLocations: []string{
+				"/backup/namespace-test/instance-test/db-test",
+				"/backup/namespace-test/other-instance-test/db-test",
+			},
+			expectedTriples: []*int64{nil, nil},
+		},
+		{
+			name: "GivenFailingBackup_WhenReconciling_ThenKeepRunning",
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().BackupDatabase(gomock.Any(), "db-test", gomock.Any()).Return(errors.New("disk full")).Times(1)
+			},
+			expectedPhase:   v1beta1.DatabaseBackupRunning,
+			expectedRequeue: true,
+		},
+		{
+			name: "GivenRejectedBackup_WhenReconciling_ThenFail",
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().BackupDatabase(gomock.Any(), "db-test", gomock.Any()).
+					Return(&stardogapi.Error{StatusCode: http.StatusNotFound, Message: "Database does not exist"}).Times(1)
+			},
+			expectedPhase:   v1beta1.DatabaseBackupFailed,
+			expectedRequeue: false,
+		},
+		{
+			name:             "GivenDisabledInstance_WhenReconciling_ThenWaitForDisabledInstance",
+			disabledInstance: true,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().BackupDatabase(gomock.Any(), "db-test", gomock.Any()).Return(nil).Times(2)
+				stardogMocked.EXPECT().GetDatabaseSize(gomock.Any(), "db-test").Return(triples, nil).Times(2)
+			},
+			expectedPhase: v1beta1.DatabaseBackupRunning,
+			expectedLocations: []string{
+				"/backup/namespace-test/instance-test/db-test",
+				"/backup/namespace-test/other-instance-test/db-test",
+			},
+			expectedTriples: []*int64{&triples, &triples},
+			expectedSkipped: []v1beta1.StardogInstanceRef{disabledInstanceRef},
+			expectedRequeue: true,
+		},
+		{
+			name:            "GivenUnsynchronizedDatabase_WhenReconciling_ThenInvalid",
+			unsynchronized:  true,
+			expectMocks:     func(_ *mock.MockStardogAPI) {},
+			expectedRequeue: true,
+			expectedInvalid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			tt.expectMocks(stardogMocked)
+
+			database := createStardogDB("db-test", "", instanceRef)
+			database.Spec.StardogInstanceRefs = append(database.Spec.StardogInstanceRefs, otherInstanceRef)
+			if !tt.unsynchronized {
+				database.Status.DatabaseName = "db-test"
+			}
+			if tt.disabledInstance {
+				database.Spec.StardogInstanceRefs = append(database.Spec.StardogInstanceRefs, disabledInstanceRef)
+			}
+			backup := &v1beta1.DatabaseBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup-test"},
+				Spec:       v1beta1.DatabaseBackupSpec{DatabaseRef: "db-test", Location: "/backup"},
+				Status:     v1beta1.DatabaseBackupStatus{Instances: tt.instances},
+			}
+			if len(tt.instances) > 0 {
+				backup.Status.Phase = v1beta1.DatabaseBackupRunning
+			}
+			disabledInstance := createStardogInstanceWithFinalizers(namespace, disabledInstanceRef.Name, secretName, "http://disabled-url-test.ch")
+			disabledInstance.Spec.Disabled = true
+			fakeKubeClient, err := createKubeFakeClientWithSub(
+				backup,
+				database,
+				createStardogInstanceWithFinalizers(namespace, instanceRef.Name, secretName, "http://url-test.ch"),
+				createStardogInstanceWithFinalizers(namespace, otherInstanceRef.Name, secretName, "http://other-url-test.ch"),
+				disabledInstance,
+				createFullSecret(namespace, secretName, "admin", "1234"),
+			)
+			assert.NoError(t, err)
+			r := DatabaseBackupReconciler{
+				Client: fakeKubeClient,
+				Log:    testr.New(t),
+				Scheme: scheme.Scheme,
+			}
+			br := &DatabaseBackupReconciliation{
+				resource: backup,
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+			}
+
+			result, err := r.reconcileDatabaseBackup(br)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRequeue, result.Requeue)
+			assert.Equal(t, tt.expectedPhase, backup.Status.Phase)
+			var locations []string
+			var triples []*int64
+			for _, instance := range backup.Status.Instances {
+				locations = append(locations, instance.Location)
+				triples = append(triples, instance.Triples)
+			}
+			assert.Equal(t, tt.expectedLocations, locations)
+			assert.Equal(t, tt.expectedTriples, triples)
+			assert.Equal(t, tt.expectedSkipped, backup.Status.SkippedInstances)
+			assert.Equal(t, tt.expectedPhase == v1beta1.DatabaseBackupCompleted || tt.expectedPhase == v1beta1.DatabaseBackupFailed,
+				backup.Status.CompletionTime != nil)
+			assert.Equal(t, tt.expectedInvalid, isConditionTrue(backup.Status.Conditions, v1alpha1.StardogInvalid))
+		})
+	}
+}
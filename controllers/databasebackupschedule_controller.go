@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron/v3"
+	stardogv1alpha1 "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	stardogv1beta1 "github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	scheme "k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// DatabaseBackupScheduleReconciler reconciles a DatabaseBackupSchedule object
+type DatabaseBackupScheduleReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *scheme.Scheme
+}
+
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databasebackupschedules,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databasebackupschedules/status,verbs=get;update;patch
+
+// Reconcile creates the DatabaseBackups of a DatabaseBackupSchedule when they are due and prunes the old ones
+func (r *DatabaseBackupScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	schedule := &stardogv1beta1.DatabaseBackupSchedule{}
+	err := r.Get(ctx, req.NamespacedName, schedule)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Log.Info("DatabaseBackupSchedule not found, ignoring reconcile.")
+			return ctrl.Result{Requeue: false}, nil
+		}
+		r.Log.Error(err, "Could not retrieve DatabaseBackupSchedule.")
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, err
+	}
+
+	sr := &DatabaseBackupScheduleReconciliation{
+		reconciliationContext: &ReconciliationContext{
+			context:    ctx,
+			conditions: make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
+		},
+		resource: schedule,
+	}
+
+	return r.reconcileDatabaseBackupSchedule(sr)
+}
+
+func (r *DatabaseBackupScheduleReconciler) reconcileDatabaseBackupSchedule(sr *DatabaseBackupScheduleReconciliation) (ctrl.Result, error) {
+	rc := sr.reconciliationContext
+	schedule := sr.resource
+
+	r.Log.Info("reconciling", getLoggingKeysAndValuesForDatabaseBackupSchedule(schedule)...)
+
+	if schedule.GetDeletionTimestamp() != nil {
+		return ctrl.Result{Requeue: false}, nil
+	}
+
+	cronSchedule, err := cron.ParseStandard(schedule.Spec.Schedule)
+	if err == nil && schedule.Spec.DatabaseRef == "" {
+		err = fmt.Errorf(".spec.DatabaseRef is required")
+	}
+	if err != nil {
+		r.Log.Error(err, "Specification cannot be validated")
+		rc.SetStatusCondition(createStatusConditionInvalid(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Specification cannot be validated"))
+		return ctrl.Result{Requeue: false}, r.updateStatus(sr)
+	}
+	rc.SetStatusIfExisting(stardogv1alpha1.StardogInvalid, v1.ConditionFalse)
+
+	if err := r.schedule(sr, cronSchedule); err != nil {
+		r.Log.Error(err, "Scheduling failed")
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Scheduling failed"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(sr)
+	}
+
+	if err := r.prune(sr); err != nil {
+		r.Log.Error(err, "Pruning failed")
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Pruning failed"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(sr)
+	}
+	rc.SetStatusIfExisting(stardogv1alpha1.StardogErrored, v1.ConditionFalse)
+
+	rc.SetStatusCondition(createStatusConditionReady(true, "Scheduled"))
+	result := ctrl.Result{Requeue: false}
+	if next := schedule.Status.NextScheduleTime; next != nil && !schedule.Spec.Suspend {
+		result = ctrl.Result{Requeue: true, RequeueAfter: next.Sub(now())}
+	}
+	return result, r.updateStatus(sr)
+}
+
+func (r *DatabaseBackupScheduleReconciler) updateStatus(sr *DatabaseBackupScheduleReconciliation) error {
+	res := sr.resource
+	status := res.Status
+	status.Conditions = mergeWithExistingConditions(status.Conditions, sr.reconciliationContext.conditions)
+	res.Status = status
+
+	err := r.Client.Status().Update(sr.reconciliationContext.context, res)
+	if err != nil {
+		r.Log.Error(err, "could not update DatabaseBackupSchedule", getLoggingKeysAndValuesForDatabaseBackupSchedule(res)...)
+		return err
+	}
+	r.Log.Info("updated DatabaseBackupSchedule status", getLoggingKeysAndValuesForDatabaseBackupSchedule(res)...)
+	return nil
+}
+
+// schedule creates a DatabaseBackup if the schedule is due. Missed schedules, e.g. while the operator was down, are
+// caught up by a single DatabaseBackup.
+func (r *DatabaseBackupScheduleReconciler) schedule(sr *DatabaseBackupScheduleReconciliation, cronSchedule cron.Schedule) error {
+	schedule := sr.resource
+	status := &schedule.Status
+	currentTime := now().UTC()
+
+	last := schedule.CreationTimestamp.Time
+	if status.LastScheduleTime != nil {
+		last = status.LastScheduleTime.Time
+	}
+	due := cronSchedule.Next(last.UTC())
+
+	if !schedule.Spec.Suspend && !due.IsZero() && !due.After(currentTime) {
+		backup := &stardogv1beta1.DatabaseBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("%s-%d", schedule.Name, due.Unix()),
+				Labels: map[string]string{stardogv1beta1.DatabaseBackupScheduleLabel: schedule.Name},
+			},
+			Spec: stardogv1beta1.DatabaseBackupSpec{
+				DatabaseRef: schedule.Spec.DatabaseRef,
+				Location:    schedule.Spec.Location,
+			},
+		}
+		if err := controllerutil.SetControllerReference(schedule, backup, r.Scheme); err != nil {
+			return fmt.Errorf("cannot set owner of backup %s: %v", backup.Name, err)
+		}
+		err := r.Create(sr.reconciliationContext.context, backup)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("cannot create backup %s: %v", backup.Name, err)
+		}
+		r.Log.Info("created DatabaseBackup", "name", backup.Name, "schedule", schedule.Name)
+		lastScheduleTime := metav1.NewTime(due)
+		status.LastScheduleTime = &lastScheduleTime
+		due = cronSchedule.Next(currentTime)
+	}
+
+	status.NextScheduleTime = nil
+	if !due.IsZero() {
+		nextScheduleTime := metav1.NewTime(due)
+		status.NextScheduleTime = &nextScheduleTime
+	}
+	return nil
+}
+
+// prune deletes the completed and failed DatabaseBackups of the schedule which exceed the retention. Running backups
+// and the newest completed backup are never deleted. The backup files on the Stardog servers are rotated by Stardog.
+func (r *DatabaseBackupScheduleReconciler) prune(sr *DatabaseBackupScheduleReconciliation) error {
+	ctx := sr.reconciliationContext.context
+	schedule := sr.resource
+	retention := schedule.Spec.Retention
+
+	backups := &stardogv1beta1.DatabaseBackupList{}
+	err := r.List(ctx, backups, client.MatchingLabels{stardogv1beta1.DatabaseBackupScheduleLabel: schedule.Name})
+	if err != nil {
+		return fmt.Errorf("cannot get backup list: %v", err)
+	}
+	items := backups.Items
+	// Newest first, the names contain the schedule time
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreationTimestamp.Equal(&items[j].CreationTimestamp) {
+			return items[j].CreationTimestamp.Before(&items[i].CreationTimestamp)
+		}
+		return items[i].Name > items[j].Name
+	})
+
+	var kept []string
+	finished := int32(0)
+	schedule.Status.LastSuccessfulTime = nil
+	for i := range items {
+		backup := &items[i]
+		if backup.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if !isBackupFinished(backup) || backup.Status.CompletionTime == nil {
+			kept = append(kept, backup.Name)
+			continue
+		}
+
+		finished++
+		newestCompleted := schedule.Status.LastSuccessfulTime == nil && backup.Status.Phase == stardogv1beta1.DatabaseBackupCompleted
+		if newestCompleted {
+			schedule.Status.LastSuccessfulTime = backup.Status.CompletionTime.DeepCopy()
+		} else if exceedsRetention(retention, finished, backup.Status.CompletionTime.Time) {
+			if err := r.Delete(ctx, backup); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("cannot delete backup %s: %v", backup.Name, err)
+			}
+			r.Log.Info("pruned DatabaseBackup", "name", backup.Name, "schedule", schedule.Name)
+			continue
+		}
+		kept = append(kept, backup.Name)
+	}
+
+	// Oldest first
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	schedule.Status.Backups = kept
+	return nil
+}
+
+// exceedsRetention returns true if the completed or failed backup with the given position, counting from the newest
+// one, and completion time is not kept anymore
+func exceedsRetention(retention stardogv1beta1.DatabaseBackupRetention, position int32, completionTime time.Time) bool {
+	if retention.KeepLast > 0 && position > retention.KeepLast {
+		return true
+	}
+	return retention.MaxAge != nil && now().Sub(completionTime) > retention.MaxAge.Duration
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DatabaseBackupScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&stardogv1beta1.DatabaseBackupSchedule{}).
+		Owns(&stardogv1beta1.DatabaseBackup{}).
+		Complete(r)
+}
+
+func getLoggingKeysAndValuesForDatabaseBackupSchedule(schedule *stardogv1beta1.DatabaseBackupSchedule) []interface{} {
+	return []interface{}{
+		"DatabaseBackupSchedule", schedule.Name,
+	}
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_reconcileDatabaseBackupSchedule(t *testing.T) {
+	created := time.Date(2024, time.January, 17, 1, 30, 0, 0, time.UTC)
+	twoAM := time.Date(2024, time.January, 17, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		now              time.Time
+		suspend          bool
+		retention        v1beta1.DatabaseBackupRetention
+		objects          []client.Object
+		expectedBackups  []string
+		expectedNext     time.Time
+		expectedLastTime *time.Time
+	}{
+		{
+			name:            "GivenScheduleNotDue_WhenReconciling_ThenCreateNoBackup",
+			now:             created.Add(15 * time.Minute),
+			expectedNext:    twoAM,
+			expectedBackups: nil,
+		},
+		{
+			name:             "GivenScheduleDue_WhenReconciling_ThenCreateBackup",
+			now:              twoAM.Add(30 * time.Second),
+			expectedNext:     twoAM.AddDate(0, 0, 1),
+			expectedBackups:  []string{fmt.Sprintf("schedule-test-%d", twoAM.Unix())},
+			expectedLastTime: &twoAM,
+		},
+		{
+			name:            "GivenMissedSchedules_WhenReconciling_ThenCreateSingleBackup",
+			now:             twoAM.AddDate(0, 0, 3).Add(time.Hour),
+			expectedNext:    twoAM.AddDate(0, 0, 4),
+			expectedBackups: []string{fmt.Sprintf("schedule-test-%d", twoAM.Unix())},
+		},
+		{
+			name:            "GivenSuspendedSchedule_WhenDue_ThenCreateNoBackup",
+			now:             twoAM.Add(30 * time.Second),
+			suspend:         true,
+			expectedNext:    twoAM,
+			expectedBackups: nil,
+		},
+		{
+			name:      "GivenKeepLast_WhenReconciling_ThenPruneOldestCompletedBackups",
+			now:       created.Add(15 * time.Minute),
+			retention: v1beta1.DatabaseBackupRetention{KeepLast: 2},
+			objects: []client.Object{
+				createScheduledBackup("schedule-test-1", v1beta1.DatabaseBackupCompleted, created.AddDate(0, 0, -4)),
+				createScheduledBackup("schedule-test-2", v1beta1.DatabaseBackupCompleted, created.AddDate(0, 0, -3)),
+				createScheduledBackup("schedule-test-3", v1beta1.DatabaseBackupCompleted, created.AddDate(0, 0, -2)),
+				createScheduledBackup("schedule-test-4", v1beta1.DatabaseBackupCompleted, created.AddDate(0, 0, -1)),
+				createScheduledBackup("schedule-test-5", v1beta1.DatabaseBackupRunning, time.Time{}),
+			},
+			expectedNext:    twoAM,
+			expectedBackups: []string{"schedule-test-3", "schedule-test-4", "schedule-test-5"},
+		},
+		{
+			name:      "GivenFailedBackups_WhenReconciling_ThenPruneThemButKeepNewestCompletedBackup",
+			now:       created.Add(15 * time.Minute),
+			retention: v1beta1.DatabaseBackupRetention{KeepLast: 2},
+			objects: []client.Object{
+				createScheduledBackup("schedule-test-1", v1beta1.DatabaseBackupCompleted, created.AddDate(0, 0, -4)),
+				createScheduledBackup("schedule-test-2", v1beta1.DatabaseBackupFailed, created.AddDate(0, 0, -3)),
+				createScheduledBackup("schedule-test-3", v1beta1.DatabaseBackupFailed, created.AddDate(0, 0, -2)),
+				createScheduledBackup("schedule-test-4", v1beta1.DatabaseBackupFailed, created.AddDate(0, 0, -1)),
+			},
+			expectedNext:    twoAM,
+			expectedBackups: []string{"schedule-test-1", "schedule-test-3", "schedule-test-4"},
+		},
+		{
+			name:      "GivenMaxAge_WhenReconciling_ThenPruneExpiredButKeepNewestBackup",
+			now:       created.Add(15 * time.Minute),
+			retention: v1beta1.DatabaseBackupRetention{MaxAge: &metav1.Duration{Duration: 36 * time.Hour}},
+			objects: []client.Object{
+				createScheduledBackup("schedule-test-1", v1beta1.DatabaseBackupCompleted, created.AddDate(0, 0, -4)),
+				createScheduledBackup("schedule-test-2", v1beta1.DatabaseBackupCompleted, created.AddDate(0, 0, -3)),
+			},
+			expectedNext:    twoAM,
+			expectedBackups: []string{"schedule-test-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return tt.now }
+			defer func() { now = time.Now }()
+
+			schedule := &v1beta1.DatabaseBackupSchedule{
+				ObjectMeta: metav1.ObjectMeta{Name: "schedule-test", CreationTimestamp: metav1.NewTime(created)},
+				Spec: v1beta1.DatabaseBackupScheduleSpec{
+					DatabaseRef: "db-test",
+					Schedule:    "0 2 * * *",
+					Location:    "/backup",
+					Suspend:     tt.suspend,
+					Retention:   tt.retention,
+				},
+			}
+			fakeKubeClient, err := createKubeFakeClientWithSub(append(tt.objects, schedule)...)
+			assert.NoError(t, err)
+			r := DatabaseBackupScheduleReconciler{
+				Client: fakeKubeClient,
+				Log:    testr.New(t),
+				Scheme: scheme.Scheme,
+			}
+			sr := &DatabaseBackupScheduleReconciliation{
+				resource: schedule,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+				},
+			}
+
+			_, err = r.reconcileDatabaseBackupSchedule(sr)
+
+			assert.NoError(t, err)
+			assert.True(t, isConditionTrue(schedule.Status.Conditions, v1alpha1.StardogReady))
+			assert.Equal(t, tt.expectedNext, schedule.Status.NextScheduleTime.Time.UTC())
+			if tt.expectedLastTime != nil {
+				assert.Equal(t, *tt.expectedLastTime, schedule.Status.LastScheduleTime.Time.UTC())
+			}
+			backups := &v1beta1.DatabaseBackupList{}
+			assert.NoError(t, fakeKubeClient.List(context.Background(), backups))
+			var names []string
+			for _, backup := range backups.Items {
+				names = append(names, backup.Name)
+				assert.Equal(t, "db-test", backup.Spec.DatabaseRef)
+			}
+			assert.ElementsMatch(t, tt.expectedBackups, names)
+			if len(tt.objects) > 0 {
+				assert.Equal(t, tt.expectedBackups, schedule.Status.Backups)
+			}
+		})
+	}
+}
+
+func createScheduledBackup(name string, phase v1beta1.DatabaseBackupPhase, completionTime time.Time) *v1beta1.DatabaseBackup {
+	backup := &v1beta1.DatabaseBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{v1beta1.DatabaseBackupScheduleLabel: "schedule-test"},
+		},
+		Spec:   v1beta1.DatabaseBackupSpec{DatabaseRef: "db-test", Location: "/backup"},
+		Status: v1beta1.DatabaseBackupStatus{Phase: phase},
+	}
+	if !completionTime.IsZero() {
+		backup.Status.CompletionTime = &metav1.Time{Time: completionTime}
+	}
+	return backup
+}
@@ -0,0 +1,399 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/go-logr/logr"
+	stardogv1alpha1 "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	stardogv1beta1 "github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	scheme "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/strings/slices"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DatabaseMigrationReconciler reconciles a DatabaseMigration object
+type DatabaseMigrationReconciler struct {
+	client.Client
+	Log            logr.Logger
+	Scheme         *scheme.Scheme
+	StardogClients *StardogClientPool
+}
+
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databasemigrations,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databasemigrations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databases,verbs=create;delete
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=organizations,verbs=get;list;watch;update;patch
+
+// Reconcile migrates the source Database of a DatabaseMigration step by step to its target
+func (r *DatabaseMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	migration := &stardogv1beta1.DatabaseMigration{}
+	err := r.Get(ctx, req.NamespacedName, migration)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Log.Info("DatabaseMigration not found, ignoring reconcile.")
+			return ctrl.Result{Requeue: false}, nil
+		}
+		r.Log.Error(err, "Could not retrieve DatabaseMigration.")
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, err
+	}
+
+	mr := &DatabaseMigrationReconciliation{
+		reconciliationContext: &ReconciliationContext{
+			context:        ctx,
+			conditions:     make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
+			stardogClients: r.StardogClients,
+		},
+		resource: migration,
+	}
+
+	return r.reconcileDatabaseMigration(mr)
+}
+
+func (r *DatabaseMigrationReconciler) reconcileDatabaseMigration(mr *DatabaseMigrationReconciliation) (ctrl.Result, error) {
+	rc := mr.reconciliationContext
+	migration := mr.resource
+
+	r.Log.Info("reconciling", getLoggingKeysAndValuesForDatabaseMigration(migration)...)
+
+	if migration.Status.Phase == stardogv1beta1.DatabaseMigrationCompleted || migration.GetDeletionTimestamp() != nil {
+		return ctrl.Result{Requeue: false}, nil
+	}
+
+	if err := r.validateSpecification(mr); err != nil {
+		r.Log.Error(err, "Specification cannot be validated")
+		rc.SetStatusCondition(createStatusConditionInvalid(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Specification cannot be validated"))
+		return ctrl.Result{Requeue: false}, r.updateStatus(mr)
+	}
+	rc.SetStatusIfExisting(stardogv1alpha1.StardogInvalid, v1.ConditionFalse)
+
+	if err := r.migrate(mr); err != nil {
+		r.Log.Error(err, "Migration failed", "phase", migration.Status.Phase)
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, fmt.Sprintf("Migration failed in phase %s", migration.Status.Phase)))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(mr)
+	}
+	rc.SetStatusIfExisting(stardogv1alpha1.StardogErrored, v1.ConditionFalse)
+
+	if migration.Status.Phase != stardogv1beta1.DatabaseMigrationCompleted {
+		rc.SetStatusCondition(createStatusConditionReady(false, fmt.Sprintf("Migration in phase %s", migration.Status.Phase)))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(mr)
+	}
+
+	rc.SetStatusCondition(createStatusConditionReady(true, "Migrated"))
+	return ctrl.Result{Requeue: false}, r.updateStatus(mr)
+}
+
+func (r *DatabaseMigrationReconciler) updateStatus(mr *DatabaseMigrationReconciliation) error {
+	res := mr.resource
+	status := res.Status
+	status.Conditions = mergeWithExistingConditions(status.Conditions, mr.reconciliationContext.conditions)
+	res.Status = status
+
+	err := r.Client.Status().Update(mr.reconciliationContext.context, res)
+	if err != nil {
+		r.Log.Error(err, "could not update DatabaseMigration", getLoggingKeysAndValuesForDatabaseMigration(res)...)
+		return err
+	}
+	r.Log.Info("updated DatabaseMigration status", getLoggingKeysAndValuesForDatabaseMigration(res)...)
+	return nil
+}
+
+// validateSpecification checks the spec and retrieves the source Database. Before the migration starts, it also
+// makes sure that the target does not exist yet.
+func (r *DatabaseMigrationReconciler) validateSpecification(mr *DatabaseMigrationReconciliation) error {
+	r.Log.V(1).Info("validating DatabaseMigrationSpec")
+	ctx := mr.reconciliationContext.context
+	migration := mr.resource
+	spec := migration.Spec
+
+	if spec.SourceDatabaseRef == "" {
+		return fmt.Errorf(".spec.SourceDatabaseRef is required")
+	}
+	if spec.Target.DatabaseRef == "" {
+		return fmt.Errorf(".spec.Target.DatabaseRef is required")
+	}
+	if spec.BackupLocation == "" {
+		return fmt.Errorf(".spec.BackupLocation is required")
+	}
+
+	mr.source = &stardogv1beta1.Database{}
+	err := r.Get(ctx, types.NamespacedName{Name: spec.SourceDatabaseRef}, mr.source)
+	if err != nil {
+		if apierrors.IsNotFound(err) && migration.Status.Phase == stardogv1beta1.DatabaseMigrationDroppingSource {
+			mr.source = nil
+			return nil
+		}
+		return fmt.Errorf("cannot get source database %s: %v", spec.SourceDatabaseRef, err)
+	}
+
+	if migration.Status.Phase != "" && migration.Status.Phase != stardogv1beta1.DatabaseMigrationPending {
+		return nil
+	}
+
+	if mr.source.Status.DatabaseName == "" {
+		return fmt.Errorf("source database %s has not been synchronized yet", spec.SourceDatabaseRef)
+	}
+	targetName, targetInstances := getMigrationTarget(migration, mr.source)
+	for _, instance := range targetInstances {
+		if targetName == mr.source.Spec.DatabaseName && containsStardogInstanceRef(mr.source.Spec.StardogInstanceRefs, instance) {
+			return fmt.Errorf("database %s already exists on instance %s/%s", targetName, instance.Namespace, instance.Name)
+		}
+	}
+
+	err = r.Get(ctx, types.NamespacedName{Name: spec.Target.DatabaseRef}, &stardogv1beta1.Database{})
+	if err == nil {
+		return fmt.Errorf("target database %s already exists", spec.Target.DatabaseRef)
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("cannot get target database %s: %v", spec.Target.DatabaseRef, err)
+	}
+
+	return nil
+}
+
+// migrate executes the phases of the migration until one of them has to wait for another reconciliation
+func (r *DatabaseMigrationReconciler) migrate(mr *DatabaseMigrationReconciliation) error {
+	status := &mr.resource.Status
+
+	for {
+		switch status.Phase {
+		case "", stardogv1beta1.DatabaseMigrationPending:
+			status.SourceDatabaseName = mr.source.Spec.DatabaseName
+			status.Phase = stardogv1beta1.DatabaseMigrationBackingUp
+		case stardogv1beta1.DatabaseMigrationBackingUp:
+			if err := r.backupSource(mr); err != nil {
+				return err
+			}
+			status.Phase = stardogv1beta1.DatabaseMigrationRestoring
+		case stardogv1beta1.DatabaseMigrationRestoring:
+			if err := r.restoreTarget(mr); err != nil {
+				return err
+			}
+			status.Phase = stardogv1beta1.DatabaseMigrationSynchronizing
+		case stardogv1beta1.DatabaseMigrationSynchronizing:
+			synchronized, err := r.syncTarget(mr)
+			if err != nil || !synchronized {
+				return err
+			}
+			status.Phase = stardogv1beta1.DatabaseMigrationRepointing
+		case stardogv1beta1.DatabaseMigrationRepointing:
+			repointed, err := r.repointOrganizations(mr)
+			if err != nil || !repointed {
+				return err
+			}
+			status.Phase = stardogv1beta1.DatabaseMigrationCompleted
+			if mr.resource.Spec.DropSource {
+				status.Phase = stardogv1beta1.DatabaseMigrationDroppingSource
+			}
+		case stardogv1beta1.DatabaseMigrationDroppingSource:
+			if err := r.dropSource(mr); err != nil {
+				return err
+			}
+			status.Phase = stardogv1beta1.DatabaseMigrationCompleted
+		default:
+			return nil
+		}
+		r.Log.Info("migration entered phase", "phase", status.Phase, "migration", mr.resource.Name)
+	}
+}
+
+// backupSource backs up the source database from its first instance into a directory named after the migration
+func (r *DatabaseMigrationReconciler) backupSource(mr *DatabaseMigrationReconciliation) error {
+	migration := mr.resource
+	instance := mr.source.Spec.StardogInstanceRefs[0]
+	dbName := migration.Status.SourceDatabaseName
+
+	stardogClient, disabled, err := mr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+	if err != nil {
+		return fmt.Errorf("cannot initialize stardog client: %v", err)
+	}
+	if disabled {
+		return fmt.Errorf("cannot back up database %s from disabled instance %s/%s", dbName, instance.Namespace, instance.Name)
+	}
+
+	location := path.Join(migration.Spec.BackupLocation, migration.Name)
+	err = stardogClient.BackupDatabase(mr.reconciliationContext.context, dbName, location)
+	if err != nil {
+		return fmt.Errorf("cannot back up database %s to %s: %v", dbName, location, err)
+	}
+	migration.Status.BackupLocation = path.Join(location, dbName)
+	r.Log.Info("backed up Stardog database", "name", dbName, "location", location)
+	return nil
+}
+
+// restoreTarget restores the backup under the target name on every target instance which does not have it yet
+func (r *DatabaseMigrationReconciler) restoreTarget(mr *DatabaseMigrationReconciliation) error {
+	ctx := mr.reconciliationContext.context
+	location := mr.resource.Status.BackupLocation
+	targetName, targetInstances := getMigrationTarget(mr.resource, mr.source)
+
+	for _, instance := range targetInstances {
+		stardogClient, disabled, err := mr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+		if err != nil {
+			return fmt.Errorf("cannot initialize stardog client: %v", err)
+		}
+		if disabled {
+			r.Log.Info("skipping resource from reconciliation", "instance", instance.Name, "resource", mr.resource.Name)
+			continue
+		}
+
+		liveDatabases, err := stardogClient.ListDatabases(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot list databases of instance %s/%s: %v", instance.Namespace, instance.Name, err)
+		}
+		if slices.Contains(liveDatabases, targetName) {
+			continue
+		}
+		err = stardogClient.RestoreDatabase(ctx, location, targetName, false)
+		if err != nil {
+			return fmt.Errorf("cannot restore database %s from %s on instance %s/%s: %v", targetName, location, instance.Namespace, instance.Name, err)
+		}
+		r.Log.Info("restored Stardog database", "name", targetName, "instance", instance.Name, "location", location)
+	}
+	return nil
+}
+
+// syncTarget creates the target Database, which creates the users and roles of the restored database. It returns
+// true once the target Database has been synchronized.
+func (r *DatabaseMigrationReconciler) syncTarget(mr *DatabaseMigrationReconciliation) (bool, error) {
+	ctx := mr.reconciliationContext.context
+	migration := mr.resource
+
+	target := &stardogv1beta1.Database{}
+	err := r.Get(ctx, types.NamespacedName{Name: migration.Spec.Target.DatabaseRef}, target)
+	if err == nil {
+		return target.Status.DatabaseName != "" && isConditionTrue(target.Status.Conditions, stardogv1alpha1.StardogReady), nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("cannot get target database %s: %v", migration.Spec.Target.DatabaseRef, err)
+	}
+
+	targetName, targetInstances := getMigrationTarget(migration, mr.source)
+	target = &stardogv1beta1.Database{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        migration.Spec.Target.DatabaseRef,
+			Annotations: map[string]string{stardogv1beta1.DatabaseMigrationAnnotation: migration.Name},
+		},
+		Spec: stardogv1beta1.DatabaseSpec{
+			DatabaseName:              targetName,
+			AddUserForNonHiddenGraphs: mr.source.Spec.AddUserForNonHiddenGraphs,
+			Options:                   mr.source.Spec.Options,
+			StardogInstanceRefs:       targetInstances,
+			NamedGraphPrefix:          mr.source.Spec.NamedGraphPrefix,
+		},
+	}
+	if err := r.Create(ctx, target); err != nil {
+		return false, fmt.Errorf("cannot create target database %s: %v", target.Name, err)
+	}
+	r.Log.Info("created target Database", "name", target.Name)
+	return false, nil
+}
+
+// repointOrganizations moves the Organizations of the source Database to the target Database. It returns true once
+// all of them have been synchronized with the target Database.
+func (r *DatabaseMigrationReconciler) repointOrganizations(mr *DatabaseMigrationReconciliation) (bool, error) {
+	ctx := mr.reconciliationContext.context
+	migration := mr.resource
+	targetRef := migration.Spec.Target.DatabaseRef
+
+	orgs := &stardogv1beta1.OrganizationList{}
+	if err := r.List(ctx, orgs); err != nil {
+		return false, fmt.Errorf("cannot get organization list: %v", err)
+	}
+
+	repointed := true
+	for i := range orgs.Items {
+		org := &orgs.Items[i]
+		if org.Spec.DatabaseRef == migration.Spec.SourceDatabaseRef {
+			org.Spec.DatabaseMigrationRef = migration.Name
+			org.Spec.DatabaseRef = targetRef
+			if err := r.Update(ctx, org); err != nil {
+				return false, fmt.Errorf("cannot move organization %s to database %s: %v", org.Name, targetRef, err)
+			}
+			if !slices.Contains(migration.Status.Organizations, org.Name) {
+				migration.Status.Organizations = append(migration.Status.Organizations, org.Name)
+			}
+			repointed = false
+			continue
+		}
+		if slices.Contains(migration.Status.Organizations, org.Name) &&
+			(org.Status.DatabaseRef != targetRef || !isConditionTrue(org.Status.Conditions, stardogv1alpha1.StardogReady)) {
+			repointed = false
+		}
+	}
+	return repointed, nil
+}
+
+// dropSource deletes the source Database and drops the source database on all its instances. The Database is deleted
+// first, so that it is not synchronized again while the database is dropped. Its finalizer completes once the
+// database is gone.
+func (r *DatabaseMigrationReconciler) dropSource(mr *DatabaseMigrationReconciliation) error {
+	source := mr.source
+	if source == nil {
+		return nil
+	}
+	ctx := mr.reconciliationContext.context
+
+	if source.GetDeletionTimestamp() == nil {
+		if err := r.Delete(ctx, source); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete source database %s: %v", source.Name, err)
+		}
+	}
+
+	_, targetInstances := getMigrationTarget(mr.resource, source)
+	for _, instance := range source.Spec.StardogInstanceRefs {
+		stardogClient, disabled, err := mr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+		if err != nil {
+			return fmt.Errorf("cannot initialize stardog client: %v", err)
+		}
+		if disabled {
+			r.Log.Info("skipping resource from reconciliation", "instance", instance.Name, "resource", mr.resource.Name)
+			continue
+		}
+
+		dropped := source.DeepCopy()
+		// The custom user is shared with the target database if both are on the same instance
+		if containsStardogInstanceRef(targetInstances, instance) {
+			dropped.Status.AddUserForNonHiddenGraphs = ""
+		}
+		if err := dropDatabase(ctx, stardogClient, dropped); err != nil {
+			return fmt.Errorf("cannot drop source database %s on instance %s/%s: %v", source.Spec.DatabaseName, instance.Namespace, instance.Name, err)
+		}
+		r.Log.Info("dropped Stardog database", "name", source.Spec.DatabaseName, "instance", instance.Name)
+	}
+	return nil
+}
+
+// getMigrationTarget returns the name and instances of the target database, defaulting to the ones of the source
+func getMigrationTarget(migration *stardogv1beta1.DatabaseMigration, source *stardogv1beta1.Database) (string, []stardogv1beta1.StardogInstanceRef) {
+	name := migration.Spec.Target.DatabaseName
+	if name == "" {
+		name = source.Spec.DatabaseName
+	}
+	instances := migration.Spec.Target.StardogInstanceRefs
+	if len(instances) == 0 {
+		instances = source.Spec.StardogInstanceRefs
+	}
+	return name, instances
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DatabaseMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&stardogv1beta1.DatabaseMigration{}).
+		Complete(r)
+}
+
+func getLoggingKeysAndValuesForDatabaseMigration(migration *stardogv1beta1.DatabaseMigration) []interface{} {
+	return []interface{}{
+		"DatabaseMigration", migration.Name,
+	}
+}
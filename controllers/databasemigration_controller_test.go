@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_reconcileDatabaseMigration(t *testing.T) {
+	namespace := "namespace-test"
+	stardogInstanceName := "instance-test"
+	secretName := "secret-test"
+	instanceRef := v1beta1.NewStardogInstanceRef(stardogInstanceName, namespace)
+	readyCondition := []v1alpha1.StardogCondition{{Type: v1alpha1.StardogReady, Status: v1.ConditionTrue}}
+
+	tests := []struct {
+		name          string
+		phase         v1beta1.DatabaseMigrationPhase
+		dropSource    bool
+		objects       []client.Object
+		expectMocks   func(stardogMocked *mock.MockStardogAPI)
+		expectedPhase v1beta1.DatabaseMigrationPhase
+		assertObjects func(t *testing.T, kubeClient client.Client)
+	}{
+		{
+			name:  "GivenNewMigration_WhenReconciling_ThenBackupRestoreAndCreateTargetDatabase",
+			phase: "",
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().BackupDatabase(gomock.Any(), "source-db", "/backup/migration-test").Return(nil).Times(1)
+				stardogMocked.EXPECT().ListDatabases(gomock.Any()).Return([]string{"source-db"}, nil).Times(1)
+				stardogMocked.EXPECT().RestoreDatabase(gomock.Any(), "/backup/migration-test/source-db", "target-db", false).Return(nil).Times(1)
+			},
+			expectedPhase: v1beta1.DatabaseMigrationSynchronizing,
+			assertObjects: func(t *testing.T, kubeClient client.Client) {
+				target := &v1beta1.Database{}
+				assert.NoError(t, kubeClient.Get(context.Background(), types.NamespacedName{Name: "target"}, target))
+				assert.Equal(t, "target-db", target.Spec.DatabaseName)
+				assert.Equal(t, "hidden-user", target.Spec.AddUserForNonHiddenGraphs)
+				assert.Equal(t, []v1beta1.StardogInstanceRef{instanceRef}, target.Spec.StardogInstanceRefs)
+				assert.Equal(t, "migration-test", target.Annotations[v1beta1.DatabaseMigrationAnnotation])
+			},
+		},
+		{
+			name:  "GivenRestoredDatabase_WhenReconcilingAgain_ThenSkipRestore",
+			phase: v1beta1.DatabaseMigrationRestoring,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().ListDatabases(gomock.Any()).Return([]string{"source-db", "target-db"}, nil).Times(1)
+			},
+			expectedPhase: v1beta1.DatabaseMigrationSynchronizing,
+		},
+		{
+			name:  "GivenSynchronizedTarget_WhenReconciling_ThenRepointOrganizations",
+			phase: v1beta1.DatabaseMigrationSynchronizing,
+			objects: []client.Object{
+				&v1beta1.Database{
+					ObjectMeta: metav1.ObjectMeta{Name: "target"},
+					Spec:       v1beta1.DatabaseSpec{DatabaseName: "target-db"},
+					Status:     v1beta1.DatabaseStatus{DatabaseName: "target-db", Conditions: readyCondition},
+				},
+				createOrg("org-test", "source", []v1beta1.NamedGraph{{Name: "graph1"}}),
+			},
+			expectMocks:   func(_ *mock.MockStardogAPI) {},
+			expectedPhase: v1beta1.DatabaseMigrationRepointing,
+			assertObjects: func(t *testing.T, kubeClient client.Client) {
+				org := &v1beta1.Organization{}
+				assert.NoError(t, kubeClient.Get(context.Background(), types.NamespacedName{Name: "org-test"}, org))
+				assert.Equal(t, "target", org.Spec.DatabaseRef)
+				assert.Equal(t, "migration-test", org.Spec.DatabaseMigrationRef)
+			},
+		},
+		{
+			name:       "GivenMovedOrganizations_WhenDropSource_ThenDeleteSourceAndComplete",
+			phase:      v1beta1.DatabaseMigrationRepointing,
+			dropSource: true,
+			objects: []client.Object{
+				&v1beta1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "org-test"},
+					Spec:       v1beta1.OrganizationSpec{Name: "org-test", DisplayName: "org-test", DatabaseRef: "target"},
+					Status:     v1beta1.OrganizationStatus{DatabaseRef: "target", Conditions: readyCondition},
+				},
+			},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().DeleteUserRole(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(3)
+				stardogMocked.EXPECT().DeleteRole(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				stardogMocked.EXPECT().DeleteUser(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				stardogMocked.EXPECT().DropDatabase(gomock.Any(), "source-db").Return(nil).Times(1)
+			},
+			expectedPhase: v1beta1.DatabaseMigrationCompleted,
+			assertObjects: func(t *testing.T, kubeClient client.Client) {
+				source := &v1beta1.Database{}
+				err := kubeClient.Get(context.Background(), types.NamespacedName{Name: "source"}, source)
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			tt.expectMocks(stardogMocked)
+
+			source := createStardogDB("source-db", "hidden-user", instanceRef)
+			source.Name = "source"
+			source.Status.DatabaseName = "source-db"
+			source.Status.AddUserForNonHiddenGraphs = "hidden-user"
+			migration := &v1beta1.DatabaseMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "migration-test"},
+				Spec: v1beta1.DatabaseMigrationSpec{
+					SourceDatabaseRef: "source",
+					Target:            v1beta1.DatabaseMigrationTarget{DatabaseRef: "target", DatabaseName: "target-db"},
+					BackupLocation:    "/backup",
+					DropSource:        tt.dropSource,
+				},
+				Status: v1beta1.DatabaseMigrationStatus{
+					Phase:              tt.phase,
+					SourceDatabaseName: "source-db",
+					BackupLocation:     "/backup/migration-test/source-db",
+					Organizations:      []string{"org-test"},
+				},
+			}
+			objects := append([]client.Object{
+				migration,
+				source,
+				createStardogInstanceWithFinalizers(namespace, stardogInstanceName, secretName, "http://url-test.ch"),
+				createFullSecret(namespace, secretName, "admin", "1234"),
+			}, tt.objects...)
+			fakeKubeClient, err := createKubeFakeClientWithSub(objects...)
+			assert.NoError(t, err)
+			r := DatabaseMigrationReconciler{
+				Client: fakeKubeClient,
+				Log:    testr.New(t),
+				Scheme: scheme.Scheme,
+			}
+			mr := &DatabaseMigrationReconciliation{
+				resource: migration,
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+			}
+
+			_, err = r.reconcileDatabaseMigration(mr)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPhase, migration.Status.Phase)
+			assert.False(t, isConditionTrue(migration.Status.Conditions, v1alpha1.StardogErrored))
+			if tt.assertObjects != nil {
+				tt.assertObjects(t, fakeKubeClient)
+			}
+		})
+	}
+}
+
+func Test_validateSpecificationDatabaseMigration(t *testing.T) {
+	instanceRef := v1beta1.NewStardogInstanceRef("instance-test", "namespace-test")
+	otherInstanceRef := v1beta1.NewStardogInstanceRef("other-instance-test", "namespace-test")
+
+	tests := []struct {
+		name        string
+		target      v1beta1.DatabaseMigrationTarget
+		objects     []client.Object
+		expectedErr bool
+	}{
+		{
+			name:        "GivenRename_WhenValidating_ThenNoError",
+			target:      v1beta1.DatabaseMigrationTarget{DatabaseRef: "target", DatabaseName: "target-db"},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenSameNameOnOtherInstance_WhenValidating_ThenNoError",
+			target:      v1beta1.DatabaseMigrationTarget{DatabaseRef: "target", StardogInstanceRefs: []v1beta1.StardogInstanceRef{otherInstanceRef}},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenSameNameOnSameInstance_WhenValidating_ThenError",
+			target:      v1beta1.DatabaseMigrationTarget{DatabaseRef: "target", StardogInstanceRefs: []v1beta1.StardogInstanceRef{instanceRef}},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenExistingTargetDatabase_WhenValidating_ThenError",
+			target:      v1beta1.DatabaseMigrationTarget{DatabaseRef: "target", DatabaseName: "target-db"},
+			objects:     []client.Object{createStardogDB("target", "", instanceRef)},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := createStardogDB("source", "", instanceRef)
+			source.Status.DatabaseName = "source"
+			fakeKubeClient, err := createKubeFakeClientWithSub(append(tt.objects, source)...)
+			assert.NoError(t, err)
+			r := DatabaseMigrationReconciler{Client: fakeKubeClient, Log: testr.New(t)}
+			mr := &DatabaseMigrationReconciliation{
+				resource: &v1beta1.DatabaseMigration{
+					ObjectMeta: metav1.ObjectMeta{Name: "migration-test"},
+					Spec:       v1beta1.DatabaseMigrationSpec{SourceDatabaseRef: "source", Target: tt.target, BackupLocation: "/backup"},
+				},
+				reconciliationContext: &ReconciliationContext{context: context.Background()},
+			}
+
+			err = r.validateSpecification(mr)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	stardogv1alpha1 "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	stardogv1beta1 "github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	scheme "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DatabaseRestoreReconciler reconciles a DatabaseRestore object
+type DatabaseRestoreReconciler struct {
+	client.Client
+	Log            logr.Logger
+	Scheme         *scheme.Scheme
+	StardogClients *StardogClientPool
+}
+
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databaserestores,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databaserestores/status,verbs=get;update;patch
+
+// Reconcile restores the backup of a DatabaseRestore step by step
+func (r *DatabaseRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	restore := &stardogv1beta1.DatabaseRestore{}
+	err := r.Get(ctx, req.NamespacedName, restore)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Log.Info("DatabaseRestore not found, ignoring reconcile.")
+			return ctrl.Result{Requeue: false}, nil
+		}
+		r.Log.Error(err, "Could not retrieve DatabaseRestore.")
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, err
+	}
+
+	rr := &DatabaseRestoreReconciliation{
+		reconciliationContext: &ReconciliationContext{
+			context:        ctx,
+			conditions:     make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
+			stardogClients: r.StardogClients,
+		},
+		resource: restore,
+	}
+
+	return r.reconcileDatabaseRestore(rr)
+}
+
+func (r *DatabaseRestoreReconciler) reconcileDatabaseRestore(rr *DatabaseRestoreReconciliation) (ctrl.Result, error) {
+	rc := rr.reconciliationContext
+	restore := rr.resource
+
+	r.Log.Info("reconciling", getLoggingKeysAndValuesForDatabaseRestore(restore)...)
+
+	if restore.Status.Phase == stardogv1beta1.DatabaseRestoreCompleted || restore.GetDeletionTimestamp() != nil {
+		return ctrl.Result{Requeue: false}, nil
+	}
+
+	if err := r.validateSpecification(rr); err != nil {
+		r.Log.Error(err, "Specification cannot be validated")
+		rc.SetStatusCondition(createStatusConditionInvalid(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Specification cannot be validated"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(rr)
+	}
+	rc.SetStatusIfExisting(stardogv1alpha1.StardogInvalid, v1.ConditionFalse)
+
+	if err := r.restore(rr); err != nil {
+		r.Log.Error(err, "Restore failed", "phase", restore.Status.Phase)
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, fmt.Sprintf("Restore failed in phase %s", restore.Status.Phase)))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(rr)
+	}
+	rc.SetStatusIfExisting(stardogv1alpha1.StardogErrored, v1.ConditionFalse)
+
+	if restore.Status.Phase != stardogv1beta1.DatabaseRestoreCompleted {
+		r.Log.Info("waiting for disabled instances to be restored", "restored", restore.Status.RestoredInstances)
+		rc.SetStatusCondition(createStatusConditionReady(false, "Waiting for disabled instances"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(rr)
+	}
+
+	rc.SetStatusCondition(createStatusConditionReady(true, "Restored"))
+	return ctrl.Result{Requeue: false}, r.updateStatus(rr)
+}
+
+func (r *DatabaseRestoreReconciler) updateStatus(rr *DatabaseRestoreReconciliation) error {
+	res := rr.resource
+	status := res.Status
+	status.Conditions = mergeWithExistingConditions(status.Conditions, rr.reconciliationContext.conditions)
+	res.Status = status
+
+	err := r.Client.Status().Update(rr.reconciliationContext.context, res)
+	if err != nil {
+		r.Log.Error(err, "could not update DatabaseRestore", getLoggingKeysAndValuesForDatabaseRestore(res)...)
+		return err
+	}
+	r.Log.Info("updated DatabaseRestore status", getLoggingKeysAndValuesForDatabaseRestore(res)...)
+	return nil
+}
+
+// validateSpecification checks the spec and retrieves the restored Database and the DatabaseBackup, if any
+func (r *DatabaseRestoreReconciler) validateSpecification(rr *DatabaseRestoreReconciliation) error {
+	r.Log.V(1).Info("validating DatabaseRestoreSpec")
+	ctx := rr.reconciliationContext.context
+	spec := rr.resource.Spec
+
+	if spec.DatabaseRef == "" {
+		return fmt.Errorf(".spec.DatabaseRef is required")
+	}
+	if (spec.BackupRef == "") == (spec.Location == "") {
+		return fmt.Errorf("either .spec.BackupRef or .spec.Location is required")
+	}
+
+	rr.database = &stardogv1beta1.Database{}
+	err := r.Get(ctx, types.NamespacedName{Name: spec.DatabaseRef}, rr.database)
+	if err != nil {
+		return fmt.Errorf("cannot get database %s: %v", spec.DatabaseRef, err)
+	}
+	if rr.database.Status.DatabaseName == "" {
+		return fmt.Errorf("database %s has not been synchronized yet", spec.DatabaseRef)
+	}
+	if restoreName := rr.database.Annotations[stardogv1beta1.DatabaseRestoreAnnotation]; restoreName != "" && restoreName != rr.resource.Name {
+		running, err := isRestoreRunning(ctx, r.Client, rr.database)
+		if err != nil {
+			return err
+		}
+		if running {
+			return fmt.Errorf("database %s is being restored by %s", spec.DatabaseRef, restoreName)
+		}
+	}
+
+	if spec.BackupRef == "" {
+		return nil
+	}
+	rr.backup = &stardogv1beta1.DatabaseBackup{}
+	err = r.Get(ctx, types.NamespacedName{Name: spec.BackupRef}, rr.backup)
+	if err != nil {
+		return fmt.Errorf("cannot get backup %s: %v", spec.BackupRef, err)
+	}
+	if rr.backup.Status.Phase != stardogv1beta1.DatabaseBackupCompleted {
+		return fmt.Errorf("backup %s has not been completed yet", spec.BackupRef)
+	}
+	return nil
+}
+
+// restore executes the phases of the restore. The Database is not synchronized by its reconciler until the restore
+// is completed, so that it does not create an empty database in the meantime. The restore stays in the Restoring
+// phase until the database has been restored on every instance of the Database.
+func (r *DatabaseRestoreReconciler) restore(rr *DatabaseRestoreReconciliation) error {
+	ctx := rr.reconciliationContext.context
+	restore := rr.resource
+	status := &restore.Status
+
+	for {
+		switch status.Phase {
+		case "", stardogv1beta1.DatabaseRestorePending:
+			if err := r.setDatabaseAnnotation(ctx, rr.database, restore.Name); err != nil {
+				return err
+			}
+			startTime := metav1.NewTime(now())
+			status.StartTime = &startTime
+			status.DatabaseName = rr.database.Status.DatabaseName
+			status.Phase = stardogv1beta1.DatabaseRestoreRestoring
+		case stardogv1beta1.DatabaseRestoreRestoring:
+			if err := r.restoreInstances(rr); err != nil {
+				return err
+			}
+			if !allInstancesRestored(rr) {
+				return nil
+			}
+			status.Phase = stardogv1beta1.DatabaseRestoreSynchronizing
+		case stardogv1beta1.DatabaseRestoreSynchronizing:
+			if err := r.syncDatabase(rr); err != nil {
+				return err
+			}
+			if err := r.setDatabaseAnnotation(ctx, rr.database, ""); err != nil {
+				return err
+			}
+			completionTime := metav1.NewTime(now())
+			status.CompletionTime = &completionTime
+			status.Phase = stardogv1beta1.DatabaseRestoreCompleted
+		default:
+			return nil
+		}
+		r.Log.Info("restore entered phase", "phase", status.Phase, "restore", restore.Name)
+	}
+}
+
+// restoreInstances overwrites the database with the backup on every instance of the Database which has not been
+// restored yet. Disabled instances are skipped and restored by a later reconciliation.
+func (r *DatabaseRestoreReconciler) restoreInstances(rr *DatabaseRestoreReconciliation) error {
+	ctx := rr.reconciliationContext.context
+	status := &rr.resource.Status
+	dbName := status.DatabaseName
+
+	for _, instance := range rr.database.Spec.StardogInstanceRefs {
+		if containsStardogInstanceRef(status.RestoredInstances, instance) {
+			continue
+		}
+		location, err := getRestoreLocation(rr, instance)
+		if err != nil {
+			return err
+		}
+
+		stardogClient, disabled, err := rr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+		if err != nil {
+			return fmt.Errorf("cannot initialize stardog client: %v", err)
+		}
+		if disabled {
+			r.Log.Info("skipping resource from reconciliation", "instance", instance.Name, "resource", rr.resource.Name)
+			continue
+		}
+
+		err = stardogClient.RestoreDatabase(ctx, location, dbName, true)
+		if err != nil {
+			return fmt.Errorf("cannot restore database %s from %s on instance %s/%s: %v", dbName, location, instance.Namespace, instance.Name, err)
+		}
+		status.RestoredInstances = append(status.RestoredInstances, instance)
+		r.Log.Info("restored Stardog database", "name", dbName, "instance", instance.Name, "location", location)
+	}
+	return nil
+}
+
+// syncDatabase applies the users, roles, permissions and credential Secrets of the Database and of its synchronized
+// Organizations again, the same way their reconcilers do
+func (r *DatabaseRestoreReconciler) syncDatabase(rr *DatabaseRestoreReconciliation) error {
+	ctx := rr.reconciliationContext.context
+	database := rr.database
+
+	databaseReconciler := &DatabaseReconciler{Client: r.Client, Log: r.Log, Scheme: r.Scheme, StardogClients: r.StardogClients}
+	err := databaseReconciler.syncDB(&DatabaseReconciliation{
+		resource:              database,
+		reconciliationContext: newReconciliationContext(rr.reconciliationContext),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot synchronize database %s: %v", database.Name, err)
+	}
+
+	orgs := &stardogv1beta1.OrganizationList{}
+	if err := r.List(ctx, orgs); err != nil {
+		return fmt.Errorf("cannot get organization list: %v", err)
+	}
+	organizationReconciler := &OrganizationReconciler{Client: r.Client, Log: r.Log, Scheme: r.Scheme, StardogClients: r.StardogClients}
+	for i := range orgs.Items {
+		org := &orgs.Items[i]
+		if org.Spec.DatabaseRef != database.Name || org.Status.DatabaseRef != database.Name || org.GetDeletionTimestamp() != nil {
+			continue
+		}
+		err := organizationReconciler.syncOrganization(&OrganizationReconciliation{
+			database:              database,
+			resource:              org,
+			reconciliationContext: newReconciliationContext(rr.reconciliationContext),
+		})
+		if err != nil {
+			return fmt.Errorf("cannot synchronize organization %s: %v", org.Name, err)
+		}
+	}
+	return nil
+}
+
+// setDatabaseAnnotation sets the DatabaseRestoreAnnotation of the Database to the given value, an empty value
+// removes it
+func (r *DatabaseRestoreReconciler) setDatabaseAnnotation(ctx context.Context, database *stardogv1beta1.Database, value string) error {
+	if database.Annotations[stardogv1beta1.DatabaseRestoreAnnotation] == value {
+		return nil
+	}
+	if value == "" {
+		delete(database.Annotations, stardogv1beta1.DatabaseRestoreAnnotation)
+	} else {
+		if database.Annotations == nil {
+			database.Annotations = map[string]string{}
+		}
+		database.Annotations[stardogv1beta1.DatabaseRestoreAnnotation] = value
+	}
+	if err := r.Update(ctx, database); err != nil {
+		return fmt.Errorf("cannot update database %s: %v", database.Name, err)
+	}
+	return nil
+}
+
+// allInstancesRestored returns true if the database has been restored on every instance of the Database
+func allInstancesRestored(rr *DatabaseRestoreReconciliation) bool {
+	for _, instance := range rr.database.Spec.StardogInstanceRefs {
+		if !containsStardogInstanceRef(rr.resource.Status.RestoredInstances, instance) {
+			return false
+		}
+	}
+	return true
+}
+
+// getRestoreLocation returns the directory the given instance is restored from
+func getRestoreLocation(rr *DatabaseRestoreReconciliation, instance stardogv1beta1.StardogInstanceRef) (string, error) {
+	if rr.backup == nil {
+		return rr.resource.Spec.Location, nil
+	}
+	for _, backup := range rr.backup.Status.Instances {
+		if backup.StardogInstanceRef == instance {
+			return backup.Location, nil
+		}
+	}
+	return "", fmt.Errorf("backup %s does not contain a backup of instance %s/%s", rr.backup.Name, instance.Namespace, instance.Name)
+}
+
+// isRestoreRunning returns true if the DatabaseRestore referenced by the DatabaseRestoreAnnotation of the Database
+// exists and has not been completed yet
+func isRestoreRunning(ctx context.Context, kubeClient client.Client, database *stardogv1beta1.Database) (bool, error) {
+	restoreName := database.Annotations[stardogv1beta1.DatabaseRestoreAnnotation]
+	if restoreName == "" {
+		return false, nil
+	}
+	restore := &stardogv1beta1.DatabaseRestore{}
+	err := kubeClient.Get(ctx, types.NamespacedName{Name: restoreName}, restore)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot get restore %s: %v", restoreName, err)
+	}
+	return restore.GetDeletionTimestamp() == nil && restore.Status.Phase != stardogv1beta1.DatabaseRestoreCompleted, nil
+}
+
+// newReconciliationContext returns an empty ReconciliationContext sharing the context and clients of the given one
+func newReconciliationContext(rc *ReconciliationContext) *ReconciliationContext {
+	return &ReconciliationContext{
+		context:        rc.context,
+		conditions:     make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
+		stardogClients: rc.stardogClients,
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DatabaseRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&stardogv1beta1.DatabaseRestore{}).
+		Complete(r)
+}
+
+func getLoggingKeysAndValuesForDatabaseRestore(restore *stardogv1beta1.DatabaseRestore) []interface{} {
+	return []interface{}{
+		"DatabaseRestore", restore.Name,
+	}
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_reconcileDatabaseRestore(t *testing.T) {
+	namespace := "namespace-test"
+	secretName := "secret-test"
+	instanceRef := v1beta1.NewStardogInstanceRef("instance-test", namespace)
+
+	tests := []struct {
+		name             string
+		spec             v1beta1.DatabaseRestoreSpec
+		disabledInstance bool
+		expectMocks      func(stardogMocked *mock.MockStardogAPI)
+		expectedPhase    v1beta1.DatabaseRestorePhase
+		expectedRequeue  bool
+		expectedErrored  bool
+		expectedPaused   bool
+	}{
+		{
+			name: "GivenBackupRef_WhenReconciling_ThenRestoreFromInstanceBackupAndSynchronize",
+			spec: v1beta1.DatabaseRestoreSpec{DatabaseRef: "db-test", BackupRef: "backup-test"},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().RestoreDatabase(gomock.Any(), "/backup/namespace-test/instance-test/db-test", "db-test", true).Return(nil).Times(1)
+				expectSynchronizedDatabase(stardogMocked, "db-test")
+			},
+			expectedPhase: v1beta1.DatabaseRestoreCompleted,
+		},
+		{
+			name: "GivenLocation_WhenReconciling_ThenRestoreFromLocation",
+			spec: v1beta1.DatabaseRestoreSpec{DatabaseRef: "db-test", Location: "/backup/manual/db-test"},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().RestoreDatabase(gomock.Any(), "/backup/manual/db-test", "db-test", true).Return(nil).Times(1)
+				expectSynchronizedDatabase(stardogMocked, "db-test")
+			},
+			expectedPhase: v1beta1.DatabaseRestoreCompleted,
+		},
+		{
+			name: "GivenFailingRestore_WhenReconciling_ThenKeepDatabasePaused",
+			spec: v1beta1.DatabaseRestoreSpec{DatabaseRef: "db-test", BackupRef: "backup-test"},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().RestoreDatabase(gomock.Any(), gomock.Any(), "db-test", true).Return(errors.New("backup not found")).Times(1)
+			},
+			expectedPhase:   v1beta1.DatabaseRestoreRestoring,
+			expectedRequeue: true,
+			expectedErrored: true,
+			expectedPaused:  true,
+		},
+		{
+			name:             "GivenDisabledInstance_WhenReconciling_ThenWaitInRestoringPhase",
+			spec:             v1beta1.DatabaseRestoreSpec{DatabaseRef: "db-test", BackupRef: "backup-test"},
+			disabledInstance: true,
+			expectMocks:      func(stardogMocked *mock.MockStardogAPI) {},
+			expectedPhase:    v1beta1.DatabaseRestoreRestoring,
+			expectedRequeue:  true,
+			expectedPaused:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			tt.expectMocks(stardogMocked)
+
+			database := createStardogDB("db-test", "", instanceRef)
+			database.Status.DatabaseName = "db-test"
+			backup := &v1beta1.DatabaseBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup-test"},
+				Spec:       v1beta1.DatabaseBackupSpec{DatabaseRef: "db-test", Location: "/backup"},
+				Status: v1beta1.DatabaseBackupStatus{
+					Phase: v1beta1.DatabaseBackupCompleted,
+					Instances: []v1beta1.DatabaseBackupInstanceStatus{
+						{StardogInstanceRef: instanceRef, Location: "/backup/namespace-test/instance-test/db-test"},
+					},
+				},
+			}
+			restore := &v1beta1.DatabaseRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: "restore-test"},
+				Spec:       tt.spec,
+			}
+			instance := createStardogInstanceWithFinalizers(namespace, instanceRef.Name, secretName, "http://url-test.ch")
+			instance.Spec.Disabled = tt.disabledInstance
+			fakeKubeClient, err := createKubeFakeClientWithSub(
+				restore,
+				backup,
+				database,
+				instance,
+				createFullSecret(namespace, secretName, "admin", "1234"),
+				createDatabaseCredentialSecret(namespace, "db-test", instanceRef.Name),
+			)
+			assert.NoError(t, err)
+			r := DatabaseRestoreReconciler{
+				Client: fakeKubeClient,
+				Log:    testr.New(t),
+				Scheme: scheme.Scheme,
+			}
+			rr := &DatabaseRestoreReconciliation{
+				resource: restore,
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+			}
+
+			result, err := r.reconcileDatabaseRestore(rr)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRequeue, result.Requeue)
+			assert.Equal(t, tt.expectedPhase, restore.Status.Phase)
+			assert.Equal(t, tt.expectedErrored, isConditionTrue(restore.Status.Conditions, v1alpha1.StardogErrored))
+			updatedDatabase := &v1beta1.Database{}
+			assert.NoError(t, fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: "db-test"}, updatedDatabase))
+			paused, err := isRestoreRunning(context.Background(), fakeKubeClient, updatedDatabase)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPaused, paused)
+		})
+	}
+}
+
+func Test_reconcileDatabaseDuringRestore(t *testing.T) {
+	tests := []struct {
+		name  string
+		phase v1beta1.DatabaseRestorePhase
+	}{
+		{
+			name:  "GivenRestoringRestore_WhenReconcilingDatabase_ThenSkipSynchronization",
+			phase: v1beta1.DatabaseRestoreRestoring,
+		},
+		{
+			name:  "GivenSynchronizingRestore_WhenReconcilingDatabase_ThenSkipSynchronization",
+			phase: v1beta1.DatabaseRestoreSynchronizing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := createStardogDB("db-test", "", v1beta1.NewStardogInstanceRef("instance-test", "namespace-test"))
+			database.Annotations = map[string]string{v1beta1.DatabaseRestoreAnnotation: "restore-test"}
+			restore := &v1beta1.DatabaseRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: "restore-test"},
+				Spec:       v1beta1.DatabaseRestoreSpec{DatabaseRef: "db-test", Location: "/backup"},
+				Status:     v1beta1.DatabaseRestoreStatus{Phase: tt.phase},
+			}
+			fakeKubeClient, err := createKubeFakeClientWithSub([]client.Object{database, restore}...)
+			assert.NoError(t, err)
+			r := DatabaseReconciler{Client: fakeKubeClient, Log: testr.New(t), Scheme: scheme.Scheme}
+			dr := &DatabaseReconciliation{
+				resource: database,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+				},
+			}
+
+			_, err = r.reconcileDatabase(dr)
+
+			assert.NoError(t, err)
+			assert.False(t, isConditionTrue(database.Status.Conditions, v1alpha1.StardogReady))
+			assert.Empty(t, database.Status.DatabaseName)
+		})
+	}
+}
+
+func Test_isRestoreRunning(t *testing.T) {
+	deletionTime := metav1.NewTime(now())
+	tests := []struct {
+		name       string
+		annotation string
+		restore    *v1beta1.DatabaseRestore
+		expected   bool
+	}{
+		{
+			name:     "GivenNoAnnotation_WhenChecking_ThenNotRunning",
+			expected: false,
+		},
+		{
+			name:       "GivenMissingRestore_WhenChecking_ThenNotRunning",
+			annotation: "restore-test",
+			expected:   false,
+		},
+		{
+			name:       "GivenRestoringRestore_WhenChecking_ThenRunning",
+			annotation: "restore-test",
+			restore:    createDatabaseRestore(v1beta1.DatabaseRestoreRestoring, nil),
+			expected:   true,
+		},
+		{
+			name:       "GivenCompletedRestore_WhenChecking_ThenNotRunning",
+			annotation: "restore-test",
+			restore:    createDatabaseRestore(v1beta1.DatabaseRestoreCompleted, nil),
+			expected:   false,
+		},
+		{
+			name:       "GivenDeletedRestore_WhenChecking_ThenNotRunning",
+			annotation: "restore-test",
+			restore:    createDatabaseRestore(v1beta1.DatabaseRestoreRestoring, &deletionTime),
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := createStardogDB("db-test", "", v1beta1.NewStardogInstanceRef("instance-test", "namespace-test"))
+			if tt.annotation != "" {
+				database.Annotations = map[string]string{v1beta1.DatabaseRestoreAnnotation: tt.annotation}
+			}
+			objects := []client.Object{database}
+			if tt.restore != nil {
+				objects = append(objects, tt.restore)
+			}
+			fakeKubeClient, err := createKubeFakeClientWithSub(objects...)
+			assert.NoError(t, err)
+
+			running, err := isRestoreRunning(context.Background(), fakeKubeClient, database)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, running)
+		})
+	}
+}
+
+// createDatabaseRestore returns a DatabaseRestore of db-test in the given phase
+func createDatabaseRestore(phase v1beta1.DatabaseRestorePhase, deletionTimestamp *metav1.Time) *v1beta1.DatabaseRestore {
+	restore := &v1beta1.DatabaseRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore-test", DeletionTimestamp: deletionTimestamp},
+		Spec:       v1beta1.DatabaseRestoreSpec{DatabaseRef: "db-test", Location: "/backup"},
+		Status:     v1beta1.DatabaseRestoreStatus{Phase: phase},
+	}
+	if deletionTimestamp != nil {
+		restore.Finalizers = []string{"finalizer-test"}
+	}
+	return restore
+}
+
+// expectSynchronizedDatabase expects the calls of DatabaseReconciler.sync for a database whose users, roles and
+// permissions all exist already
+func expectSynchronizedDatabase(stardogMocked *mock.MockStardogAPI, dbName string) {
+	read, write := getUserRoleNames(dbName)
+	stardogMocked.EXPECT().ListDatabases(gomock.Any()).Return([]string{dbName}, nil).Times(1)
+	stardogMocked.EXPECT().IsDatabaseOnline(gomock.Any(), dbName).Return(true, nil).Times(1)
+	stardogMocked.EXPECT().ListUsers(gomock.Any()).Return([]string{read, write}, nil).Times(1)
+	stardogMocked.EXPECT().GetRoles(gomock.Any()).Return([]string{read, write}, nil).Times(1)
+	stardogMocked.EXPECT().GetRolePermissions(gomock.Any(), gomock.Any()).
+		Return(append(getDBReadPermissions(dbName), getDBWritePermissions(dbName)...), nil).Times(2)
+	stardogMocked.EXPECT().GetUserRoles(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, name string) ([]string, error) { return []string{name}, nil }).Times(2)
+}
+
+// createDatabaseCredentialSecret returns the complete credential Secret of the read and write users of a database
+func createDatabaseCredentialSecret(namespace, dbName, instance string) *v1.Secret {
+	read, write := getUserRoleNames(dbName)
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: getUsersCredentialSecret(dbName, instance), Namespace: namespace},
+		Data:       map[string][]byte{read: []byte("read"), write: []byte("write")},
+	}
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// secretIndex indexes resources by the "<namespace>/<name>" keys of the Secrets they reference
+	secretIndex = "stardog.vshn.ch/secrets"
+	// instanceIndex indexes resources by the "<namespace>/<name>" keys of the StardogInstances they reference
+	instanceIndex = "stardog.vshn.ch/stardogInstances"
+)
+
+// objectKey returns the key of an object in the secretIndex and instanceIndex
+func objectKey(namespace, name string) string {
+	return types.NamespacedName{Namespace: namespace, Name: name}.String()
+}
+
+// indexInstanceSecrets returns the keys of the admin credentials and TLS Secrets of a StardogInstance
+func indexInstanceSecrets(obj client.Object) []string {
+	instance := obj.(*StardogInstance)
+	namespaceOrDefault := func(namespace string) string {
+		if namespace == "" {
+			return instance.Namespace
+		}
+		return namespace
+	}
+
+	keys := []string{objectKey(namespaceOrDefault(instance.Spec.AdminCredentials.Namespace), instance.Spec.AdminCredentials.SecretRef)}
+	if tls := instance.Spec.TLS; tls != nil {
+		if tls.CABundle != nil && tls.CABundle.SecretRef != "" {
+			keys = append(keys, objectKey(namespaceOrDefault(tls.CABundle.Namespace), tls.CABundle.SecretRef))
+		}
+		if tls.ClientCertificate != nil {
+			keys = append(keys, objectKey(namespaceOrDefault(tls.ClientCertificate.Namespace), tls.ClientCertificate.SecretRef))
+		}
+	}
+	return keys
+}
+
+// indexUserSecrets returns the key of the credential Secret of a StardogUser
+func indexUserSecrets(obj client.Object) []string {
+	user := obj.(*StardogUser)
+	namespace := user.Spec.Credentials.Namespace
+	if namespace == "" {
+		namespace = user.Namespace
+	}
+	return []string{objectKey(namespace, user.Spec.Credentials.SecretRef)}
+}
+
+// indexUserInstances returns the key of the StardogInstance of a StardogUser
+func indexUserInstances(obj client.Object) []string {
+	user := obj.(*StardogUser)
+	return []string{objectKey(user.Namespace, user.Spec.StardogInstanceRef)}
+}
+
+// indexRoleInstances returns the key of the StardogInstance of a StardogRole
+func indexRoleInstances(obj client.Object) []string {
+	role := obj.(*StardogRole)
+	return []string{objectKey(role.Namespace, role.Spec.StardogInstanceRef)}
+}
+
+// indexDatabaseInstances returns the keys of the StardogInstances of a Database
+func indexDatabaseInstances(obj client.Object) []string {
+	return instanceRefKeys(obj.(*v1beta1.Database).Spec.StardogInstanceRefs)
+}
+
+// indexOrganizationInstances returns the keys of the StardogInstances an Organization has been created on
+func indexOrganizationInstances(obj client.Object) []string {
+	return instanceRefKeys(obj.(*v1beta1.Organization).Status.StardogInstanceRefs)
+}
+
+func instanceRefKeys(refs []v1beta1.StardogInstanceRef) []string {
+	keys := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		keys = append(keys, objectKey(ref.Namespace, ref.Name))
+	}
+	return keys
+}
+
+// indexField registers the index function for the field of the given type
+func indexField(mgr ctrl.Manager, obj client.Object, field string, index client.IndexerFunc) error {
+	return mgr.GetFieldIndexer().IndexField(context.Background(), obj, field, index)
+}
+
+// requestsForIndex returns the requests of the objects of the list type whose index contains the value
+func requestsForIndex(ctx context.Context, c client.Client, list client.ObjectList, index, value string) []reconcile.Request {
+	if err := c.List(ctx, list, client.MatchingFields{index: value}); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list dependent resources", "index", index, "value", value)
+		return nil
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "cannot extract dependent resources", "index", index, "value", value)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+	}
+	return requests
+}
+
+// mapInstanceToDependents returns a MapFunc which enqueues the objects of the list type referencing a StardogInstance
+func mapInstanceToDependents(c client.Client, newList func() client.ObjectList) handler.MapFunc {
+	return func(ctx context.Context, instance client.Object) []reconcile.Request {
+		return requestsForIndex(ctx, c, newList(), instanceIndex, objectKey(instance.GetNamespace(), instance.GetName()))
+	}
+}
+
+// mapSecretToDependents returns a MapFunc which enqueues the objects of the list type referencing a StardogInstance
+// which uses a Secret
+func mapSecretToDependents(c client.Client, newList func() client.ObjectList) handler.MapFunc {
+	return func(ctx context.Context, secret client.Object) []reconcile.Request {
+		requests := make([]reconcile.Request, 0)
+		for _, instance := range requestsForIndex(ctx, c, &StardogInstanceList{}, secretIndex, objectKey(secret.GetNamespace(), secret.GetName())) {
+			requests = append(requests, requestsForIndex(ctx, c, newList(), instanceIndex, instance.String())...)
+		}
+		return requests
+	}
+}
+
+// watchInstanceDependents adds watches which enqueue the objects of the list type when a StardogInstance they
+// reference or one of the Secrets of the StardogInstance changes
+func watchInstanceDependents(b *builder.Builder, c client.Client, newList func() client.ObjectList) *builder.Builder {
+	return b.
+		Watches(&StardogInstance{}, handler.EnqueueRequestsFromMapFunc(mapInstanceToDependents(c, newList)),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(mapSecretToDependents(c, newList)))
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_mapSecretToDependents(t *testing.T) {
+	namespace := "namespace-test"
+
+	tests := []struct {
+		name     string
+		secret   client.Object
+		newList  func() client.ObjectList
+		expected []reconcile.Request
+	}{
+		{
+			name:     "GivenAdminSecretOfInstance_WhenMappingToUsers_ThenEnqueueUsersOfInstance",
+			secret:   createFullSecret(namespace, "admin-secret", "admin", "admin"),
+			newList:  func() client.ObjectList { return &v1alpha1.StardogUserList{} },
+			expected: []reconcile.Request{createRequest(namespace, "user")},
+		},
+		{
+			name:     "GivenAdminSecretOfInstance_WhenMappingToDatabases_ThenEnqueueDatabasesOfInstance",
+			secret:   createFullSecret(namespace, "admin-secret", "admin", "admin"),
+			newList:  func() client.ObjectList { return &v1beta1.DatabaseList{} },
+			expected: []reconcile.Request{createRequest("", "db")},
+		},
+		{
+			name:     "GivenUnreferencedSecret_WhenMappingToUsers_ThenEnqueueNothing",
+			secret:   createFullSecret(namespace, "other-secret", "admin", "admin"),
+			newList:  func() client.ObjectList { return &v1alpha1.StardogUserList{} },
+			expected: []reconcile.Request{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKubeClient, err := createIndexedKubeFakeClient(
+				createStardogInstance(namespace, "instance", "admin-secret", "https://stardog.example.com"),
+				createStardogInstance(namespace, "other-instance", "other-admin-secret", "https://other.example.com"),
+				createStardogUser(namespace, "user", "instance", "user-secret", nil),
+				createStardogUser(namespace, "other-user", "other-instance", "other-user-secret", nil),
+				createStardogDB("db", "", v1beta1.NewStardogInstanceRef("instance", namespace)),
+			)
+			assert.NoError(t, err)
+
+			requests := mapSecretToDependents(fakeKubeClient, tt.newList)(context.Background(), tt.secret)
+
+			assert.ElementsMatch(t, tt.expected, requests)
+		})
+	}
+}
+
+func Test_mapInstanceToDependents(t *testing.T) {
+	namespace := "namespace-test"
+
+	tests := []struct {
+		name     string
+		instance client.Object
+		newList  func() client.ObjectList
+		expected []reconcile.Request
+	}{
+		{
+			name:     "GivenInstance_WhenMappingToRoles_ThenEnqueueRolesOfInstance",
+			instance: createStardogInstance(namespace, "instance", "admin-secret", "https://stardog.example.com"),
+			newList:  func() client.ObjectList { return &v1alpha1.StardogRoleList{} },
+			expected: []reconcile.Request{createRequest(namespace, "role")},
+		},
+		{
+			name:     "GivenInstance_WhenMappingToOrganizations_ThenEnqueueOrganizationsCreatedOnInstance",
+			instance: createStardogInstance(namespace, "instance", "admin-secret", "https://stardog.example.com"),
+			newList:  func() client.ObjectList { return &v1beta1.OrganizationList{} },
+			expected: []reconcile.Request{createRequest("", "org")},
+		},
+		{
+			name:     "GivenUnreferencedInstance_WhenMappingToRoles_ThenEnqueueNothing",
+			instance: createStardogInstance(namespace, "other-instance", "admin-secret", "https://stardog.example.com"),
+			newList:  func() client.ObjectList { return &v1alpha1.StardogRoleList{} },
+			expected: []reconcile.Request{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := createOrg("org", "db", nil)
+			org.Status.StardogInstanceRefs = []v1beta1.StardogInstanceRef{v1beta1.NewStardogInstanceRef("instance", namespace)}
+			fakeKubeClient, err := createIndexedKubeFakeClient(
+				createStardogRole(namespace, "role", "instance", nil),
+				org,
+			)
+			assert.NoError(t, err)
+
+			requests := mapInstanceToDependents(fakeKubeClient, tt.newList)(context.Background(), tt.instance)
+
+			assert.ElementsMatch(t, tt.expected, requests)
+		})
+	}
+}
+
+func Test_indexInstanceSecrets(t *testing.T) {
+	instance := createStardogInstance("namespace-test", "instance", "admin-secret", "https://stardog.example.com")
+	instance.Spec.AdminCredentials.Namespace = ""
+	instance.Spec.TLS = &v1alpha1.StardogTLSSpec{
+		CABundle:          &v1alpha1.StardogCABundleSpec{SecretRef: "ca-bundle"},
+		ClientCertificate: &v1alpha1.StardogClientCertificateSpec{Namespace: "certs", SecretRef: "client-cert"},
+	}
+
+	keys := indexInstanceSecrets(instance)
+
+	assert.Equal(t, []string{"namespace-test/admin-secret", "namespace-test/ca-bundle", "certs/client-cert"}, keys)
+}
+
+func createIndexedKubeFakeClient(initObjs ...client.Object) (client.Client, error) {
+	err := v1alpha1.AddToScheme(scheme.Scheme)
+	err = v1beta1.AddToScheme(scheme.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(initObjs...).
+		WithIndex(&v1alpha1.StardogInstance{}, secretIndex, indexInstanceSecrets).
+		WithIndex(&v1alpha1.StardogUser{}, secretIndex, indexUserSecrets).
+		WithIndex(&v1alpha1.StardogUser{}, instanceIndex, indexUserInstances).
+		WithIndex(&v1alpha1.StardogRole{}, instanceIndex, indexRoleInstances).
+		WithIndex(&v1beta1.Database{}, instanceIndex, indexDatabaseInstances).
+		WithIndex(&v1beta1.Organization{}, instanceIndex, indexOrganizationInstances).
+		Build(), nil
+}
+
+func createRequest(namespace, name string) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	stardogv1beta1 "github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// databaseTriples reports the approximate number of triples of a database on a Stardog instance
+	databaseTriples = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "stardog_database_triples",
+		Help: "Approximate number of triples in the Stardog database",
+	}, []string{"namespace", "database", "instance"})
+	// databaseStatisticsRefreshTime reports when the statistics of a database on a Stardog instance have been refreshed
+	databaseStatisticsRefreshTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "stardog_database_statistics_refresh_timestamp_seconds",
+		Help: "Unix time the statistics of the Stardog database have been refreshed last",
+	}, []string{"namespace", "database", "instance"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(databaseTriples, databaseStatisticsRefreshTime)
+}
+
+// exportDatabaseStatistics sets the database metrics of the instance from the given status
+func exportDatabaseStatistics(dbName string, status stardogv1beta1.DatabaseInstanceStatus) {
+	if status.Triples == nil || status.StatisticsRefreshTime == nil {
+		return
+	}
+	instance := status.StardogInstanceRef
+	databaseTriples.WithLabelValues(instance.Namespace, dbName, instance.Name).Set(float64(*status.Triples))
+	databaseStatisticsRefreshTime.WithLabelValues(instance.Namespace, dbName, instance.Name).Set(float64(status.StatisticsRefreshTime.Unix()))
+}
+
+// deleteInstanceStatistics removes the database metrics of the instance, e.g. once it has been removed from the Database
+func deleteInstanceStatistics(dbName string, instance stardogv1beta1.StardogInstanceRef) {
+	labels := prometheus.Labels{"namespace": instance.Namespace, "database": dbName, "instance": instance.Name}
+	databaseTriples.DeletePartialMatch(labels)
+	databaseStatisticsRefreshTime.DeletePartialMatch(labels)
+}
+
+// deleteDatabaseStatistics removes the database metrics of all instances of the Database. Databases with the same name
+// on other instances keep their metrics.
+func deleteDatabaseStatistics(database *stardogv1beta1.Database) {
+	for _, instance := range database.Spec.StardogInstanceRefs {
+		deleteInstanceStatistics(database.Spec.DatabaseName, instance)
+	}
+}
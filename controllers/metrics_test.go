@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_deleteInstanceStatistics(t *testing.T) {
+	instance := v1beta1.NewStardogInstanceRef("instance-test", "namespace-test")
+	removedInstance := v1beta1.NewStardogInstanceRef("removed-instance-test", "namespace-test")
+	triples := int64(42)
+	refreshTime := metav1.Now()
+	defer deleteInstanceStatistics("db-metrics-test", instance)
+	for _, ref := range []v1beta1.StardogInstanceRef{instance, removedInstance} {
+		exportDatabaseStatistics("db-metrics-test", v1beta1.DatabaseInstanceStatus{StardogInstanceRef: ref, Triples: &triples, StatisticsRefreshTime: &refreshTime})
+	}
+
+	deleteInstanceStatistics("db-metrics-test", removedInstance)
+
+	assert.Equal(t, float64(42), testutil.ToFloat64(databaseTriples.WithLabelValues("namespace-test", "db-metrics-test", "instance-test")))
+	assert.False(t, databaseTriples.DeleteLabelValues("namespace-test", "db-metrics-test", "removed-instance-test"))
+	assert.False(t, databaseStatisticsRefreshTime.DeleteLabelValues("namespace-test", "db-metrics-test", "removed-instance-test"))
+}
+
+func Test_deleteDatabaseStatistics(t *testing.T) {
+	instance := v1beta1.NewStardogInstanceRef("instance-test", "namespace-test")
+	otherInstance := v1beta1.NewStardogInstanceRef("other-instance-test", "namespace-test")
+	triples := int64(42)
+	refreshTime := metav1.Now()
+	defer deleteInstanceStatistics("db-metrics-test", otherInstance)
+	for _, ref := range []v1beta1.StardogInstanceRef{instance, otherInstance} {
+		exportDatabaseStatistics("db-metrics-test", v1beta1.DatabaseInstanceStatus{StardogInstanceRef: ref, Triples: &triples, StatisticsRefreshTime: &refreshTime})
+	}
+	database := createStardogDB("db-metrics-test", "", instance)
+
+	deleteDatabaseStatistics(database)
+
+	assert.False(t, databaseTriples.DeleteLabelValues("namespace-test", "db-metrics-test", "instance-test"))
+	assert.False(t, databaseStatisticsRefreshTime.DeleteLabelValues("namespace-test", "db-metrics-test", "instance-test"))
+	assert.Equal(t, float64(42), testutil.ToFloat64(databaseTriples.WithLabelValues("namespace-test", "db-metrics-test", "other-instance-test")))
+}
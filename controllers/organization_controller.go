@@ -2,23 +2,16 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-logr/logr"
-	"github.com/go-openapi/runtime"
 	stardogv1alpha1 "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
 	stardogv1beta1 "github.com/vshn/stardog-userrole-operator/api/v1beta1"
-	stardog "github.com/vshn/stardog-userrole-operator/stardogrest/client"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles_permissions"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	scheme "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/utils/pointer"
 	"k8s.io/utils/strings/slices"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,12 +29,14 @@ const orgFinalizer = "finalizer.stardog.organizations"
 // OrganizationReconciler reconciles a Organization object
 type OrganizationReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *scheme.Scheme
+	Log            logr.Logger
+	Scheme         *scheme.Scheme
+	StardogClients *StardogClientPool
 }
 
 //+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databases,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databases/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=stardog.vshn.ch,resources=databasemigrations,verbs=get;list;watch
 
 // Reconcile manages the Stardog resources for a Database object
 func (r *OrganizationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -58,9 +53,9 @@ func (r *OrganizationReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	or := &OrganizationReconciliation{
 		reconciliationContext: &ReconciliationContext{
-			context:       ctx,
-			conditions:    make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
-			stardogClient: stardog.NewHTTPClient(nil),
+			context:        ctx,
+			conditions:     make(map[stardogv1alpha1.StardogConditionType]stardogv1alpha1.StardogCondition),
+			stardogClients: r.StardogClients,
 		},
 		resource: organization,
 	}
@@ -74,7 +69,7 @@ func (r *OrganizationReconciler) reconcileOrganization(or *OrganizationReconcili
 
 	r.Log.Info("reconciling", getLoggingKeysAndValuesForOrganization(organization)...)
 
-	if err := r.validateSpecification(or.reconciliationContext.context, organization); err != nil {
+	if err := r.validateSpecification(or); err != nil {
 		r.Log.Error(err, "Specification cannot be validated")
 		rc.SetStatusCondition(createStatusConditionInvalid(err))
 		rc.SetStatusCondition(createStatusConditionReady(false, "Specification cannot be validated"))
@@ -92,14 +87,37 @@ func (r *OrganizationReconciler) reconcileOrganization(or *OrganizationReconcili
 	isStardogOrganizationMarkedToBeDeleted := organization.GetDeletionTimestamp() != nil
 	if isStardogOrganizationMarkedToBeDeleted {
 		if err := r.deleteOrganizations(or); err != nil {
-			r.Log.Error(err, "Organization cannot be deleted")
-			rc.SetStatusCondition(createStatusConditionTerminating(err))
+			if errors.Is(err, errDeletionProtected) {
+				r.Log.Info("Organization is protected from deletion", getLoggingKeysAndValuesForOrganization(organization)...)
+				rc.SetStatusCondition(createStatusConditionDeletionBlocked(err))
+			} else {
+				r.Log.Error(err, "Organization cannot be deleted")
+				rc.SetStatusCondition(createStatusConditionTerminating(err))
+			}
 			rc.SetStatusCondition(createStatusConditionReady(false, "Organization cannot be deleted"))
 			return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(or)
 		}
 		return ctrl.Result{Requeue: false}, nil
 	}
 
+	if err := r.moveOrganization(or); err != nil {
+		r.Log.Error(err, "Cannot remove organization from previous database")
+		rc.SetStatusCondition(createStatusConditionErrored(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Cannot remove organization from previous database"))
+		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(or)
+	}
+
+	rotate, err := isCredentialRotationDue(organization.Spec.CredentialRotation, organization.Status.LastCredentialRotation, organization.CreationTimestamp)
+	if err != nil {
+		r.Log.Error(err, "Cannot determine whether the credentials have to be rotated")
+		rc.SetStatusCondition(createStatusConditionInvalid(err))
+		rc.SetStatusCondition(createStatusConditionReady(false, "Specification cannot be validated"))
+		return ctrl.Result{Requeue: false}, r.updateStatus(or)
+	}
+	if rotate {
+		or.credentialRotation = newCredentialRotation()
+	}
+
 	if err := r.syncOrganization(or); err != nil {
 		r.Log.Error(err, "Synchronization failed")
 		rc.SetStatusCondition(createStatusConditionErrored(err))
@@ -118,8 +136,14 @@ func (r *OrganizationReconciler) reconcileOrganization(or *OrganizationReconcili
 		return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(or)
 	}
 
+	or.resource.Status.Name = or.resource.Spec.Name
+	or.resource.Status.DisplayName = or.resource.Spec.DisplayName
+	or.resource.Status.DatabaseRef = or.resource.Spec.DatabaseRef
 	or.resource.Status.StardogInstanceRefs = or.database.Status.StardogInstanceRefs
 	or.resource.Status.NamedGraphs = or.resource.Spec.NamedGraphs
+	if or.credentialRotation != nil {
+		or.resource.Status.LastCredentialRotation = &or.credentialRotation.time
+	}
 	rc.SetStatusCondition(createStatusConditionReady(true, "Synchronized"))
 	return ctrl.Result{Requeue: true, RequeueAfter: ReconFreq}, r.updateStatus(or)
 }
@@ -141,10 +165,11 @@ func (r *OrganizationReconciler) updateStatus(or *OrganizationReconciliation) er
 	return nil
 }
 
-func (r *OrganizationReconciler) validateSpecification(ctx context.Context, organization *stardogv1beta1.Organization) error {
-	r.Log.V(1).Info("validating StardogRoleSpec")
-	spec := &organization.Spec
-	status := &organization.Status
+func (r *OrganizationReconciler) validateSpecification(or *OrganizationReconciliation) error {
+	r.Log.V(1).Info("validating OrganizationSpec")
+	organization := or.resource
+	spec := organization.Spec
+	status := organization.Status
 
 	if len(spec.NamedGraphs) == 0 {
 		return fmt.Errorf(".spec.NamedGraphs is required to have at least one graph")
@@ -159,25 +184,77 @@ func (r *OrganizationReconciler) validateSpecification(ctx context.Context, orga
 		return fmt.Errorf(".spec.DisplayName is required")
 	}
 
-	// If status is not set for organization name then we treat it as a creation (first object reconciliation)
-	if status.Name == "" {
-		status.Name = spec.Name
-		status.NamedGraphs = spec.NamedGraphs
-		status.DatabaseRef = spec.DatabaseRef
-
-		err := r.Client.Status().Update(ctx, organization)
-		if err != nil {
-			r.Log.Error(err, "Could not update Organization Status", getLoggingKeysAndValuesForOrganization(organization)...)
-			return err
+	// The API server rejects changes to immutable fields, an object whose spec differs from the synchronized status
+	// has bypassed the validation.
+	if status.Name != "" && status.Name != spec.Name {
+		return fmt.Errorf(".spec.Name is immutable, cannot change it from %s to %s", status.Name, spec.Name)
+	}
+	// Only a DatabaseMigration moves an organization to another database
+	if status.DatabaseRef != "" && status.DatabaseRef != spec.DatabaseRef {
+		if spec.DatabaseMigrationRef == "" {
+			return fmt.Errorf(".spec.DatabaseRef is immutable, cannot change it from %s to %s", status.DatabaseRef, spec.DatabaseRef)
 		}
+		return r.validateMigration(or)
 	}
 
-	spec.Name = status.Name
-	spec.DatabaseRef = status.DatabaseRef
+	return nil
+}
 
+// validateMigration checks that the DatabaseMigration referenced by the organization moves it from the database it
+// has been synchronized with to its current databaseRef. The webhook does the same on admission, but it may be disabled.
+func (r *OrganizationReconciler) validateMigration(or *OrganizationReconciliation) error {
+	organization := or.resource
+	spec := organization.Spec
+	previousRef := organization.Status.DatabaseRef
+
+	migration := &stardogv1beta1.DatabaseMigration{}
+	err := r.Client.Get(or.reconciliationContext.context, types.NamespacedName{Name: spec.DatabaseMigrationRef}, migration)
+	if err != nil {
+		return fmt.Errorf(".spec.DatabaseRef is immutable, cannot change it from %s to %s: cannot get DatabaseMigration %s: %v",
+			previousRef, spec.DatabaseRef, spec.DatabaseMigrationRef, err)
+	}
+	if migration.Spec.SourceDatabaseRef != previousRef || migration.Spec.Target.DatabaseRef != spec.DatabaseRef {
+		return fmt.Errorf(".spec.DatabaseRef is immutable, cannot change it from %s to %s: DatabaseMigration %s does not migrate %s to %s",
+			previousRef, spec.DatabaseRef, spec.DatabaseMigrationRef, previousRef, spec.DatabaseRef)
+	}
 	return nil
 }
 
+// moveOrganization removes the organization from the database it has been synchronized with, if a DatabaseMigration
+// changed its databaseRef
+func (r *OrganizationReconciler) moveOrganization(or *OrganizationReconciliation) error {
+	org := or.resource
+	previousRef := org.Status.DatabaseRef
+	if previousRef == "" || previousRef == org.Spec.DatabaseRef {
+		return nil
+	}
+	r.Log.Info("moving organization to another database", "organization", org.Name, "from", previousRef, "to", org.Spec.DatabaseRef)
+
+	previous := &stardogv1beta1.Database{}
+	err := r.Client.Get(or.reconciliationContext.context, types.NamespacedName{Name: previousRef}, previous)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("cannot get previous database %s for organization %s: %v", previousRef, org.Name, err)
+	}
+	if err == nil {
+		previousOr := &OrganizationReconciliation{
+			database:              previous,
+			resource:              org,
+			reconciliationContext: or.reconciliationContext,
+		}
+		for _, instance := range org.Status.StardogInstanceRefs {
+			if err := r.deleteOrganization(previousOr, instance); err != nil {
+				return fmt.Errorf("cannot delete organization %s from database %s for instance %s: %v", org.Name, previousRef, instance.Name, err)
+			}
+		}
+	}
+
+	// The organization has no graphs in the new database yet
+	org.Status.DatabaseRef = org.Spec.DatabaseRef
+	org.Status.StardogInstanceRefs = nil
+	org.Status.NamedGraphs = nil
+	return r.updateStatus(or)
+}
+
 func (r *OrganizationReconciler) syncOrganization(or *OrganizationReconciliation) error {
 	dbInstances := or.database.Spec.StardogInstanceRefs
 	orgInstances := or.resource.Status.StardogInstanceRefs
@@ -201,13 +278,13 @@ func (r *OrganizationReconciler) syncOrganization(or *OrganizationReconciliation
 
 func (r *OrganizationReconciler) sync(or *OrganizationReconciliation, instance stardogv1beta1.StardogInstanceRef) error {
 	rc := or.reconciliationContext
+	ctx := rc.context
 	database := or.database
 	dbName := database.Spec.DatabaseName
 	org := or.resource
 	orgName := org.Spec.Name
-	stardogClient := or.reconciliationContext.stardogClient
 
-	auth, disabled, err := rc.initStardogClientFromRef(r.Client, instance)
+	stardogClient, disabled, err := rc.initStardogClientFromRef(r.Client, instance)
 	if err != nil {
 		return fmt.Errorf("cannot initialize stardog client: %v", err)
 	}
@@ -217,38 +294,57 @@ func (r *OrganizationReconciler) sync(or *OrganizationReconciliation, instance s
 	}
 
 	// Generate and save credentials in k8s
-	secretName := getUsersCredentialSecret(dbName, orgName)
-	userRoleName := getUserAndRoleName(dbName, orgName)
+	secrets, err := r.credentialSecrets(or, instance)
 	if err != nil {
 		return err
 	}
+	userRoleName := getUserAndRoleName(dbName, orgName)
 
 	// create default write user for organization
 	pass, err := generatePassword()
 	if err != nil {
 		return err
 	}
-	usr := models.User{
-		Password: []string{pass}, Username: &userRoleName,
+	usr := stardogapi.UserCredentials{
+		Name: userRoleName, Password: pass,
 	}
-	usrs, err := createDefaultUsersForDB(stardogClient, auth, []models.User{usr})
+	usrs, err := createDefaultUsersForDB(ctx, stardogClient, []stardogapi.UserCredentials{usr})
 	if err != nil {
-		r.Log.Error(err, "error creating users", "users", usrs)
+		r.Log.Error(err, "error creating users", "users", getUserNames(usrs))
 		return err
 	}
 	if len(usrs) != 0 {
-		err = r.createCredentials(or, secretName, usrs)
+		err = r.createCredentials(or, secrets, usrs)
 		if err != nil {
-			r.Log.Error(err, "error creating secret credentials", "users", usrs)
+			r.Log.Error(err, "error creating secret credentials", "users", getUserNames(usrs))
 			return err
 		}
 	}
 
-	// create default read and write roles
-	rolenames := []models.Rolename{
-		{Rolename: &userRoleName},
+	recovered, err := recoverCredentials(ctx, r.Client, stardogClient, secrets, []string{userRoleName})
+	if err != nil {
+		return err
+	}
+	if len(recovered) > 0 {
+		r.Log.Info("recovered credential secret", "namespace", rc.namespace, "name", secrets.name, "users", recovered)
+		rc.setCredentialsRecovered(rc.namespace, secrets.name, recovered)
+	}
+
+	if or.credentialRotation != nil && len(usrs) == 0 {
+		err = rotatePasswords(ctx, r.Client, stardogClient, or.credentialRotation, secrets, []string{userRoleName})
+		if err != nil {
+			return fmt.Errorf("cannot rotate credentials: %v", err)
+		}
+		err = restartDeployments(ctx, r.Client, rc.namespace, org.Spec.CredentialRotation.RolloutSelector, or.credentialRotation.time.Time)
+		if err != nil {
+			return err
+		}
+		r.Log.Info("rotated credentials", "namespace", rc.namespace, "secret", secrets.name, "users", []string{userRoleName})
 	}
-	err = createDefaultRolesForDB(stardogClient, auth, rolenames)
+
+	// create default read and write roles
+	rolenames := []string{userRoleName}
+	err = createDefaultRolesForDB(ctx, stardogClient, rolenames)
 	if err != nil {
 		r.Log.Error(err, "Cannot create roles", "roles", rolenames)
 		return err
@@ -256,21 +352,21 @@ func (r *OrganizationReconciler) sync(or *OrganizationReconciliation, instance s
 
 	//create read and write permissions for user roles
 	perms := getOrganizationPerms(database, org, true, false)
-	err = createDefaultPermissions(stardogClient, auth, userRoleName, perms)
+	err = createDefaultPermissions(ctx, stardogClient, userRoleName, perms)
 	if err != nil {
 		r.Log.Error(err, "Adding permission to role failed", "role", userRoleName, "permission", perms)
 		return err
 	}
 
 	// Remove permissions in case name graphs have been removed
-	err = removePermissions(org, database, stardogClient, auth, userRoleName)
+	err = removePermissions(ctx, org, database, stardogClient, userRoleName)
 	if err != nil {
 		r.Log.Error(err, "Cannot remove permissions")
 		return err
 	}
 
 	// assign role to user
-	err = assignDefaultRole(stardogClient, auth, userRoleName)
+	err = assignDefaultRole(ctx, stardogClient, userRoleName)
 	if err != nil {
 		r.Log.Error(err, "Cannot assign defaultRoles", "user", userRoleName)
 		return err
@@ -280,13 +376,13 @@ func (r *OrganizationReconciler) sync(or *OrganizationReconciliation, instance s
 	roleNameCustomUser := database.Spec.AddUserForNonHiddenGraphs
 	if roleNameCustomUser != "" {
 		permsCustomUser := getOrganizationPerms(database, org, false, true)
-		err = createDefaultPermissions(stardogClient, auth, roleNameCustomUser, permsCustomUser)
+		err = createDefaultPermissions(ctx, stardogClient, roleNameCustomUser, permsCustomUser)
 		if err != nil {
 			r.Log.Error(err, "Adding permission to role failed", "role", roleNameCustomUser, "permission", permsCustomUser)
 			return err
 		}
 
-		err = adjustPermissionsForCustomUser(org, database, stardogClient, auth, roleNameCustomUser)
+		err = adjustPermissionsForCustomUser(ctx, org, database, stardogClient, roleNameCustomUser)
 		if err != nil {
 			r.Log.Error(err, "Cannot remove permissions")
 			return err
@@ -296,59 +392,54 @@ func (r *OrganizationReconciler) sync(or *OrganizationReconciliation, instance s
 	return nil
 }
 
-func assignDefaultRole(stardogClient *stardog.Stardog, auth runtime.ClientAuthInfoWriter, userRoleName string) error {
-	readUserRolesResp, err := stardogClient.UsersRoles.ListUserRoles(users_roles.NewListUserRolesParams().WithUser(userRoleName), auth)
-	if err != nil || !readUserRolesResp.IsSuccess() {
+func assignDefaultRole(ctx context.Context, stardogClient stardogapi.StardogAPI, userRoleName string) error {
+	userRoles, err := stardogClient.GetUserRoles(ctx, userRoleName)
+	if err != nil {
 		return fmt.Errorf("error getting user roles for user %s; %v", userRoleName, err)
 	}
 
-	if !slices.Contains(readUserRolesResp.Payload.Roles, userRoleName) {
-		params := users_roles.NewAddRoleParams().
-			WithUser(userRoleName).
-			WithRole(&models.Rolename{Rolename: &userRoleName})
-		roleResp, err := stardogClient.UsersRoles.AddRole(params, auth)
-		if err != nil || !roleResp.IsSuccess() {
+	if !slices.Contains(userRoles, userRoleName) {
+		err = stardogClient.AddUserRole(ctx, userRoleName, userRoleName)
+		if err != nil {
 			return fmt.Errorf("error assigning role %s to user %s: %v", userRoleName, userRoleName, err)
 		}
 	}
 	return nil
 }
 
-func adjustPermissionsForCustomUser(org *stardogv1beta1.Organization, database *stardogv1beta1.Database, stardogClient *stardog.Stardog, auth runtime.ClientAuthInfoWriter, userRoleName string) error {
+func adjustPermissionsForCustomUser(ctx context.Context, org *stardogv1beta1.Organization, database *stardogv1beta1.Database, stardogClient stardogapi.StardogAPI, userRoleName string) error {
 	for _, statusGraph := range org.Status.NamedGraphs {
 		statusGraphName := statusGraph.Name
-		perm := roles_permissions.NewRemoveRolePermissionParams().WithRole(userRoleName)
 		if !contains(stardogv1beta1.GetNamedGraphNames(org.Spec.NamedGraphs), statusGraph.Name) {
-			return removePermissionForCustomUser(org, database, stardogClient, auth, perm, statusGraphName)
+			return removePermissionForCustomUser(ctx, org, database, stardogClient, userRoleName, statusGraphName)
 		}
 	}
 	return nil
 }
 
-func removePermissionForCustomUser(org *stardogv1beta1.Organization, database *stardogv1beta1.Database, stardogClient *stardog.Stardog, auth runtime.ClientAuthInfoWriter, perm *roles_permissions.RemoveRolePermissionParams, statusGraphName string) error {
+func removePermissionForCustomUser(ctx context.Context, org *stardogv1beta1.Organization, database *stardogv1beta1.Database, stardogClient stardogapi.StardogAPI, userRoleName, statusGraphName string) error {
 	ng := getFullNamedGraph(org.Spec.Name, database.Spec.NamedGraphPrefix, statusGraphName, false)
 	resources := []string{ng, database.Spec.DatabaseName}
 	sort.Strings(resources)
-	p := models.Permission{
-		Action:       pointer.String("READ"),
-		Resource:     resources,
-		ResourceType: pointer.String("named-graph"),
+	p := stardogapi.Permission{
+		Action:       "READ",
+		Resources:    resources,
+		ResourceType: "named-graph",
 	}
-	pResp, err := stardogClient.RolesPermissions.RemoveRolePermission(perm.WithPermission(&p), auth)
-	if err != nil || !pResp.IsSuccess() {
+	err := stardogClient.DeleteRolePermission(ctx, userRoleName, p)
+	if err != nil {
 		return fmt.Errorf("cannot remove permission %+v for graph %s: %v", p, ng, err)
 	}
 	return nil
 }
 
-func removePermissions(org *stardogv1beta1.Organization, database *stardogv1beta1.Database, stardogClient *stardog.Stardog, auth runtime.ClientAuthInfoWriter, userRoleName string) error {
+func removePermissions(ctx context.Context, org *stardogv1beta1.Organization, database *stardogv1beta1.Database, stardogClient stardogapi.StardogAPI, userRoleName string) error {
 	for _, statusGraph := range org.Status.NamedGraphs {
-		perm := roles_permissions.NewRemoveRolePermissionParams().WithRole(userRoleName)
 		if !contains(stardogv1beta1.GetNamedGraphNames(org.Spec.NamedGraphs), statusGraph.Name) {
 			ng := getFullNamedGraph(org.Spec.Name, database.Spec.NamedGraphPrefix, statusGraph.Name, false)
 			for _, p := range getGraphPermissionForNameGraphs(ng, database.Spec.DatabaseName) {
-				pResp, err := stardogClient.RolesPermissions.RemoveRolePermission(perm.WithPermission(&p), auth)
-				if err != nil || !pResp.IsSuccess() {
+				err := stardogClient.DeleteRolePermission(ctx, userRoleName, p)
+				if err != nil {
 					return fmt.Errorf("cannot remove permission %+v for graph %s of role %s: %v", p, ng, userRoleName, err)
 				}
 			}
@@ -357,8 +448,8 @@ func removePermissions(org *stardogv1beta1.Organization, database *stardogv1beta
 			if statusGraph.AddHidden {
 				ngh := getFullNamedGraph(org.Spec.Name, database.Spec.NamedGraphPrefix, statusGraph.Name, true)
 				for _, p := range getGraphPermissionForNameGraphs(ngh, database.Spec.DatabaseName) {
-					pResp, err := stardogClient.RolesPermissions.RemoveRolePermission(perm.WithPermission(&p), auth)
-					if err != nil || !pResp.IsSuccess() {
+					err := stardogClient.DeleteRolePermission(ctx, userRoleName, p)
+					if err != nil {
 						return fmt.Errorf("cannot remove permission %+v for graph %s of user %s: %v", p, ngh, userRoleName, err)
 					}
 				}
@@ -372,8 +463,8 @@ func removePermissions(org *stardogv1beta1.Organization, database *stardogv1beta
 			if specGraph.AddHidden == false && statusGraph.AddHidden == true {
 				ngh := getFullNamedGraph(org.Spec.Name, database.Spec.NamedGraphPrefix, statusGraph.Name, true)
 				for _, p := range getGraphPermissionForNameGraphs(ngh, database.Spec.DatabaseName) {
-					pResp, err := stardogClient.RolesPermissions.RemoveRolePermission(perm.WithPermission(&p), auth)
-					if err != nil || !pResp.IsSuccess() {
+					err := stardogClient.DeleteRolePermission(ctx, userRoleName, p)
+					if err != nil {
 						return fmt.Errorf("cannot remove permission %+v for graph %s of role %s: %v", p, ngh, userRoleName, err)
 					}
 				}
@@ -401,6 +492,9 @@ func (r *OrganizationReconciler) getDatabaseRef(or *OrganizationReconciliation)
 func (r *OrganizationReconciler) deleteOrganizations(or *OrganizationReconciliation) error {
 	instances := or.database.Status.StardogInstanceRefs
 	org := or.resource
+	if stardogv1alpha1.IsDeletionProtected(org) {
+		return errDeletionProtected
+	}
 	r.Log.Info(fmt.Sprintf("deleting organization %s for each Stardog instances %s", or.resource.Name, instances))
 
 	for _, instance := range instances {
@@ -424,7 +518,7 @@ func (r *OrganizationReconciler) deleteOrganization(or *OrganizationReconciliati
 	orgName := org.Spec.Name
 
 	r.Log.V(1).Info("setup Stardog Client from ", "ref", instance)
-	auth, disabled, err := or.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+	stardogClient, disabled, err := or.reconciliationContext.initStardogClientFromRef(r.Client, instance)
 	if err != nil {
 		return fmt.Errorf("cannot initialize stardog client: %v", err)
 
@@ -434,16 +528,15 @@ func (r *OrganizationReconciler) deleteOrganization(or *OrganizationReconciliati
 		return nil
 	}
 
-	stardogClient := or.reconciliationContext.stardogClient
+	ctx := or.reconciliationContext.context
 	dbName := database.Spec.DatabaseName
 
 	userRoleName := getUserAndRoleName(dbName, orgName)
 
 	// Remove all permissions
-	permParam := roles_permissions.NewRemoveRolePermissionParams().WithRole(userRoleName)
 	for _, p := range getOrganizationPerms(database, org, true, false) {
-		_, err = stardogClient.RolesPermissions.RemoveRolePermission(permParam.WithPermission(&p), auth)
-		if err != nil && !NotFound(err) {
+		err = stardogClient.DeleteRolePermission(ctx, userRoleName, p)
+		if err != nil && !stardogapi.IsNotFound(err) {
 			return fmt.Errorf("cannot remove permission %#v of role %s: %v", p, userRoleName, err)
 		}
 	}
@@ -453,91 +546,77 @@ func (r *OrganizationReconciler) deleteOrganization(or *OrganizationReconciliati
 	if customUser != "" {
 		for _, statusGraph := range org.Status.NamedGraphs {
 			statusGraphName := statusGraph.Name
-			perm := roles_permissions.NewRemoveRolePermissionParams().WithRole(customUser)
-			return removePermissionForCustomUser(org, database, stardogClient, auth, perm, statusGraphName)
+			return removePermissionForCustomUser(ctx, org, database, stardogClient, customUser, statusGraphName)
 		}
 	}
 
 	// Remove assigned role to user
-	param := users_roles.NewRemoveRoleOfUserParams()
-	_, err = stardogClient.UsersRoles.RemoveRoleOfUser(param.WithUser(userRoleName).WithRole(userRoleName), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteUserRole(ctx, userRoleName, userRoleName)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove assigned role %s from user %s: %v", userRoleName, userRoleName, err)
 	}
 
 	// Remove role
-	roleParam := roles.NewRemoveRoleParams()
-	_, err = stardogClient.Roles.RemoveRole(roleParam.WithRole(userRoleName), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteRole(ctx, userRoleName)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove role %s: %v", userRoleName, err)
 	}
 
 	// Remove read and write users
-	userParam := users.NewRemoveUserParams()
-	_, err = stardogClient.Users.RemoveUser(userParam.WithUser(userRoleName), auth)
-	if err != nil && !NotFound(err) {
+	err = stardogClient.DeleteUser(ctx, userRoleName)
+	if err != nil && !stardogapi.IsNotFound(err) {
 		return fmt.Errorf("cannot remove user %s: %v", userRoleName, err)
 	}
 
 	return nil
 }
 
-func (r *OrganizationReconciler) createCredentials(or *OrganizationReconciliation, secretName string, usrs []models.User) error {
+// credentialSecrets returns the credential Secrets of the user of the Organization on the given instance, including the
+// connection details if requested by the secret template
+func (r *OrganizationReconciler) credentialSecrets(or *OrganizationReconciliation, instance stardogv1beta1.StardogInstanceRef) (*credentialSecrets, error) {
 	org := or.resource
 	rc := or.reconciliationContext
-	ctx := rc.context
-	namespace := rc.namespace
-	secret := &v1.Secret{}
-	err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, secret)
-
-	if err == nil {
-		return nil
-	}
-
-	if !apierrors.IsNotFound(err) {
-		r.Log.Error(err, fmt.Sprintf("error getting Secret %s/%s", namespace, secretName))
-		return err
-	}
-
-	secret = &v1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: namespace,
-		}}
-	err = controllerutil.SetControllerReference(org, secret, r.Scheme)
-	if err != nil {
-		return err
+	dbName := or.database.Spec.DatabaseName
+	secrets := &credentialSecrets{
+		namespace: rc.namespace,
+		name:      getUsersCredentialSecret(dbName, org.Spec.Name),
+		owner:     org,
+		scheme:    r.Scheme,
+		template:  org.Spec.SecretTemplate,
+	}
+	if secrets.template != nil && secrets.template.ConnectionDetails {
+		connection, err := getConnectionDetails(rc.context, r.Client, instance, dbName)
+		if err != nil {
+			return nil, err
+		}
+		secrets.connection = connection
 	}
+	return secrets, nil
+}
 
-	secret.StringData = map[string]string{}
+func (r *OrganizationReconciler) createCredentials(or *OrganizationReconciliation, secrets *credentialSecrets, usrs []stardogapi.UserCredentials) error {
+	passwords := map[string]string{}
 	for _, u := range usrs {
-		secret.StringData[*u.Username] = u.Password[0]
-	}
-
-	err = r.Create(ctx, secret)
-	if err != nil && apierrors.IsAlreadyExists(err) {
-		err = r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: secret.Namespace}, secret)
-		if err != nil {
-			r.Log.Error(err, fmt.Sprintf("error creating secret %s/%s", namespace, secretName))
-			return fmt.Errorf("cannot get existing credentials from secret %s: %v", secretName, err)
-		}
-		return nil
+		passwords[u.Name] = u.Password
 	}
-	if err != nil {
-		r.Log.Error(err, fmt.Sprintf("error creating secret %s/%s", namespace, secretName))
+	if err := secrets.writePasswords(or.reconciliationContext.context, r.Client, passwords); err != nil {
 		return err
 	}
 
-	r.Log.Info("created credential secret", "namespace", secret.Namespace, "name", secret.Name)
+	r.Log.Info("stored credentials", "namespace", secrets.namespace, "secret", secrets.name, "users", getUserNames(usrs))
 	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *OrganizationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexField(mgr, &stardogv1beta1.Organization{}, instanceIndex, indexOrganizationInstances); err != nil {
+		return err
+	}
 	h := handler.EnqueueRequestsFromMapFunc(triggerOrgReconciliationFromDB(mgr.GetClient()))
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&stardogv1beta1.Organization{}).
-		Watches(&stardogv1beta1.Database{}, h).
+		Watches(&stardogv1beta1.Database{}, h)
+	return watchInstanceDependents(b, r.Client, func() client.ObjectList { return &stardogv1beta1.OrganizationList{} }).
 		Complete(r)
 }
 
@@ -574,39 +653,39 @@ func getUserAndRoleName(dbName, orgName string) string {
 	return fmt.Sprintf("%s-%s", dbName, orgName)
 }
 
-func getGraphPermissions(org *stardogv1beta1.Organization, namedGraphPrefix, dbName string, withHidden, readOnly bool) []models.Permission {
-	perms := make([]models.Permission, 0)
+func getGraphPermissions(org *stardogv1beta1.Organization, namedGraphPrefix, dbName string, withHidden, readOnly bool) []stardogapi.Permission {
+	perms := make([]stardogapi.Permission, 0)
 	orgName := org.Spec.Name
 	for _, ng := range org.Spec.NamedGraphs {
 		fullNameNG := getFullNamedGraph(orgName, namedGraphPrefix, ng.Name, false)
-		ngPerm := []models.Permission{
+		ngPerm := []stardogapi.Permission{
 			{
-				Action:       pointer.String("READ"),
-				Resource:     []string{dbName, fullNameNG},
-				ResourceType: pointer.String("named-graph"),
+				Action:       "READ",
+				Resources:    []string{dbName, fullNameNG},
+				ResourceType: "named-graph",
 			},
 		}
 		if !readOnly {
-			ngPerm = append(ngPerm, models.Permission{
-				Action:       pointer.String("WRITE"),
-				Resource:     []string{dbName, fullNameNG},
-				ResourceType: pointer.String("named-graph"),
+			ngPerm = append(ngPerm, stardogapi.Permission{
+				Action:       "WRITE",
+				Resources:    []string{dbName, fullNameNG},
+				ResourceType: "named-graph",
 			})
 		}
 		if withHidden && ng.AddHidden {
 			fullNameNGH := getFullNamedGraph(orgName, namedGraphPrefix, ng.Name, true)
-			ngPermHidden := []models.Permission{
+			ngPermHidden := []stardogapi.Permission{
 				{
-					Action:       pointer.String("READ"),
-					Resource:     []string{dbName, fullNameNGH},
-					ResourceType: pointer.String("named-graph"),
+					Action:       "READ",
+					Resources:    []string{dbName, fullNameNGH},
+					ResourceType: "named-graph",
 				},
 			}
 			if !readOnly {
-				ngPermHidden = append(ngPermHidden, models.Permission{
-					Action:       pointer.String("WRITE"),
-					Resource:     []string{dbName, fullNameNGH},
-					ResourceType: pointer.String("named-graph"),
+				ngPermHidden = append(ngPermHidden, stardogapi.Permission{
+					Action:       "WRITE",
+					Resources:    []string{dbName, fullNameNGH},
+					ResourceType: "named-graph",
 				})
 			}
 			perms = append(perms, ngPermHidden...)
@@ -623,24 +702,24 @@ func getFullNamedGraph(orgName, namedGraphPrefix, ng string, hidden bool) string
 	return strings.TrimSuffix(namedGraphPrefix, "/") + "/" + orgName + "/" + ng
 }
 
-func getGraphPermissionForNameGraphs(namedGraph, dbName string) []models.Permission {
+func getGraphPermissionForNameGraphs(namedGraph, dbName string) []stardogapi.Permission {
 	resources := []string{namedGraph, dbName}
 	sort.Strings(resources)
-	return []models.Permission{
+	return []stardogapi.Permission{
 		{
-			Action:       pointer.String("READ"),
-			Resource:     resources,
-			ResourceType: pointer.String("named-graph"),
+			Action:       "READ",
+			Resources:    resources,
+			ResourceType: "named-graph",
 		},
 		{
-			Action:       pointer.String("WRITE"),
-			Resource:     resources,
-			ResourceType: pointer.String("named-graph"),
+			Action:       "WRITE",
+			Resources:    resources,
+			ResourceType: "named-graph",
 		},
 	}
 }
 
-func getOrganizationPerms(database *stardogv1beta1.Database, org *stardogv1beta1.Organization, withHidden, readOnly bool) []models.Permission {
+func getOrganizationPerms(database *stardogv1beta1.Database, org *stardogv1beta1.Organization, withHidden, readOnly bool) []stardogapi.Permission {
 	db := database.Spec.DatabaseName
 	graphPerm := getGraphPermissions(org, database.Spec.NamedGraphPrefix, db, withHidden, readOnly)
 	dbReadPerm := getDBReadPermissions(db)
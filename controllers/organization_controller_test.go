@@ -3,21 +3,19 @@ package controllers
 import (
 	"context"
 	"github.com/go-logr/logr/testr"
-	"github.com/go-openapi/runtime"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
 	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles_permissions"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_roles"
-	stardogmock "github.com/vshn/stardog-userrole-operator/stardogrest/mocks"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"testing"
+	"time"
 )
 
 func Test_createHiddenGraph(t *testing.T) {
@@ -39,8 +37,7 @@ func Test_createHiddenGraph(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 	db := createStardogDB("test-db", "hidden-user", v1beta1.StardogInstanceRef{
 		Name:      stardogInstanceName,
 		Namespace: namespace,
@@ -57,7 +54,7 @@ func Test_createHiddenGraph(t *testing.T) {
 		stardogDB           *v1beta1.Database
 		stardogOrg          *v1beta1.Organization
 		or                  OrganizationReconciliation
-		expectedPermissions []models.Permission
+		expectedPermissions []stardogapi.Permission
 	}{
 		{
 			name:            "GivenReconciliationContext_WhenCreateOrgWithHiddenGraphs_ThenCreateHiddenGraphs",
@@ -69,26 +66,26 @@ func Test_createHiddenGraph(t *testing.T) {
 				database: db,
 				resource: org,
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 			},
-			expectedPermissions: []models.Permission{
+			expectedPermissions: []stardogapi.Permission{
 				{
-					Action:       &action,
-					Resource:     []string{"test-db"},
-					ResourceType: &resourceTypeDB,
+					Action:       action,
+					ResourceType: resourceTypeDB,
+					Resources:    []string{"test-db"},
 				},
 				{
-					Action:       &action,
-					Resource:     []string{"test-db"},
-					ResourceType: &resourceTypeMeta,
+					Action:       action,
+					ResourceType: resourceTypeMeta,
+					Resources:    []string{"test-db"},
 				},
 				{
-					Action:       &action,
-					Resource:     []string{"test-db", "https://graph.ch/org-test/graph1"},
-					ResourceType: &resourceTypeNG,
+					Action:       action,
+					ResourceType: resourceTypeNG,
+					Resources:    []string{"test-db", "https://graph.ch/org-test/graph1"},
 				},
 			},
 		},
@@ -105,41 +102,38 @@ func Test_createHiddenGraph(t *testing.T) {
 			}
 
 			stardogMocked.EXPECT().
-				SetTransport(gomock.Any()).
-				AnyTimes()
-			stardogMocked.EXPECT().
-				ListUsers(gomock.Any(), gomock.Any()).
-				Return(&users.ListUsersOK{Payload: &models.Users{}}, nil).
+				ListUsers(gomock.Any()).
+				Return([]string{}, nil).
 				Times(1)
 			stardogMocked.EXPECT().
-				CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
-				Return(users.NewCreateUserCreated(), nil)
+				AddUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+				Return(nil)
 			stardogMocked.EXPECT().
-				ListRoles(gomock.Any(), gomock.Any()).Times(1).
-				Return(&roles.ListRolesOK{Payload: &models.Roles{}}, nil)
+				GetRoles(gomock.Any()).Times(1).
+				Return([]string{}, nil)
 			stardogMocked.EXPECT().
-				CreateRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
-				Return(roles.NewCreateRoleCreated(), nil)
+				AddRole(gomock.Any(), gomock.Any()).Times(1).
+				Return(nil)
 			stardogMocked.EXPECT().
-				ListRolePermissions(gomock.Any(), gomock.Any()).AnyTimes().
-				Return(&roles_permissions.ListRolePermissionsOK{Payload: &models.Permissions{}}, nil)
+				GetRolePermissions(gomock.Any(), gomock.Any()).AnyTimes().
+				Return([]stardogapi.Permission{}, nil)
 			stardogMocked.EXPECT().
-				ListUserRoles(gomock.Any(), gomock.Any()).AnyTimes().
-				Return(&users_roles.ListUserRolesOK{Payload: &models.Roles{}}, nil)
+				GetUserRoles(gomock.Any(), gomock.Any()).AnyTimes().
+				Return([]string{}, nil)
 			stardogMocked.EXPECT().
-				AddRole(gomock.Any(), gomock.Any()).AnyTimes().
-				Return(users_roles.NewAddRoleNoContent(), nil)
+				AddUserRole(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+				Return(nil)
 
 			hiddenRoleExists := false
-			var permHiddenRole []models.Permission
+			var permHiddenRole []stardogapi.Permission
 			stardogMocked.EXPECT().
 				AddRolePermission(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(params *roles_permissions.AddRolePermissionParams, authInfo runtime.ClientAuthInfoWriter, opts ...users_roles.ClientOption) (*roles_permissions.AddRolePermissionCreated, error) {
-					if params.Role == "hidden-user" {
+				DoAndReturn(func(_ context.Context, role string, permission stardogapi.Permission) error {
+					if role == "hidden-user" {
 						hiddenRoleExists = true
-						permHiddenRole = append(permHiddenRole, *params.Permission)
+						permHiddenRole = append(permHiddenRole, permission)
 					}
-					return roles_permissions.NewAddRolePermissionCreated(), nil
+					return nil
 				}).AnyTimes()
 
 			_, err = r.reconcileOrganization(&tt.or)
@@ -162,3 +156,193 @@ func createOrg(name, dbRef string, ngs []v1beta1.NamedGraph) *v1beta1.Organizati
 		},
 	}
 }
+
+func Test_validateSpecificationOrganization(t *testing.T) {
+	graphs := []v1beta1.NamedGraph{{Name: "graph1"}}
+	migration := &v1beta1.DatabaseMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "migration-test"},
+		Spec: v1beta1.DatabaseMigrationSpec{
+			SourceDatabaseRef: "old-db",
+			Target:            v1beta1.DatabaseMigrationTarget{DatabaseRef: "db-test"},
+		},
+	}
+	otherMigration := &v1beta1.DatabaseMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-migration-test"},
+		Spec: v1beta1.DatabaseMigrationSpec{
+			SourceDatabaseRef: "other-db",
+			Target:            v1beta1.DatabaseMigrationTarget{DatabaseRef: "db-test"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		migration   string
+		status      v1beta1.OrganizationStatus
+		expectedErr bool
+	}{
+		{
+			name:        "GivenNewOrganization_WhenValidating_ThenNoError",
+			expectedErr: false,
+		},
+		{
+			name:        "GivenSynchronizedOrganization_WhenSpecIsUnchanged_ThenNoError",
+			status:      v1beta1.OrganizationStatus{Name: "org-test", DatabaseRef: "db-test"},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenSynchronizedOrganization_WhenNameChanged_ThenError",
+			status:      v1beta1.OrganizationStatus{Name: "old-org", DatabaseRef: "db-test"},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenSynchronizedOrganization_WhenDatabaseRefChanged_ThenError",
+			status:      v1beta1.OrganizationStatus{Name: "org-test", DatabaseRef: "old-db"},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenMigratedOrganization_WhenDatabaseRefChanged_ThenNoError",
+			migration:   "migration-test",
+			status:      v1beta1.OrganizationStatus{Name: "org-test", DatabaseRef: "old-db"},
+			expectedErr: false,
+		},
+		{
+			name:        "GivenUnknownMigration_WhenDatabaseRefChanged_ThenError",
+			migration:   "unknown-migration-test",
+			status:      v1beta1.OrganizationStatus{Name: "org-test", DatabaseRef: "old-db"},
+			expectedErr: true,
+		},
+		{
+			name:        "GivenMigrationOfOtherDatabase_WhenDatabaseRefChanged_ThenError",
+			migration:   "other-migration-test",
+			status:      v1beta1.OrganizationStatus{Name: "org-test", DatabaseRef: "old-db"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKubeClient, err := createKubeFakeClientWithSub(migration, otherMigration)
+			assert.NoError(t, err)
+			r := OrganizationReconciler{Client: fakeKubeClient, Log: testr.New(t)}
+			org := createOrg("org-test", "db-test", graphs)
+			org.Spec.DatabaseMigrationRef = tt.migration
+			org.Status = tt.status
+			or := &OrganizationReconciliation{
+				resource: org,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+				},
+			}
+
+			err = r.validateSpecification(or)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+			assert.Equal(t, "org-test", org.Spec.Name)
+		})
+	}
+}
+
+func Test_moveOrganization(t *testing.T) {
+	instances := []v1beta1.StardogInstanceRef{v1beta1.NewStardogInstanceRef("instance-test", "namespace-test")}
+	graphs := []v1beta1.NamedGraph{{Name: "graph1"}}
+
+	tests := []struct {
+		name           string
+		status         v1beta1.OrganizationStatus
+		expectedStatus v1beta1.OrganizationStatus
+	}{
+		{
+			name:           "GivenSynchronizedOrganization_WhenDatabaseRefIsUnchanged_ThenKeepStatus",
+			status:         v1beta1.OrganizationStatus{Name: "org-test", DatabaseRef: "db-test", NamedGraphs: graphs, StardogInstanceRefs: instances},
+			expectedStatus: v1beta1.OrganizationStatus{Name: "org-test", DatabaseRef: "db-test", NamedGraphs: graphs, StardogInstanceRefs: instances},
+		},
+		{
+			name:           "GivenMigratedOrganization_WhenPreviousDatabaseIsGone_ThenResetStatus",
+			status:         v1beta1.OrganizationStatus{Name: "org-test", DatabaseRef: "old-db", NamedGraphs: graphs, StardogInstanceRefs: instances},
+			expectedStatus: v1beta1.OrganizationStatus{Name: "org-test", DatabaseRef: "db-test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := createOrg("org-test", "db-test", graphs)
+			org.Spec.DatabaseMigrationRef = "migration-test"
+			org.Status = tt.status
+			fakeKubeClient, err := createKubeFakeClientWithSub(org)
+			assert.NoError(t, err)
+			r := OrganizationReconciler{Client: fakeKubeClient, Log: testr.New(t), Scheme: scheme.Scheme}
+			or := &OrganizationReconciliation{
+				resource: org,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+				},
+			}
+
+			err = r.moveOrganization(or)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus.DatabaseRef, org.Status.DatabaseRef)
+			assert.Equal(t, tt.expectedStatus.NamedGraphs, org.Status.NamedGraphs)
+			assert.Equal(t, tt.expectedStatus.StardogInstanceRefs, org.Status.StardogInstanceRefs)
+		})
+	}
+}
+
+func Test_reconcileOrganization(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		expectedDeleted bool
+		expectedBlocked bool
+	}{
+		{
+			name:            "GivenDeletionProtection_WhenDeleting_ThenBlockDeletion",
+			annotations:     map[string]string{v1alpha1.DeletionProtectionAnnotation: "true"},
+			expectedBlocked: true,
+		},
+		{
+			name:            "GivenNoDeletionProtection_WhenDeleting_ThenRemoveFinalizer",
+			expectedDeleted: true,
+		},
+		{
+			name:            "GivenDisabledDeletionProtection_WhenDeleting_ThenRemoveFinalizer",
+			annotations:     map[string]string{v1alpha1.DeletionProtectionAnnotation: "false"},
+			expectedDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := createStardogDB("db-test", "", v1beta1.NewStardogInstanceRef("instance-test", "namespace-test"))
+			org := createOrg("org-test", "db-test", []v1beta1.NamedGraph{{Name: "graph1"}})
+			org.Annotations = tt.annotations
+			org.Finalizers = []string{orgFinalizer}
+			org.DeletionTimestamp = &metav1.Time{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			fakeKubeClient, err := createKubeFakeClientWithSub(db, org)
+			assert.NoError(t, err)
+			r := OrganizationReconciler{Client: fakeKubeClient, Log: testr.New(t), Scheme: scheme.Scheme}
+			or := &OrganizationReconciliation{
+				resource: org,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+				},
+			}
+
+			_, err = r.reconcileOrganization(or)
+
+			assert.NoError(t, err)
+			actual := &v1beta1.Organization{}
+			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: "org-test"}, actual)
+			if tt.expectedDeleted {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Contains(t, actual.Finalizers, orgFinalizer)
+			assert.Equal(t, tt.expectedBlocked, isConditionTrue(actual.Status.Conditions, v1alpha1.StardogDeletionBlocked))
+		})
+	}
+}
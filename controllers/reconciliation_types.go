@@ -2,35 +2,74 @@ package controllers
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"github.com/go-openapi/runtime"
-	auth "github.com/go-openapi/runtime/client"
-	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
-	stardog "github.com/vshn/stardog-userrole-operator/stardogrest/client"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
 	"net/url"
+	"strings"
 
 	. "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// StardogClientFactory creates a StardogAPI client for the given StardogInstance with the given admin credentials.
+// tlsConfig is nil if the StardogInstance does not configure TLS.
+type StardogClientFactory func(instance StardogInstance, username, password string, tlsConfig *tls.Config) (stardogapi.StardogAPI, error)
+
 type ReconciliationContext struct {
-	context       context.Context
-	conditions    map[StardogConditionType]StardogCondition
-	stardogClient *stardog.Stardog
-	namespace     string
+	context        context.Context
+	conditions     map[StardogConditionType]StardogCondition
+	stardogClients *StardogClientPool
+	namespace      string
 }
 
 type OrganizationReconciliation struct {
 	database              *v1beta1.Database
 	resource              *v1beta1.Organization
+	credentialRotation    *credentialRotation
 	reconciliationContext *ReconciliationContext
 }
 
 type DatabaseReconciliation struct {
-	resource              *v1beta1.Database
+	resource           *v1beta1.Database
+	pendingOptions     []string
+	credentialRotation *credentialRotation
+	// instances contains the live state of the database on each synchronized instance
+	instances             []v1beta1.DatabaseInstanceStatus
+	reconciliationContext *ReconciliationContext
+}
+
+type DatabaseMigrationReconciliation struct {
+	resource              *v1beta1.DatabaseMigration
+	source                *v1beta1.Database
+	reconciliationContext *ReconciliationContext
+}
+
+type DatabaseBackupReconciliation struct {
+	resource              *v1beta1.DatabaseBackup
+	database              *v1beta1.Database
+	reconciliationContext *ReconciliationContext
+}
+
+type ArchivedDatabaseReconciliation struct {
+	resource              *v1beta1.ArchivedDatabase
+	reconciliationContext *ReconciliationContext
+}
+
+type DatabaseBackupScheduleReconciliation struct {
+	resource              *v1beta1.DatabaseBackupSchedule
+	reconciliationContext *ReconciliationContext
+}
+
+type DatabaseRestoreReconciliation struct {
+	resource              *v1beta1.DatabaseRestore
+	database              *v1beta1.Database
+	backup                *v1beta1.DatabaseBackup
 	reconciliationContext *ReconciliationContext
 }
 
@@ -47,6 +86,16 @@ type StardogRoleReconciliation struct {
 type StardogUserReconciliation struct {
 	resource              *StardogUser
 	reconciliationContext *ReconciliationContext
+	// username is set once the user exists in Stardog
+	username string
+	// appliedCredentials and lastRotationTime are set once the credentials have been applied to Stardog
+	appliedCredentials *CredentialsSecretVersion
+	lastRotationTime   *metav1.Time
+	// enabled is set once the enabled state has been applied to Stardog
+	enabled *bool
+	// effectivePermissions and effectivePermissionsRefreshTime are set once the effective permissions have been listed
+	effectivePermissions            []EffectivePermission
+	effectivePermissionsRefreshTime *metav1.Time
 }
 
 // SetStatusCondition adds the given condition to the status condition of the Stardog CRDs. Overwrites existing conditions
@@ -63,24 +112,216 @@ func (rc *ReconciliationContext) SetStatusIfExisting(conditionType StardogCondit
 	}
 }
 
-func (rc *ReconciliationContext) initStardogClient(kubeClient client.Client, stardogInstance StardogInstance) (runtime.ClientAuthInfoWriter, error) {
+// NewStardogClient is the default StardogClientFactory. It creates a stardogapi.Client for the server URL of the
+// given StardogInstance, honouring its scheme, port and base path.
+func NewStardogClient(instance StardogInstance, username, password string, tlsConfig *tls.Config) (stardogapi.StardogAPI, error) {
+	baseURL, err := getBaseURL(instance.Spec.ServerUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url from stardoginstance %s: %v", instance.Name, err)
+	}
+	if tlsConfig != nil {
+		return stardogapi.NewClientWithTLSConfig(username, password, baseURL, tlsConfig), nil
+	}
+
+	return stardogapi.NewClient(username, password, baseURL), nil
+}
+
+// getBaseURL returns the server URL without trailing slash, query or fragment, so that API paths can be appended to it.
+func getBaseURL(serverUrl string) (string, error) {
+	u, err := url.Parse(serverUrl)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme %q in %s", u.Scheme, serverUrl)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("missing host in %s", serverUrl)
+	}
+
+	baseURL := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   strings.TrimSuffix(u.Path, "/"),
+	}
+	return baseURL.String(), nil
+}
+
+// initStardogClient returns the pooled client of the given StardogInstance. A new client is only created if the
+// StardogInstance, its admin credentials or its TLS resources changed since the pooled client has been created.
+func (rc *ReconciliationContext) initStardogClient(kubeClient client.Client, stardogInstance StardogInstance) (stardogapi.StardogAPI, error) {
 	adminCredentials := stardogInstance.Spec.AdminCredentials
-	serverUrl := stardogInstance.Spec.ServerUrl
-	adminUsername, adminPassword, err := rc.getCredentials(kubeClient, adminCredentials, rc.namespace)
+	adminSecret, err := rc.getCredentialsSecret(kubeClient, adminCredentials, rc.namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	u, err := url.Parse(serverUrl)
-	if err != nil || u.Host == "" {
-		return nil, fmt.Errorf("invalid url from stardoginstance %s: %s", stardogInstance.Name, serverUrl)
+	tlsVersions, err := rc.getTLSResourceVersions(kubeClient, stardogInstance)
+	if err != nil {
+		return nil, err
 	}
 
-	rc.stardogClient.SetTransport(httptransport.New(u.Host, stardog.DefaultBasePath, stardog.DefaultSchemes))
-	return auth.BasicAuth(adminUsername, adminPassword), nil
+	version := strings.Join(append([]string{
+		string(stardogInstance.UID),
+		stardogInstance.ResourceVersion,
+		adminSecret.ResourceVersion,
+	}, tlsVersions...), "/")
+	instanceName := types.NamespacedName{Namespace: stardogInstance.Namespace, Name: stardogInstance.Name}
+
+	return rc.stardogClients.get(instanceName, version, func(factory StardogClientFactory) (stardogapi.StardogAPI, error) {
+		adminUsername, adminPassword, err := getUsernameAndPassword(*adminSecret, adminCredentials)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig, err := rc.getTLSConfig(kubeClient, stardogInstance)
+		if err != nil {
+			return nil, err
+		}
+
+		return factory(stardogInstance, adminUsername, adminPassword, tlsConfig)
+	})
 }
 
-func (rc *ReconciliationContext) initStardogClientFromRef(kubeClient client.Client, instance v1beta1.StardogInstanceRef) (runtime.ClientAuthInfoWriter, bool, error) {
+// getTLSResourceVersions returns the resource versions of the Secrets and ConfigMaps referenced in the tls block of the
+// StardogInstance
+func (rc *ReconciliationContext) getTLSResourceVersions(kubeClient client.Client, stardogInstance StardogInstance) ([]string, error) {
+	spec := stardogInstance.Spec.TLS
+	if spec == nil {
+		return nil, nil
+	}
+
+	var objects []client.Object
+	if spec.CABundle != nil {
+		namespace := spec.CABundle.Namespace
+		if namespace == "" {
+			namespace = stardogInstance.Namespace
+		}
+		if spec.CABundle.SecretRef != "" {
+			objects = append(objects, &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: spec.CABundle.SecretRef}})
+		}
+		if spec.CABundle.ConfigMapRef != "" {
+			objects = append(objects, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: spec.CABundle.ConfigMapRef}})
+		}
+	}
+	if spec.ClientCertificate != nil {
+		namespace := spec.ClientCertificate.Namespace
+		if namespace == "" {
+			namespace = stardogInstance.Namespace
+		}
+		objects = append(objects, &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: spec.ClientCertificate.SecretRef}})
+	}
+
+	versions := make([]string, 0, len(objects))
+	for _, object := range objects {
+		err := kubeClient.Get(rc.context, client.ObjectKeyFromObject(object), object)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve TLS resource %s/%s of StardogInstance %s/%s: %v",
+				object.GetNamespace(), object.GetName(), stardogInstance.Namespace, stardogInstance.Name, err)
+		}
+		versions = append(versions, object.GetResourceVersion())
+	}
+	return versions, nil
+}
+
+// getTLSConfig builds the TLS configuration from the tls block of the StardogInstance. Returns nil if there is none.
+func (rc *ReconciliationContext) getTLSConfig(kubeClient client.Client, stardogInstance StardogInstance) (*tls.Config, error) {
+	spec := stardogInstance.Spec.TLS
+	if spec == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         spec.ServerName,
+		InsecureSkipVerify: spec.InsecureSkipVerify,
+	}
+
+	if spec.CABundle != nil {
+		caBundle, err := rc.getCABundle(kubeClient, *spec.CABundle, stardogInstance.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM([]byte(caBundle)) {
+			return nil, fmt.Errorf("cannot parse CA bundle of StardogInstance %s/%s: no valid PEM encoded certificate found",
+				stardogInstance.Namespace, stardogInstance.Name)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if spec.ClientCertificate != nil {
+		certificate, err := rc.getClientCertificate(kubeClient, *spec.ClientCertificate, stardogInstance.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+func (rc *ReconciliationContext) getCABundle(kubeClient client.Client, caBundle StardogCABundleSpec, alternativeNamespace string) (string, error) {
+	namespace := caBundle.Namespace
+	if namespace == "" {
+		namespace = alternativeNamespace
+	}
+	key := caBundle.Key
+	if key == "" {
+		key = "ca.crt"
+	}
+
+	if caBundle.SecretRef != "" {
+		secret := &v1.Secret{}
+		err := kubeClient.Get(rc.context, types.NamespacedName{Namespace: namespace, Name: caBundle.SecretRef}, secret)
+		if err != nil {
+			return "", fmt.Errorf("cannot retrieve CA bundle from Secret %s/%s: %v", namespace, caBundle.SecretRef, err)
+		}
+		return getSecretData(*secret, key)
+	}
+
+	if caBundle.ConfigMapRef != "" {
+		configMap := &v1.ConfigMap{}
+		err := kubeClient.Get(rc.context, types.NamespacedName{Namespace: namespace, Name: caBundle.ConfigMapRef}, configMap)
+		if err != nil {
+			return "", fmt.Errorf("cannot retrieve CA bundle from ConfigMap %s/%s: %v", namespace, caBundle.ConfigMapRef, err)
+		}
+		if configMap.Data[key] == "" {
+			return "", fmt.Errorf(".data.%s in the ConfigMap %s/%s is required", key, namespace, caBundle.ConfigMapRef)
+		}
+		return configMap.Data[key], nil
+	}
+
+	return "", fmt.Errorf(".spec.tls.caBundle requires either secretRef or configMapRef")
+}
+
+func (rc *ReconciliationContext) getClientCertificate(kubeClient client.Client, clientCertificate StardogClientCertificateSpec, alternativeNamespace string) (tls.Certificate, error) {
+	namespace := clientCertificate.Namespace
+	if namespace == "" {
+		namespace = alternativeNamespace
+	}
+	secret := &v1.Secret{}
+	err := kubeClient.Get(rc.context, types.NamespacedName{Namespace: namespace, Name: clientCertificate.SecretRef}, secret)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("cannot retrieve client certificate from Secret %s/%s: %v", namespace, clientCertificate.SecretRef, err)
+	}
+
+	certificate, err := getSecretData(*secret, v1.TLSCertKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	key, err := getSecretData(*secret, v1.TLSPrivateKeyKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyPair, err := tls.X509KeyPair([]byte(certificate), []byte(key))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("cannot parse client certificate from Secret %s/%s: %v", namespace, clientCertificate.SecretRef, err)
+	}
+	return keyPair, nil
+}
+
+func (rc *ReconciliationContext) initStardogClientFromRef(kubeClient client.Client, instance v1beta1.StardogInstanceRef) (stardogapi.StardogAPI, bool, error) {
 	stardogInstance := &StardogInstance{}
 	err := kubeClient.Get(rc.context, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, stardogInstance)
 	if err != nil {
@@ -99,22 +340,34 @@ func (rc *ReconciliationContext) initStardogClientFromRef(kubeClient client.Clie
 }
 
 func (rc *ReconciliationContext) getCredentials(kubeClient client.Client, credentials StardogUserCredentialsSpec, alternativeNamespace string) (username, password string, err error) {
+	secret, err := rc.getCredentialsSecret(kubeClient, credentials, alternativeNamespace)
+	if err != nil {
+		return "", "", err
+	}
+
+	return getUsernameAndPassword(*secret, credentials)
+}
+
+func (rc *ReconciliationContext) getCredentialsSecret(kubeClient client.Client, credentials StardogUserCredentialsSpec, alternativeNamespace string) (*v1.Secret, error) {
 	secret := &v1.Secret{}
 	namespace := credentials.Namespace
 	if namespace == "" {
 		namespace = alternativeNamespace
 	}
-	err = kubeClient.Get(rc.context, types.NamespacedName{Namespace: namespace, Name: credentials.SecretRef}, secret)
+	err := kubeClient.Get(rc.context, types.NamespacedName{Namespace: namespace, Name: credentials.SecretRef}, secret)
 	if err != nil {
-		return "", "", fmt.Errorf("cannot retrieve credentials from Secret %s/%s: %v", namespace, credentials.SecretRef, err)
+		return nil, fmt.Errorf("cannot retrieve credentials from Secret %s/%s: %v", namespace, credentials.SecretRef, err)
 	}
+	return secret, nil
+}
 
-	username, err = getSecretData(*secret, "username")
+func getUsernameAndPassword(secret v1.Secret, credentials StardogUserCredentialsSpec) (username, password string, err error) {
+	username, err = getSecretData(secret, credentials.GetUsernameKey())
 	if err != nil {
 		return "", "", err
 	}
 
-	password, err = getSecretData(*secret, "password")
+	password, err = getSecretData(secret, credentials.GetPasswordKey())
 	if err != nil {
 		return "", "", err
 	}
@@ -2,12 +2,20 @@ package controllers
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
-	stardogmock "github.com/vshn/stardog-userrole-operator/stardogrest/mocks"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -35,8 +43,7 @@ func Test_initStardogClientFromRef(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name            string
@@ -68,14 +75,11 @@ func Test_initStardogClientFromRef(t *testing.T) {
 			assert.NoError(t, err)
 
 			rc := &ReconciliationContext{
-				context:       context.Background(),
-				conditions:    make(v1alpha1.StardogConditionMap),
-				namespace:     namespace,
-				stardogClient: stardogClient,
+				context:        context.Background(),
+				conditions:     make(v1alpha1.StardogConditionMap),
+				namespace:      namespace,
+				stardogClients: createStardogClientPoolFromMock(stardogMocked),
 			}
-			stardogMocked.EXPECT().
-				SetTransport(gomock.Any()).
-				AnyTimes()
 
 			base64.StdEncoding.EncodeToString([]byte(username))
 
@@ -115,6 +119,23 @@ func Test_getCredentials(t *testing.T) {
 			pass:   base64.StdEncoding.EncodeToString([]byte(password)),
 			err:    nil,
 		},
+		{
+			name:      "GivenKeyMapping_WhenCorrectCredentials_ThenGetUsernameAndPasswordFromMappedKeys",
+			namespace: *createNamespace(namespace),
+			credentials: stardogv1alpha1.StardogUserCredentialsSpec{
+				Namespace:   namespace,
+				SecretRef:   secretName,
+				UsernameKey: "user",
+				PasswordKey: "pass",
+			},
+			secret: v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+				Data:       map[string][]byte{"user": []byte(username), "pass": []byte(password)},
+			},
+			user: username,
+			pass: password,
+			err:  nil,
+		},
 		{
 			name:      "GivenCorrectSetup_WhenSecretDoesNotExist_ThenRaiseError",
 			namespace: *createNamespace(namespace),
@@ -137,10 +158,9 @@ func Test_getCredentials(t *testing.T) {
 			assert.NoError(t, err)
 
 			rc := &ReconciliationContext{
-				context:       context.Background(),
-				conditions:    make(v1alpha1.StardogConditionMap),
-				namespace:     namespace,
-				stardogClient: nil,
+				context:    context.Background(),
+				conditions: make(v1alpha1.StardogConditionMap),
+				namespace:  namespace,
 			}
 
 			user, pass, err := rc.getCredentials(fakeKubeClient, tt.credentials, alternativeNamespace)
@@ -151,6 +171,148 @@ func Test_getCredentials(t *testing.T) {
 	}
 }
 
+func Test_getBaseURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		serverUrl string
+		baseURL   string
+		err       error
+	}{
+		{
+			name:      "GivenServerUrl_WhenHttpWithPort_ThenKeepSchemeAndPort",
+			serverUrl: "http://stardog:5820",
+			baseURL:   "http://stardog:5820",
+			err:       nil,
+		},
+		{
+			name:      "GivenServerUrl_WhenHttpsWithBasePath_ThenKeepBasePathWithoutTrailingSlash",
+			serverUrl: "https://proxy.example.com/stardog/",
+			baseURL:   "https://proxy.example.com/stardog",
+			err:       nil,
+		},
+		{
+			name:      "GivenServerUrl_WhenSchemeIsNotSupported_ThenRaiseError",
+			serverUrl: "ftp://stardog:5820",
+			baseURL:   "",
+			err:       errors.New("unsupported scheme \"ftp\" in ftp://stardog:5820"),
+		},
+		{
+			name:      "GivenServerUrl_WhenHostIsMissing_ThenRaiseError",
+			serverUrl: "https:///stardog",
+			baseURL:   "",
+			err:       errors.New("missing host in https:///stardog"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, err := getBaseURL(tt.serverUrl)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.baseURL, baseURL)
+		})
+	}
+}
+
+func Test_getTLSConfig(t *testing.T) {
+	namespace := "namespace-test"
+	certificate, key := createCertificate(t)
+
+	tests := []struct {
+		name            string
+		tls             *stardogv1alpha1.StardogTLSSpec
+		objects         []runtime.Object
+		expectTLSConfig bool
+		expectRootCAs   bool
+		expectClientTLS bool
+		err             error
+	}{
+		{
+			name:            "GivenStardogInstance_WhenNoTLSBlock_ThenReturnNoTLSConfig",
+			tls:             nil,
+			expectTLSConfig: false,
+		},
+		{
+			name: "GivenStardogInstance_WhenCABundleInConfigMap_ThenSetRootCAs",
+			tls: &stardogv1alpha1.StardogTLSSpec{
+				CABundle:   &stardogv1alpha1.StardogCABundleSpec{ConfigMapRef: "ca"},
+				ServerName: "stardog.example.com",
+			},
+			objects: []runtime.Object{&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: namespace},
+				Data:       map[string]string{"ca.crt": string(certificate)},
+			}},
+			expectTLSConfig: true,
+			expectRootCAs:   true,
+		},
+		{
+			name: "GivenStardogInstance_WhenCABundleInSecretIsInvalid_ThenRaiseError",
+			tls: &stardogv1alpha1.StardogTLSSpec{
+				CABundle: &stardogv1alpha1.StardogCABundleSpec{SecretRef: "ca", Key: "bundle.pem"},
+			},
+			objects: []runtime.Object{&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: namespace},
+				Data:       map[string][]byte{"bundle.pem": []byte("invalid")},
+			}},
+			err: errors.New("cannot parse CA bundle of StardogInstance namespace-test/instance-test: no valid PEM encoded certificate found"),
+		},
+		{
+			name: "GivenStardogInstance_WhenClientCertificateInSecret_ThenSetCertificates",
+			tls: &stardogv1alpha1.StardogTLSSpec{
+				ClientCertificate: &stardogv1alpha1.StardogClientCertificateSpec{SecretRef: "client"},
+			},
+			objects: []runtime.Object{&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: namespace},
+				Data:       map[string][]byte{v1.TLSCertKey: certificate, v1.TLSPrivateKeyKey: key},
+			}},
+			expectTLSConfig: true,
+			expectClientTLS: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKubeClient, err := createKubeFakeClient(tt.objects...)
+			assert.NoError(t, err)
+
+			instance := createStardogInstance(namespace, "instance-test", "secret-test", "https://stardog:5820")
+			instance.Spec.TLS = tt.tls
+			rc := &ReconciliationContext{
+				context:    context.Background(),
+				conditions: make(v1alpha1.StardogConditionMap),
+				namespace:  namespace,
+			}
+
+			tlsConfig, err := rc.getTLSConfig(fakeKubeClient, *instance)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expectTLSConfig, tlsConfig != nil)
+			if tlsConfig != nil {
+				assert.Equal(t, tt.tls.ServerName, tlsConfig.ServerName)
+				assert.Equal(t, tt.expectRootCAs, tlsConfig.RootCAs != nil)
+				assert.Equal(t, tt.expectClientTLS, len(tlsConfig.Certificates) == 1)
+			}
+		})
+	}
+}
+
+func createCertificate(t *testing.T) (certificate, key []byte) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "stardog.example.com"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(privateKey)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
 func createNamespace(name string) *v1.Namespace {
 	namespace := &v1.Namespace{
 		TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
@@ -207,6 +369,7 @@ func createKubeFakeClientWithSub(initObjs ...client.Object) (client.Client, erro
 	return fake.NewClientBuilder().
 		WithScheme(s).
 		WithObjects(initObjs...).
-		WithStatusSubresource(&v1beta1.Organization{}, &v1beta1.Database{}).
+		WithStatusSubresource(&v1beta1.Organization{}, &v1beta1.Database{}, &v1beta1.DatabaseMigration{},
+			&v1beta1.DatabaseBackup{}, &v1beta1.DatabaseBackupSchedule{}, &v1beta1.DatabaseRestore{}, &v1beta1.ArchivedDatabase{}).
 		Build(), nil
 }
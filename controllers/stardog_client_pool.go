@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"sync"
+
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// StardogClientPool caches one StardogAPI client per StardogInstance, so that reconcilers share HTTP transports and
+// keep-alive connections instead of building a new client for every reconciliation. It is safe for concurrent use.
+type StardogClientPool struct {
+	factory StardogClientFactory
+	mutex   sync.Mutex
+	clients map[types.NamespacedName]pooledStardogClient
+}
+
+type pooledStardogClient struct {
+	version string
+	client  stardogapi.StardogAPI
+}
+
+// idleConnectionsCloser is implemented by clients holding an HTTP transport, e.g. stardogapi.Client
+type idleConnectionsCloser interface {
+	CloseIdleConnections()
+}
+
+// NewStardogClientPool creates an empty StardogClientPool which uses the given factory to create clients.
+func NewStardogClientPool(factory StardogClientFactory) *StardogClientPool {
+	return &StardogClientPool{
+		factory: factory,
+		clients: make(map[types.NamespacedName]pooledStardogClient),
+	}
+}
+
+// get returns the cached client of the given StardogInstance if it has been created for the same version. Otherwise
+// create is called and its client replaces the cached one. The version has to change whenever the StardogInstance or
+// any Secret or ConfigMap it references changes.
+func (p *StardogClientPool) get(instance types.NamespacedName, version string, create func(StardogClientFactory) (stardogapi.StardogAPI, error)) (stardogapi.StardogAPI, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pooled, found := p.clients[instance]
+	if found && pooled.version == version {
+		return pooled.client, nil
+	}
+
+	stardogClient, err := create(p.factory)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		closeIdleConnections(pooled.client)
+	}
+	p.clients[instance] = pooledStardogClient{version: version, client: stardogClient}
+	return stardogClient, nil
+}
+
+// Remove drops the cached client of the given StardogInstance
+func (p *StardogClientPool) Remove(instance types.NamespacedName) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if pooled, found := p.clients[instance]; found {
+		closeIdleConnections(pooled.client)
+		delete(p.clients, instance)
+	}
+}
+
+func closeIdleConnections(stardogClient stardogapi.StardogAPI) {
+	if closer, ok := stardogClient.(idleConnectionsCloser); ok {
+		closer.CloseIdleConnections()
+	}
+}
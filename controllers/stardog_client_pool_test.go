@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_StardogClientPool(t *testing.T) {
+	instance := types.NamespacedName{Namespace: "namespace-test", Name: "instance-test"}
+
+	tests := []struct {
+		name            string
+		versions        []string
+		remove          bool
+		expectedCreates int
+	}{
+		{
+			name:            "GivenPool_WhenVersionIsUnchanged_ThenReuseClient",
+			versions:        []string{"uid/1/1", "uid/1/1", "uid/1/1"},
+			expectedCreates: 1,
+		},
+		{
+			name:            "GivenPool_WhenVersionChanges_ThenCreateNewClient",
+			versions:        []string{"uid/1/1", "uid/2/1", "uid/2/3"},
+			expectedCreates: 3,
+		},
+		{
+			name:            "GivenPool_WhenClientIsRemoved_ThenCreateNewClient",
+			versions:        []string{"uid/1/1", "uid/1/1"},
+			remove:          true,
+			expectedCreates: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			creates := 0
+			pool := NewStardogClientPool(func(_ v1alpha1.StardogInstance, _, _ string, _ *tls.Config) (stardogapi.StardogAPI, error) {
+				creates++
+				return mock.NewMockStardogAPI(mockCtrl), nil
+			})
+			create := func(factory StardogClientFactory) (stardogapi.StardogAPI, error) {
+				return factory(v1alpha1.StardogInstance{}, "admin", "1234", nil)
+			}
+
+			for _, version := range tt.versions {
+				_, err := pool.get(instance, version, create)
+				assert.NoError(t, err)
+				if tt.remove {
+					pool.Remove(instance)
+				}
+			}
+
+			assert.Equal(t, tt.expectedCreates, creates)
+		})
+	}
+}
+
+func Test_StardogClientPool_Concurrent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+	pool := createStardogClientPoolFromMock(stardogMocked)
+	create := func(factory StardogClientFactory) (stardogapi.StardogAPI, error) {
+		return factory(v1alpha1.StardogInstance{}, "admin", "1234", nil)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			instance := types.NamespacedName{Namespace: "namespace-test", Name: "instance-test"}
+			stardogClient, err := pool.get(instance, "uid/1/1", create)
+			assert.NoError(t, err)
+			assert.Equal(t, stardogMocked, stardogClient)
+			if i%2 == 0 {
+				pool.Remove(instance)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func Test_initStardogClient(t *testing.T) {
+	namespace := "namespace-test"
+
+	tests := []struct {
+		name            string
+		changedPassword string
+		expectedCreates int
+	}{
+		{
+			name:            "GivenPooledClient_WhenAdminSecretIsUnchanged_ThenReuseClient",
+			expectedCreates: 1,
+		},
+		{
+			name:            "GivenPooledClient_WhenAdminSecretChanges_ThenCreateNewClient",
+			changedPassword: "5678",
+			expectedCreates: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := createFullSecret(namespace, "secret-test", "admin", "1234")
+			instance := createStardogInstance(namespace, "instance-test", "secret-test", "http://stardog:5820")
+			fakeKubeClient, err := createKubeFakeClient(secret, instance)
+			assert.NoError(t, err)
+
+			var passwords []string
+			pool := NewStardogClientPool(func(_ v1alpha1.StardogInstance, _, password string, _ *tls.Config) (stardogapi.StardogAPI, error) {
+				passwords = append(passwords, password)
+				return stardogapi.NewClient("admin", password, "http://stardog:5820"), nil
+			})
+			rc := &ReconciliationContext{
+				context:        context.Background(),
+				conditions:     make(v1alpha1.StardogConditionMap),
+				namespace:      namespace,
+				stardogClients: pool,
+			}
+
+			first, err := rc.initStardogClient(fakeKubeClient, *instance)
+			assert.NoError(t, err)
+			if tt.changedPassword != "" {
+				secret.Data["password"] = []byte(tt.changedPassword)
+				assert.NoError(t, fakeKubeClient.Update(context.Background(), secret))
+			}
+			second, err := rc.initStardogClient(fakeKubeClient, *instance)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.changedPassword == "", first == second)
+			assert.Len(t, passwords, tt.expectedCreates)
+			if tt.changedPassword != "" {
+				assert.Equal(t, tt.changedPassword, passwords[len(passwords)-1])
+			}
+		})
+	}
+}
@@ -2,15 +2,17 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
-	stardog "github.com/vshn/stardog-userrole-operator/stardogrest/client"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users"
 	"net/url"
 	"time"
 
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
@@ -28,6 +30,7 @@ type StardogInstanceReconciler struct {
 	Log               logr.Logger
 	Scheme            *runtime.Scheme
 	ReconcileInterval time.Duration
+	StardogClients    *StardogClientPool
 }
 
 const instanceUserFinalizer = "finalizer.stardog.instance.users"
@@ -36,6 +39,7 @@ const instanceDatabasesFinalizer = "finalizer.stardog.instance.databases"
 
 // +kubebuilder:rbac:groups=stardog.vshn.ch,resources=stardoginstances,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=stardog.vshn.ch,resources=stardoginstances/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 func (r *StardogInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	namespace := req.NamespacedName
@@ -45,6 +49,9 @@ func (r *StardogInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			r.Log.Info("StardogInstance not found, ignoring reconcile.", "StardogInstance", namespace)
+			if r.StardogClients != nil {
+				r.StardogClients.Remove(namespace)
+			}
 			return ctrl.Result{Requeue: false}, nil
 		}
 		r.Log.Error(err, "could not retrieve StardogInstance.", "StardogInstance", namespace)
@@ -57,10 +64,10 @@ func (r *StardogInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 
 	sir := &StardogInstanceReconciliation{
 		reconciliationContext: &ReconciliationContext{
-			context:       ctx,
-			conditions:    make(map[StardogConditionType]StardogCondition),
-			namespace:     namespace.Namespace,
-			stardogClient: stardog.NewHTTPClient(nil),
+			context:        ctx,
+			conditions:     make(map[StardogConditionType]StardogCondition),
+			namespace:      namespace.Namespace,
+			stardogClients: r.StardogClients,
 		},
 		resource: stardogInstance,
 	}
@@ -78,7 +85,12 @@ func (r *StardogInstanceReconciler) ReconcileStardogInstance(sir *StardogInstanc
 	if isStardogInstanceMarkedToBeDeleted {
 		r.Log.Info(fmt.Sprintf("checking if StardogInstance %s is deletable", sir.resource.Name))
 		if err := r.deleteStardogInstance(sir); err != nil {
-			rc.SetStatusCondition(createStatusConditionTerminating(err))
+			if errors.Is(err, errDeletionProtected) {
+				r.Log.Info("StardogInstance is protected from deletion", getLoggingKeysAndValuesForStardogInstance(stardogInstance)...)
+				rc.SetStatusCondition(createStatusConditionDeletionBlocked(err))
+			} else {
+				rc.SetStatusCondition(createStatusConditionTerminating(err))
+			}
 			rc.SetStatusCondition(createStatusConditionReady(false, "StardogInstance not ready"))
 			return ctrl.Result{Requeue: true, RequeueAfter: ReconFreqErr}, r.updateStatus(sir)
 		}
@@ -116,6 +128,9 @@ func (r *StardogInstanceReconciler) deleteStardogInstance(sir *StardogInstanceRe
 	stardogInstance := sir.resource
 	rc := sir.reconciliationContext
 
+	if IsDeletionProtected(stardogInstance) {
+		return errDeletionProtected
+	}
 	if contains(stardogInstance.GetFinalizers(), instanceUserFinalizer) {
 		if err := r.userFinalizer(sir); err != nil {
 			return err
@@ -212,6 +227,21 @@ func (r *StardogInstanceReconciler) validateSpecification(spec StardogInstanceSp
 	if err != nil {
 		return fmt.Errorf(".spec.ServerUrl is not a valid URL: %v", err)
 	}
+	_, err = getBaseURL(spec.ServerUrl)
+	if err != nil {
+		return fmt.Errorf(".spec.ServerUrl is not a valid URL: %v", err)
+	}
+	if spec.TLS != nil {
+		if spec.TLS.CABundle != nil {
+			caBundle := spec.TLS.CABundle
+			if (caBundle.SecretRef == "") == (caBundle.ConfigMapRef == "") {
+				return fmt.Errorf(".spec.TLS.CABundle requires exactly one of SecretRef and ConfigMapRef")
+			}
+		}
+		if spec.TLS.ClientCertificate != nil && spec.TLS.ClientCertificate.SecretRef == "" {
+			return fmt.Errorf(".spec.TLS.ClientCertificate.SecretRef is required")
+		}
+	}
 	return nil
 }
 
@@ -226,12 +256,12 @@ func (r *StardogInstanceReconciler) validateConnection(sir *StardogInstanceRecon
 	}
 
 	r.Log.V(1).Info("retrieving admin credentials from Secret", "secret", credentials.Namespace+"/"+credentials.SecretRef)
-	auth, err := rc.initStardogClient(r.Client, *sir.resource)
+	stardogClient, err := rc.initStardogClient(r.Client, *sir.resource)
 	if err != nil {
 		return err
 	}
 
-	_, err = rc.stardogClient.Users.IsEnabled(users.NewIsEnabledParams().WithUser("admin"), auth)
+	_, err = stardogClient.IsUserEnabled(rc.context, "admin")
 	if err != nil {
 		return err
 	}
@@ -239,9 +269,15 @@ func (r *StardogInstanceReconciler) validateConnection(sir *StardogInstanceRecon
 }
 
 func (r *StardogInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexField(mgr, &StardogInstance{}, secretIndex, indexInstanceSecrets); err != nil {
+		return err
+	}
+	mapSecretToInstances := func(ctx context.Context, secret client.Object) []reconcile.Request {
+		return requestsForIndex(ctx, r.Client, &StardogInstanceList{}, secretIndex, objectKey(secret.GetNamespace(), secret.GetName()))
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&StardogInstance{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		For(&StardogInstance{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(mapSecretToInstances)).
 		Complete(r)
 }
 
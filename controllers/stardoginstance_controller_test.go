@@ -3,10 +3,7 @@ package controllers
 import (
 	"context"
 	"errors"
-	stardog_client "github.com/vshn/stardog-userrole-operator/stardogrest/client"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users"
-	stardogmock "github.com/vshn/stardog-userrole-operator/stardogrest/mocks"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
 	"os"
 	"testing"
 	"time"
@@ -36,8 +33,7 @@ func Test_deleteStardogInstance(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name               string
@@ -53,16 +49,32 @@ func Test_deleteStardogInstance(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstanceWithFinalizers(namespace, stardogInstanceName, secretName, serverURL),
 			},
 			expectedFinalizers: []string{},
 			err:                nil,
 		},
+		{
+			name:            "GivenReconciliationContext_WhenDeletionProtected_ThenKeepFinalizers",
+			stardogInstance: *createProtectedStardogInstanceWithFinalizers(namespace, stardogInstanceName, secretName, serverURL),
+			secret:          *createFullSecret(namespace, secretName, username, password),
+			sir: StardogInstanceReconciliation{
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+				resource: createProtectedStardogInstanceWithFinalizers(namespace, stardogInstanceName, secretName, serverURL),
+			},
+			expectedFinalizers: []string{instanceRoleFinalizer, instanceUserFinalizer},
+			err:                errDeletionProtected,
+		},
 	}
 
 	for _, tt := range tests {
@@ -111,8 +123,7 @@ func Test_userFinalize(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name     string
@@ -127,10 +138,10 @@ func Test_userFinalize(t *testing.T) {
 			},
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       ctx,
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        ctx,
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstanceWithFinalizers(namespace, stardogInstanceName, secretName, serverURL),
 			},
@@ -146,10 +157,10 @@ func Test_userFinalize(t *testing.T) {
 			},
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       ctx,
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        ctx,
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstanceWithFinalizers(namespace, stardogInstanceName, secretName, serverURL),
 			},
@@ -201,8 +212,7 @@ func Test_roleFinalize(t *testing.T) {
 	ctx := context.Background()
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name     string
@@ -217,10 +227,10 @@ func Test_roleFinalize(t *testing.T) {
 			},
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       ctx,
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        ctx,
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstanceWithFinalizers(namespace, stardogInstanceRef, secretName, serverURL),
 			},
@@ -236,10 +246,10 @@ func Test_roleFinalize(t *testing.T) {
 			},
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       ctx,
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        ctx,
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstanceWithFinalizers(namespace, stardogInstanceRef, secretName, serverURL),
 			},
@@ -315,6 +325,45 @@ func Test_validateSpec(t *testing.T) {
 			},
 			err: errors.New(".spec.AdminCredentials.SecretRef is required"),
 		},
+		{
+			name: "GivenStardogInstanceSpec_WhenUrlHasUnsupportedScheme_ThenRaiseError",
+			stardogInstanceSpec: v1alpha1.StardogInstanceSpec{
+				ServerUrl: "ftp://test-stardog.ch",
+				AdminCredentials: v1alpha1.StardogUserCredentialsSpec{
+					SecretRef: secretName,
+				},
+			},
+			err: errors.New(".spec.ServerUrl is not a valid URL: unsupported scheme \"ftp\" in ftp://test-stardog.ch"),
+		},
+		{
+			name: "GivenStardogInstanceSpec_WhenCABundleHasSecretAndConfigMap_ThenRaiseError",
+			stardogInstanceSpec: v1alpha1.StardogInstanceSpec{
+				ServerUrl: serverURL,
+				AdminCredentials: v1alpha1.StardogUserCredentialsSpec{
+					SecretRef: secretName,
+				},
+				TLS: &v1alpha1.StardogTLSSpec{
+					CABundle: &v1alpha1.StardogCABundleSpec{
+						SecretRef:    "ca-secret",
+						ConfigMapRef: "ca-configmap",
+					},
+				},
+			},
+			err: errors.New(".spec.TLS.CABundle requires exactly one of SecretRef and ConfigMapRef"),
+		},
+		{
+			name: "GivenStardogInstanceSpec_WhenClientCertificateHasNoSecret_ThenRaiseError",
+			stardogInstanceSpec: v1alpha1.StardogInstanceSpec{
+				ServerUrl: serverURL,
+				AdminCredentials: v1alpha1.StardogUserCredentialsSpec{
+					SecretRef: secretName,
+				},
+				TLS: &v1alpha1.StardogTLSSpec{
+					ClientCertificate: &v1alpha1.StardogClientCertificateSpec{},
+				},
+			},
+			err: errors.New(".spec.TLS.ClientCertificate.SecretRef is required"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -346,8 +395,7 @@ func Test_validateConnection(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name            string
@@ -362,10 +410,10 @@ func Test_validateConnection(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstance(namespace, stardogInstanceName, secretName, serverURL),
 			},
@@ -377,10 +425,10 @@ func Test_validateConnection(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstance(namespace, stardogInstanceName, secretName, serverURL),
 			},
@@ -400,12 +448,8 @@ func Test_validateConnection(t *testing.T) {
 			}
 
 			stardogMocked.EXPECT().
-				SetTransport(gomock.Any()).
-				AnyTimes()
-
-			stardogMocked.EXPECT().
-				IsEnabled(gomock.Any(), gomock.Any()).
-				Return(&users.IsEnabledOK{Payload: &models.Enabled{Enabled: false}}, tt.err).
+				IsUserEnabled(gomock.Any(), gomock.Any()).
+				Return(false, tt.err).
 				Times(1)
 
 			err = r.validateConnection(&tt.sir)
@@ -484,8 +528,7 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name            string
@@ -494,7 +537,7 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 		stardogRole     v1alpha1.StardogRole
 		sir             StardogInstanceReconciliation
 		secret          v1.Secret
-		expectations    []func(stardog_client.Stardog)
+		expectations    []func()
 		expectedResult  ctrl.Result
 	}{
 		{
@@ -504,10 +547,10 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstance(namespace, stardogInstanceName, secretName, serverURL),
 			},
@@ -523,10 +566,10 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstance(namespace, stardogInstanceName, secretName, serverURL),
 			},
@@ -543,10 +586,10 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstance(namespace, stardogInstanceName, secretName, serverURL),
 			},
@@ -562,10 +605,10 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstance(namespace, stardogInstanceName, secretName, serverURL),
 			},
@@ -581,22 +624,19 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstance(namespace, stardogInstanceName, secretName, serverURL),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
-					stardogMocked.EXPECT().
-						SetTransport(gomock.Any()).
-						AnyTimes()
+			expectations: []func(){
+				func() {
 
 					stardogMocked.EXPECT().
-						IsEnabled(gomock.Any(), gomock.Any()).
-						Return(&users.IsEnabledOK{}, errors.New("cannot connect to Stardog"))
+						IsUserEnabled(gomock.Any(), gomock.Any()).
+						Return(false, errors.New("cannot connect to Stardog"))
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -611,20 +651,17 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstance(namespace, stardogInstanceName, secretName, serverURL),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						SetTransport(gomock.Any()).
-						AnyTimes()
-					stardogMocked.EXPECT().
-						IsEnabled(gomock.Any(), gomock.Any())
+						IsUserEnabled(gomock.Any(), gomock.Any())
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -639,20 +676,17 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sir: StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogInstance(namespace, stardogInstanceName, secretName, serverURL),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
-					stardogMocked.EXPECT().
-						SetTransport(gomock.Any()).
-						AnyTimes()
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						IsEnabled(gomock.Any(), gomock.Any())
+						IsUserEnabled(gomock.Any(), gomock.Any())
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -674,15 +708,15 @@ func Test_ReconcileStardogInstance(t *testing.T) {
 			}
 			sir := &StardogInstanceReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: &tt.stardogInstance,
 			}
 			for _, addExpectation := range tt.expectations {
-				addExpectation(*tt.sir.reconciliationContext.stardogClient)
+				addExpectation()
 			}
 
 			result, err := r.ReconcileStardogInstance(sir)
@@ -727,3 +761,9 @@ func createStardogInstanceWithFinalizers(namespace, name, secretName, serverURL
 	stardogInstance.SetFinalizers([]string{instanceRoleFinalizer, instanceUserFinalizer})
 	return stardogInstance
 }
+
+func createProtectedStardogInstanceWithFinalizers(namespace, name, secretName, serverURL string) *v1alpha1.StardogInstance {
+	stardogInstance := createStardogInstanceWithFinalizers(namespace, name, secretName, serverURL)
+	stardogInstance.SetAnnotations(map[string]string{v1alpha1.DeletionProtectionAnnotation: "true"})
+	return stardogInstance
+}
@@ -4,18 +4,14 @@ import (
 	"context"
 	"fmt"
 	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
-	stardog "github.com/vshn/stardog-userrole-operator/stardogrest/client"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles_permissions"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
-	"k8s.io/utils/pointer"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
 	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
@@ -33,6 +29,7 @@ type StardogRoleReconciler struct {
 	Log               logr.Logger
 	Scheme            *runtime.Scheme
 	ReconcileInterval time.Duration
+	StardogClients    *StardogClientPool
 }
 
 const roleFinalizer = "finalizer.stardog.roles"
@@ -60,10 +57,10 @@ func (r *StardogRoleReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	srr := &StardogRoleReconciliation{
 		reconciliationContext: &ReconciliationContext{
-			context:       ctx,
-			conditions:    make(map[StardogConditionType]StardogCondition),
-			namespace:     namespace.Namespace,
-			stardogClient: stardog.NewHTTPClient(nil),
+			context:        ctx,
+			conditions:     make(map[StardogConditionType]StardogCondition),
+			namespace:      namespace.Namespace,
+			stardogClients: r.StardogClients,
 		},
 		resource: stardogRole,
 	}
@@ -123,7 +120,7 @@ func (r *StardogRoleReconciler) syncRole(srr *StardogRoleReconciliation) error {
 	}
 
 	r.Log.V(1).Info("init Stardog Client from ", "ref", spec.StardogInstanceRef)
-	auth, disabled, err := srr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+	stardogClient, disabled, err := srr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
 	if err != nil {
 		return fmt.Errorf("cannot initialize stardog client: %v", err)
 	}
@@ -131,39 +128,34 @@ func (r *StardogRoleReconciler) syncRole(srr *StardogRoleReconciliation) error {
 		r.Log.Info("skipping resource from reconciliation", "instance", instance.Name, "resource", srr.resource.Name)
 		return nil
 	}
-	stardogClient := srr.reconciliationContext.stardogClient
+	ctx := srr.reconciliationContext.context
 
 	r.Log.Info("synchronizing role", "role", roleName)
-	allRoles, err := stardogClient.Roles.ListRoles(nil, auth)
-	if err != nil || !allRoles.IsSuccess() {
+	allRoles, err := stardogClient.GetRoles(ctx)
+	if err != nil {
 		return fmt.Errorf("cannot list current roles in %s: %v", namespace, err)
 	}
-	if !contains(allRoles.Payload.Roles, roleName) {
-		_, err = stardogClient.Roles.CreateRole(roles.NewCreateRoleParams().WithRole(&models.Rolename{Rolename: &roleName}), auth)
+	if !contains(allRoles, roleName) {
+		err = stardogClient.AddRole(ctx, roleName)
 		if err != nil {
 			return fmt.Errorf("cannot create role in %s/%s: %v", namespace, roleName, err)
 		}
 	}
 
-	var existingPermissions []*models.Permission
-	if contains(allRoles.Payload.Roles, roleName) {
+	var existingPermissions []stardogapi.Permission
+	if contains(allRoles, roleName) {
 		r.Log.V(1).Info("adding permissions to role", "role", roleName)
-		params := roles_permissions.NewListRolePermissionsParams().WithRole(roleName)
-		permissionsObject, err := stardogClient.RolesPermissions.ListRolePermissions(params, auth)
+		existingPermissions, err = stardogClient.GetRolePermissions(ctx, roleName)
 		if err != nil {
 			return fmt.Errorf("cannot list permissions for role %s in %s: %v", roleName, namespace, err)
 		}
-		existingPermissions = permissionsObject.Payload.Permissions
 	}
 
 	var permissionErrors []error
 	permissions := spec.Permissions
 	for _, existingPermission := range existingPermissions {
-		if !containsStardogPermission(permissions, *existingPermission) {
-			params := roles_permissions.NewRemoveRolePermissionParams().
-				WithRole(roleName).
-				WithPermission(existingPermission)
-			_, err := stardogClient.RolesPermissions.RemoveRolePermission(params, auth)
+		if !containsStardogPermission(permissions, existingPermission) {
+			err := stardogClient.DeleteRolePermission(ctx, roleName, existingPermission)
 			if err != nil {
 				permissionErrors = append(permissionErrors, err)
 			}
@@ -172,13 +164,12 @@ func (r *StardogRoleReconciler) syncRole(srr *StardogRoleReconciliation) error {
 
 	for _, permission := range permissions {
 		if !containsOperatorPermission(existingPermissions, permission) {
-			perm := &models.Permission{
-				Action:       &permission.Action,
-				ResourceType: &permission.ResourceType,
-				Resource:     permission.Resources,
+			perm := stardogapi.Permission{
+				Action:       permission.Action,
+				ResourceType: permission.ResourceType,
+				Resources:    permission.Resources,
 			}
-			params := roles_permissions.NewAddRolePermissionParams().WithRole(roleName).WithPermission(perm)
-			_, err := stardogClient.RolesPermissions.AddRolePermission(params, auth)
+			err := stardogClient.AddRolePermission(ctx, roleName, perm)
 			if err != nil {
 				permissionErrors = append(permissionErrors, err)
 			}
@@ -194,9 +185,12 @@ func (r *StardogRoleReconciler) syncRole(srr *StardogRoleReconciliation) error {
 }
 
 func (r *StardogRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&StardogRole{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+	if err := indexField(mgr, &StardogRole{}, instanceIndex, indexRoleInstances); err != nil {
+		return err
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&StardogRole{}, builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+	return watchInstanceDependents(b, r.Client, func() client.ObjectList { return &StardogRoleList{} }).
 		Complete(r)
 }
 
@@ -257,7 +251,7 @@ func (r *StardogRoleReconciler) finalize(srr *StardogRoleReconciliation) error {
 	namespace := srr.reconciliationContext.namespace
 	instance := v1beta1.NewStardogInstanceRef(spec.StardogInstanceRef, namespace)
 	r.Log.V(1).Info("setup Stardog Client from ", "ref", spec.StardogInstanceRef)
-	auth, disabled, err := srr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
+	stardogClient, disabled, err := srr.reconciliationContext.initStardogClientFromRef(r.Client, instance)
 	if err != nil {
 		return fmt.Errorf("cannot initialize stardog client: %v", err)
 	}
@@ -266,23 +260,23 @@ func (r *StardogRoleReconciler) finalize(srr *StardogRoleReconciliation) error {
 		return nil
 	}
 
-	stardogClient := srr.reconciliationContext.stardogClient
+	ctx := srr.reconciliationContext.context
 
 	role := spec.RoleName
 	if role == "" {
 		role = srr.resource.Name
 	}
 
-	rolesObject, err := stardogClient.UsersRoles.ListRoleUsers(users_roles.NewListRoleUsersParams().WithRole(role), auth)
+	roleUsers, err := stardogClient.GetRoleUsers(ctx, role)
 	if err != nil {
 		return fmt.Errorf("cannot get current list of roles in %s: %v", namespace, err)
 	}
 
-	if len(rolesObject.Payload.Users) > 0 {
-		return fmt.Errorf("cannot delete role %s as it is used by %s users in %s", role, strings.Join(rolesObject.Payload.Users, ","), namespace)
+	if len(roleUsers) > 0 {
+		return fmt.Errorf("cannot delete role %s as it is used by %s users in %s", role, strings.Join(roleUsers, ","), namespace)
 	}
 
-	_, err = srr.reconciliationContext.stardogClient.Roles.RemoveRole(roles.NewRemoveRoleParams().WithRole(role).WithForce(pointer.Bool(false)), auth)
+	err = stardogClient.DeleteRole(ctx, role)
 	if err != nil {
 		return fmt.Errorf("cannot remove Stardog Role %s/%s: %v", namespace, role, err)
 	}
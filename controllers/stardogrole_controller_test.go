@@ -3,13 +3,8 @@ package controllers
 import (
 	"context"
 	"errors"
-	stardog_client "github.com/vshn/stardog-userrole-operator/stardogrest/client"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles_permissions"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_roles"
-	stardogmock "github.com/vshn/stardog-userrole-operator/stardogrest/mocks"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
-	"k8s.io/utils/pointer"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
 	"os"
 	"testing"
 	"time"
@@ -50,20 +45,20 @@ func Test_syncRole(t *testing.T) {
 		ResourceType: resourceType2,
 		Resources:    resources2,
 	}
-	permission1 := models.Permission{
-		Action:       &action1,
-		ResourceType: &resourceType1,
-		Resource:     resources1,
+	permission1 := stardogapi.Permission{
+		Action:       action1,
+		ResourceType: resourceType1,
+		Resources:    resources1,
 	}
-	permission2 := models.Permission{
-		Action:       &action2,
-		ResourceType: &resourceType2,
-		Resource:     resources2,
+	permission2 := stardogapi.Permission{
+		Action:       action2,
+		ResourceType: resourceType2,
+		Resources:    resources2,
 	}
-	permission3 := models.Permission{
-		Action:       &action1,
-		ResourceType: &resourceType2,
-		Resource:     resources2,
+	permission3 := stardogapi.Permission{
+		Action:       action1,
+		ResourceType: resourceType2,
+		Resources:    resources2,
 	}
 
 	err := v1alpha1.AddToScheme(scheme.Scheme)
@@ -71,8 +66,7 @@ func Test_syncRole(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name            string
@@ -80,7 +74,7 @@ func Test_syncRole(t *testing.T) {
 		stardogInstance v1alpha1.StardogInstance
 		secret          v1.Secret
 		srr             StardogRoleReconciliation
-		expectations    []func(stardog_client.Stardog)
+		expectations    []func()
 		err             error
 	}{
 		{
@@ -90,56 +84,53 @@ func Test_syncRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRole(namespace, stardogRoleName, stardogInstanceRef, []v1alpha1.StardogPermissionSpec{permissionSpec1, permissionSpec2}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListRoles(gomock.Any(), gomock.Any()).
-						Return(&roles.ListRolesOK{Payload: &models.Roles{}}, nil).
+						GetRoles(gomock.Any()).
+						Return([]string{}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						CreateRole(roles.NewCreateRoleParams().WithRole(&models.Rolename{Rolename: &stardogRoleName}), gomock.Any()).
+						AddRole(gomock.Any(), stardogRoleName).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListRolePermissions(roles_permissions.NewListRolePermissionsParams().WithRole(stardogRoleName), gomock.Any()).
-						Return(&roles_permissions.ListRolePermissionsOK{Payload: &models.Permissions{Permissions: []*models.Permission{&permission1, &permission2}}}, nil).
+						GetRolePermissions(gomock.Any(), stardogRoleName).
+						Return([]stardogapi.Permission{permission1, permission2}, nil).
 						Times(0)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						AddRolePermission(roles_permissions.NewAddRolePermissionParams().WithRole(stardogRoleName).WithPermission(&permission1), gomock.Any()).
+						AddRolePermission(gomock.Any(), stardogRoleName, permission1).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						AddRolePermission(roles_permissions.NewAddRolePermissionParams().WithRole(stardogRoleName).WithPermission(&permission2), gomock.Any()).
+						AddRolePermission(gomock.Any(), stardogRoleName, permission2).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						RemoveRolePermission(roles_permissions.NewRemoveRolePermissionParams().WithRole(stardogRoleName), gomock.Any()).
+						DeleteRolePermission(gomock.Any(), stardogRoleName, gomock.Any()).
 						Times(0)
 				},
-				func(stardog_client.Stardog) {
-					stardogMocked.EXPECT().
-						SetTransport(gomock.Any()).
-						AnyTimes()
+				func() {
 				},
 			},
 			err: nil,
@@ -151,61 +142,47 @@ func Test_syncRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRole(namespace, stardogRoleName, stardogInstanceRef, []v1alpha1.StardogPermissionSpec{permissionSpec1, permissionSpec2}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListRoles(gomock.Any(), gomock.Any()).
-						Return(&roles.ListRolesOK{Payload: &models.Roles{Roles: []string{stardogRoleName}}}, nil).
+						GetRoles(gomock.Any()).
+						Return([]string{stardogRoleName}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						CreateRole(gomock.Any(), gomock.Any()).
+						AddRole(gomock.Any(), gomock.Any()).
 						Times(0)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListRolePermissions(
-							roles_permissions.NewListRolePermissionsParams().
-								WithRole(stardogRoleName),
-							gomock.Any()).
-						Return(&roles_permissions.ListRolePermissionsOK{Payload: &models.Permissions{Permissions: []*models.Permission{&permission2, &permission3}}}, nil).
+						GetRolePermissions(gomock.Any(), stardogRoleName).
+						Return([]stardogapi.Permission{permission2, permission3}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						AddRolePermission(
-							roles_permissions.NewAddRolePermissionParams().
-								WithRole(stardogRoleName).
-								WithPermission(&permission1),
-							gomock.Any()).
+						AddRolePermission(gomock.Any(), stardogRoleName, permission1).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						RemoveRolePermission(
-							roles_permissions.NewRemoveRolePermissionParams().
-								WithRole(stardogRoleName).
-								WithPermission(&permission3),
-							gomock.Any()).
+						DeleteRolePermission(gomock.Any(), stardogRoleName, permission3).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
-					stardogMocked.EXPECT().
-						SetTransport(gomock.Any()).
-						AnyTimes()
+				func() {
 				},
 			},
 			err: nil,
@@ -223,7 +200,7 @@ func Test_syncRole(t *testing.T) {
 				Client:            fakeKubeClient,
 			}
 			for _, addExpectation := range tt.expectations {
-				addExpectation(*tt.srr.reconciliationContext.stardogClient)
+				addExpectation()
 			}
 			err = r.syncRole(&tt.srr)
 
@@ -328,8 +305,7 @@ func Test_deleteStardogRole(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name               string
@@ -337,7 +313,7 @@ func Test_deleteStardogRole(t *testing.T) {
 		stardogInstance    v1alpha1.StardogInstance
 		secret             v1.Secret
 		srr                StardogRoleReconciliation
-		condition          func(stardog_client.Stardog)
+		condition          func()
 		expectedFinalizers []string
 		err                error
 	}{
@@ -348,17 +324,17 @@ func Test_deleteStardogRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRoleWithFinalizer(namespace, stardogRoleName, stardogInstanceRef, []v1alpha1.StardogPermissionSpec{permissionSpec1}),
 			},
-			condition: func(stardog_client.Stardog) {
+			condition: func() {
 				stardogMocked.EXPECT().
-					ListRoleUsers(gomock.Any(), gomock.Any()).
-					Return(&users_roles.ListRoleUsersOK{Payload: &models.Users{Users: []string{}}}, nil)
+					GetRoleUsers(gomock.Any(), gomock.Any()).
+					Return([]string{}, nil)
 			},
 			expectedFinalizers: nil,
 			err:                nil,
@@ -370,17 +346,17 @@ func Test_deleteStardogRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRoleWithFinalizer(namespace, stardogRoleName, stardogInstanceRef, []v1alpha1.StardogPermissionSpec{permissionSpec1}),
 			},
-			condition: func(stardog_client.Stardog) {
+			condition: func() {
 				stardogMocked.EXPECT().
-					ListRoleUsers(gomock.Any(), gomock.Any()).
-					Return(&users_roles.ListRoleUsersOK{Payload: &models.Users{Users: []string{"user1"}}}, nil)
+					GetRoleUsers(gomock.Any(), gomock.Any()).
+					Return([]string{"user1"}, nil)
 			},
 			expectedFinalizers: []string{roleFinalizer},
 			err:                errors.New("cannot delete role role-test as it is used by user1 users in namespace-test"),
@@ -397,9 +373,8 @@ func Test_deleteStardogRole(t *testing.T) {
 				Scheme:            scheme.Scheme,
 				Client:            fakeKubeClient,
 			}
-			tt.condition(*stardogClient)
-			stardogMocked.EXPECT().RemoveRole(gomock.Any(), gomock.Any()).AnyTimes()
-			stardogMocked.EXPECT().SetTransport(gomock.Any()).AnyTimes()
+			tt.condition()
+			stardogMocked.EXPECT().DeleteRole(gomock.Any(), gomock.Any()).AnyTimes()
 
 			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{
 				Namespace: namespace,
@@ -443,8 +418,7 @@ func Test_finalizeRole(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name            string
@@ -452,7 +426,7 @@ func Test_finalizeRole(t *testing.T) {
 		stardogInstance v1alpha1.StardogInstance
 		secret          v1.Secret
 		srr             StardogRoleReconciliation
-		conditions      []func(stardog_client.Stardog)
+		conditions      []func()
 		err             error
 	}{
 		{
@@ -462,23 +436,23 @@ func Test_finalizeRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       ctx,
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        ctx,
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRoleWithFinalizer(namespace, stardogRoleName, stardogInstanceRef, []v1alpha1.StardogPermissionSpec{permissionSpec1}),
 			},
-			conditions: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			conditions: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						ListRoleUsers(gomock.Any(), gomock.Any()).
-						Return(&users_roles.ListRoleUsersOK{Payload: &models.Users{Users: []string{}}}, nil).
+						GetRoleUsers(gomock.Any(), gomock.Any()).
+						Return([]string{}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						RemoveRole(roles.NewRemoveRoleParams().WithRole(stardogRoleName).WithForce(pointer.Bool(false)), gomock.Any()).
+						DeleteRole(gomock.Any(), stardogRoleName).
 						Times(1)
 				},
 			},
@@ -491,23 +465,23 @@ func Test_finalizeRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       ctx,
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        ctx,
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRoleWithFinalizer(namespace, stardogRoleName, stardogInstanceRef, []v1alpha1.StardogPermissionSpec{permissionSpec1}),
 			},
-			conditions: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			conditions: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						ListRoleUsers(users_roles.NewListRoleUsersParams().WithRole(stardogRoleName), gomock.Any()).
-						Return(&users_roles.ListRoleUsersOK{Payload: &models.Users{Users: []string{"user1"}}}, nil).
+						GetRoleUsers(gomock.Any(), stardogRoleName).
+						Return([]string{"user1"}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						RemoveRole(gomock.Any(), gomock.Any()).
+						DeleteRole(gomock.Any(), gomock.Any()).
 						Times(0)
 				},
 			},
@@ -520,24 +494,24 @@ func Test_finalizeRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       ctx,
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        ctx,
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRoleWithFinalizer(namespace, stardogRoleName, stardogInstanceRef, []v1alpha1.StardogPermissionSpec{permissionSpec1}),
 			},
-			conditions: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			conditions: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						ListRoleUsers(gomock.Any(), gomock.Any()).
-						Return(&users_roles.ListRoleUsersOK{Payload: &models.Users{Users: []string{}}}, nil).
+						GetRoleUsers(gomock.Any(), gomock.Any()).
+						Return([]string{}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						RemoveRole(roles.NewRemoveRoleParams().WithRole(stardogRoleName).WithForce(pointer.Bool(false)), gomock.Any()).
-						Return(&roles.RemoveRoleNoContent{}, errors.New("cannot update role")).
+						DeleteRole(gomock.Any(), stardogRoleName).
+						Return(errors.New("cannot update role")).
 						Times(1)
 				},
 			},
@@ -556,9 +530,8 @@ func Test_finalizeRole(t *testing.T) {
 				Client:            fakeKubeClient,
 			}
 			for _, addCondition := range tt.conditions {
-				addCondition(*stardogClient)
+				addCondition()
 			}
-			stardogMocked.EXPECT().SetTransport(gomock.Any()).AnyTimes()
 
 			err = r.finalize(&tt.srr)
 
@@ -636,8 +609,7 @@ func Test_ReconcileStardogRole(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name            string
@@ -647,7 +619,7 @@ func Test_ReconcileStardogRole(t *testing.T) {
 		stardogUser     v1alpha1.StardogUser
 		srr             StardogRoleReconciliation
 		secret          v1.Secret
-		expectations    []func(stardog_client.Stardog)
+		expectations    []func()
 		expectedResult  ctrl.Result
 	}{
 		{
@@ -658,22 +630,22 @@ func Test_ReconcileStardogRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRole(namespace, stardogRoleName, stardogInstanceName, []v1alpha1.StardogPermissionSpec{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						ListRoleUsers(gomock.Any(), gomock.Any()).
-						Return(&users_roles.ListRoleUsersOK{Payload: &models.Users{Users: []string{}}}, nil)
+						GetRoleUsers(gomock.Any(), gomock.Any()).
+						Return([]string{}, nil)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						RemoveRole(gomock.Any(), gomock.Any())
+						DeleteRole(gomock.Any(), gomock.Any())
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -689,22 +661,22 @@ func Test_ReconcileStardogRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRole(namespace, stardogRoleName, stardogInstanceName, []v1alpha1.StardogPermissionSpec{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						ListRoleUsers(gomock.Any(), gomock.Any()).
-						Return(&users_roles.ListRoleUsersOK{Payload: &models.Users{Users: []string{}}}, nil)
+						GetRoleUsers(gomock.Any(), gomock.Any()).
+						Return([]string{}, nil)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						RemoveRole(gomock.Any(), gomock.Any())
+						DeleteRole(gomock.Any(), gomock.Any())
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -721,18 +693,18 @@ func Test_ReconcileStardogRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRole(namespace, stardogRoleName, stardogInstanceName, []v1alpha1.StardogPermissionSpec{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						ListRoleUsers(gomock.Any(), gomock.Any()).
-						Return(&users_roles.ListRoleUsersOK{Payload: &models.Users{Users: []string{stardogUserName}}}, nil)
+						GetRoleUsers(gomock.Any(), gomock.Any()).
+						Return([]string{stardogUserName}, nil)
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -748,10 +720,10 @@ func Test_ReconcileStardogRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRole(namespace, stardogRoleName, stardogInstanceName, []v1alpha1.StardogPermissionSpec{}),
 			},
@@ -768,18 +740,18 @@ func Test_ReconcileStardogRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRole(namespace, stardogRoleName, stardogInstanceName, []v1alpha1.StardogPermissionSpec{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						ListRoles(gomock.Any(), gomock.Any()).
-						Return(&roles.ListRolesOK{Payload: &models.Roles{}}, errors.New("cannot list roles"))
+						GetRoles(gomock.Any()).
+						Return(nil, errors.New("cannot list roles"))
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -795,22 +767,22 @@ func Test_ReconcileStardogRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRole(namespace, stardogRoleName, stardogInstanceName, []v1alpha1.StardogPermissionSpec{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						ListRoles(gomock.Any(), gomock.Any()).
-						Return(&roles.ListRolesOK{Payload: &models.Roles{}}, nil)
+						GetRoles(gomock.Any()).
+						Return([]string{}, nil)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						CreateRole(gomock.Any(), gomock.Any())
+						AddRole(gomock.Any(), gomock.Any())
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -826,22 +798,22 @@ func Test_ReconcileStardogRole(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			srr: StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogRole(namespace, stardogRoleName, stardogInstanceName, []v1alpha1.StardogPermissionSpec{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						ListRoles(gomock.Any(), gomock.Any()).
-						Return(&roles.ListRolesOK{Payload: &models.Roles{}}, nil)
+						GetRoles(gomock.Any()).
+						Return([]string{}, nil)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						CreateRole(gomock.Any(), gomock.Any())
+						AddRole(gomock.Any(), gomock.Any())
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -863,17 +835,16 @@ func Test_ReconcileStardogRole(t *testing.T) {
 			}
 			srr := &StardogRoleReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: &tt.stardogRole,
 			}
 			for _, addExpectation := range tt.expectations {
-				addExpectation(*tt.srr.reconciliationContext.stardogClient)
+				addExpectation()
 			}
-			stardogMocked.EXPECT().SetTransport(gomock.Any()).AnyTimes()
 
 			result, err := r.ReconcileStardogRole(srr)
 
@@ -4,17 +4,21 @@ import (
 	"context"
 	"fmt"
 	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
-	stardog "github.com/vshn/stardog-userrole-operator/stardogrest/client"
-	model_users "github.com/vshn/stardog-userrole-operator/stardogrest/client/users"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -30,12 +34,15 @@ type StardogUserReconciler struct {
 	Log               logr.Logger
 	Scheme            *runtime.Scheme
 	ReconcileInterval time.Duration
+	StardogClients    *StardogClientPool
 }
 
 const userFinalizer = "finalizer.stardog.users"
 
 // +kubebuilder:rbac:groups=stardog.vshn.ch,resources=stardogusers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=stardog.vshn.ch,resources=stardogusers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 func (r *StardogUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	namespace := req.NamespacedName
@@ -57,10 +64,10 @@ func (r *StardogUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	sur := &StardogUserReconciliation{
 		reconciliationContext: &ReconciliationContext{
-			context:       ctx,
-			conditions:    make(map[StardogConditionType]StardogCondition),
-			namespace:     namespace.Namespace,
-			stardogClient: stardog.NewHTTPClient(nil),
+			context:        ctx,
+			conditions:     make(map[StardogConditionType]StardogCondition),
+			namespace:      namespace.Namespace,
+			stardogClients: r.StardogClients,
 		},
 		resource: stardogUser,
 	}
@@ -113,9 +120,20 @@ func (r *StardogUserReconciler) ReconcileStardogUser(sur *StardogUserReconciliat
 }
 
 func (r *StardogUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&StardogUser{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+	if err := indexField(mgr, &StardogUser{}, secretIndex, indexUserSecrets); err != nil {
+		return err
+	}
+	if err := indexField(mgr, &StardogUser{}, instanceIndex, indexUserInstances); err != nil {
+		return err
+	}
+	mapSecretToUsers := func(ctx context.Context, secret client.Object) []reconcile.Request {
+		return requestsForIndex(ctx, r.Client, &StardogUserList{}, secretIndex, objectKey(secret.GetNamespace(), secret.GetName()))
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&StardogUser{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&v1.Secret{}).
+		Watches(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(mapSecretToUsers))
+	return watchInstanceDependents(b, r.Client, func() client.ObjectList { return &StardogUserList{} }).
 		Complete(r)
 }
 
@@ -140,7 +158,7 @@ func (r *StardogUserReconciler) finalize(sur *StardogUserReconciliation) error {
 	instance := v1beta1.NewStardogInstanceRef(spec.StardogInstanceRef, namespace)
 
 	r.Log.V(1).Info("setup Stardog Client from ", "ref", spec.StardogInstanceRef)
-	auth, disabled, err := rc.initStardogClientFromRef(r.Client, instance)
+	stardogClient, disabled, err := rc.initStardogClientFromRef(r.Client, instance)
 	if err != nil {
 		return fmt.Errorf("cannot initialize stardog client: %v", err)
 	}
@@ -149,13 +167,27 @@ func (r *StardogUserReconciler) finalize(sur *StardogUserReconciliation) error {
 		return nil
 	}
 
-	_, err = rc.stardogClient.Users.RemoveUser(model_users.NewRemoveUserParams().WithUser(sur.resource.Name), auth)
+	username := getAppliedUsername(sur.resource)
+	err = stardogClient.DeleteUser(rc.context, username)
 	if err != nil {
-		return fmt.Errorf("cannot remove Stardog user %s/%s: %v", namespace, sur.resource.Name, err)
+		return fmt.Errorf("cannot remove Stardog user %s/%s: %v", namespace, username, err)
 	}
 	return nil
 }
 
+// getAppliedUsername returns the name of the user in Stardog. StardogUsers synchronized before the username has been
+// recorded in the status fall back to the username of the generated credentials or the name of the StardogUser.
+func getAppliedUsername(stardogUser *StardogUser) string {
+	if stardogUser.Status.Username != "" {
+		return stardogUser.Status.Username
+	}
+	credentials := stardogUser.Spec.Credentials
+	if credentials.Generate && credentials.Username != "" {
+		return credentials.Username
+	}
+	return stardogUser.Name
+}
+
 func (r *StardogUserReconciler) validateSpecification(spec *StardogUserSpec) error {
 	r.Log.V(1).Info("validating StardogUserSpec")
 	if spec.Credentials.SecretRef == "" {
@@ -175,7 +207,7 @@ func (r *StardogUserReconciler) syncUser(sur *StardogUserReconciliation) error {
 	instance := v1beta1.NewStardogInstanceRef(spec.StardogInstanceRef, namespace)
 
 	r.Log.V(1).Info("init Stardog Client from ", "ref", spec.StardogInstanceRef)
-	auth, disabled, err := rc.initStardogClientFromRef(r.Client, instance)
+	stardogClient, disabled, err := rc.initStardogClientFromRef(r.Client, instance)
 	if err != nil {
 		return fmt.Errorf("cannot initialize stardog client: %v", err)
 	}
@@ -184,53 +216,79 @@ func (r *StardogUserReconciler) syncUser(sur *StardogUserReconciliation) error {
 		return nil
 	}
 
+	if spec.Superuser {
+		allowed, err := r.isSuperuserAllowed(rc.context, instance, sur.resource.Namespace)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("StardogInstance %s does not allow superusers from namespace %s", instance.Name, sur.resource.Namespace)
+		}
+	}
+
+	rotated := false
+	if userCredentials.Generate {
+		if err := r.createGeneratedCredentials(sur, instance); err != nil {
+			return err
+		}
+		if rotated, err = r.rotateGeneratedCredentials(sur); err != nil {
+			return err
+		}
+	}
+
 	r.Log.V(1).Info("retrieving user credentials from Secret", "secret", userCredentials.Namespace+"/"+userCredentials.SecretRef)
-	username, password, err := rc.getCredentials(r.Client, userCredentials, namespace)
+	secret, err := rc.getCredentialsSecret(r.Client, userCredentials.StardogUserCredentialsSpec, namespace)
 	if err != nil {
 		return err
 	}
-
-	superuser := false
-	user := &models.User{
-		Username:  &username,
-		Password:  []string{password},
-		Superuser: superuser,
+	username, password, err := getUsernameAndPassword(*secret, userCredentials.StardogUserCredentialsSpec)
+	if err != nil {
+		return err
 	}
-	stardogClient := rc.stardogClient
-	usersObject, err := stardogClient.Users.ListUsers(nil, auth)
+	secretVersion := CredentialsSecretVersion{UID: secret.UID, ResourceVersion: secret.ResourceVersion}
+
+	ctx := rc.context
+	users, err := stardogClient.ListUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("cannot get current list of users in %s: %v", namespace, err)
 	}
 
-	users := usersObject.Payload.Users
-	if len(users) > 0 && contains(users, username) {
-		r.Log.V(1).Info("user already exists", "username", username)
-		params := model_users.NewChangePasswordParams().WithUser(username).WithPassword(&models.Password{Password: &password})
-		_, err := stardogClient.Users.ChangePassword(params, auth)
-		if err != nil {
-			return fmt.Errorf("cannot change password for %s/%s: %v", namespace, *user.Username, err)
+	// Stardog rejects the credentials of disabled users, so existing users are enabled before their credentials are
+	// validated and new users are disabled once they have been created
+	exists := contains(users, username)
+	if exists {
+		if err := r.syncEnabled(sur, stardogClient, username); err != nil {
+			return err
 		}
-	} else {
-		r.Log.V(1).Info("creating user", "username", username)
-		_, err = stardogClient.Users.CreateUser(model_users.NewCreateUserParams().WithUser(user), auth)
+	}
+	if err := r.syncCredentials(sur, stardogClient, users, username, password, secretVersion); err != nil {
+		return err
+	}
+	if !exists {
+		if err := r.syncEnabled(sur, stardogClient, username); err != nil {
+			return err
+		}
+	}
+	if err := r.checkSuperuser(sur, stardogClient, username); err != nil {
+		return err
+	}
+	if rotated {
+		err = restartDeployments(ctx, r.Client, sur.resource.Namespace, spec.CredentialRotation.RolloutSelector, now())
 		if err != nil {
-			return fmt.Errorf("cannot create user in %s/%s: %v", namespace, *user.Username, err)
+			return err
 		}
 	}
 
-	users_roles.NewListUserRolesParams().WithUser(username)
-	rolesObject, err := stardogClient.UsersRoles.ListUserRoles(users_roles.NewListUserRolesParams().WithUser(username), auth)
+	existingRoles, err := stardogClient.GetUserRoles(ctx, username)
 	if err != nil {
-		return fmt.Errorf("cannot get list of roles from %s/%s: %v", namespace, *user.Username, err)
+		return fmt.Errorf("cannot get list of roles from %s/%s: %v", namespace, username, err)
 	}
 
 	var roleErrors []error
 	roles := spec.Roles
-	existingRoles := rolesObject.Payload.Roles
 	for _, role := range roles {
 		if !contains(existingRoles, role) {
-			params := users_roles.NewAddRoleParams().WithUser(*user.Username).WithRole(&models.Rolename{Rolename: &role})
-			_, err := stardogClient.UsersRoles.AddRole(params, auth)
+			err := stardogClient.AddUserRole(ctx, username, role)
 			if err != nil {
 				roleErrors = append(roleErrors, err)
 			}
@@ -239,8 +297,7 @@ func (r *StardogUserReconciler) syncUser(sur *StardogUserReconciliation) error {
 
 	for _, existingRole := range existingRoles {
 		if !contains(roles, existingRole) {
-			params := users_roles.NewRemoveRoleOfUserParams().WithUser(username).WithRole(existingRole)
-			_, err := stardogClient.UsersRoles.RemoveRoleOfUser(params, auth)
+			err := stardogClient.DeleteUserRole(ctx, username, existingRole)
 			if err != nil {
 				roleErrors = append(roleErrors, err)
 			}
@@ -252,6 +309,330 @@ func (r *StardogUserReconciler) syncUser(sur *StardogUserReconciliation) error {
 		return fmt.Errorf("some roles have not been added to user %s in %s: %s", username, namespace, aggregateErrors)
 	}
 
+	if err := r.syncPermissions(sur, stardogClient, username); err != nil {
+		return err
+	}
+	return r.refreshEffectivePermissions(sur, stardogClient, username)
+}
+
+// syncPermissions grants the permissions of the spec to the user directly and revokes all other permissions granted
+// to the user directly. The permissions are left untouched if they are not set in the spec, e.g. for StardogUsers
+// created before the permissions were managed.
+func (r *StardogUserReconciler) syncPermissions(sur *StardogUserReconciliation, stardogClient stardogapi.StardogAPI, username string) error {
+	ctx := sur.reconciliationContext.context
+	namespace := sur.reconciliationContext.namespace
+
+	if sur.resource.Spec.Permissions == nil {
+		r.Log.V(1).Info("skipping unmanaged permissions of user", "username", username)
+		return nil
+	}
+
+	existingPermissions, err := stardogClient.GetUserPermissions(ctx, username)
+	if err != nil {
+		return fmt.Errorf("cannot list permissions of user %s in %s: %v", username, namespace, err)
+	}
+
+	var permissionErrors []error
+	permissions := sur.resource.Spec.Permissions
+	for _, existingPermission := range existingPermissions {
+		if !containsStardogPermission(permissions, existingPermission) {
+			err := stardogClient.DeleteUserPermission(ctx, username, existingPermission)
+			if err != nil {
+				permissionErrors = append(permissionErrors, err)
+			}
+		}
+	}
+
+	for _, permission := range permissions {
+		if !containsOperatorPermission(existingPermissions, permission) {
+			perm := stardogapi.Permission{
+				Action:       permission.Action,
+				ResourceType: permission.ResourceType,
+				Resources:    permission.Resources,
+			}
+			err := stardogClient.AddUserPermission(ctx, username, perm)
+			if err != nil {
+				permissionErrors = append(permissionErrors, err)
+			}
+		}
+	}
+
+	if len(permissionErrors) > 0 {
+		aggregateErrors := errors.NewAggregate(permissionErrors)
+		return fmt.Errorf("cannot add all permissions to user %s in %s: %s", username, namespace, aggregateErrors)
+	}
+	return nil
+}
+
+// createGeneratedCredentials creates the Secret of the generated credentials with a new password if it does not exist.
+// The Secret is owned by the StardogUser, so it is deleted together with it.
+func (r *StardogUserReconciler) createGeneratedCredentials(sur *StardogUserReconciliation, instance v1beta1.StardogInstanceRef) error {
+	ctx := sur.reconciliationContext.context
+	stardogUser := sur.resource
+	credentials := stardogUser.Spec.Credentials
+	secretName := credentials.SecretRef
+
+	secret := &v1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: stardogUser.Namespace, Name: secretName}, secret)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("cannot get Secret %s/%s: %v", stardogUser.Namespace, secretName, err)
+	}
+
+	username := credentials.Username
+	if username == "" {
+		username = stardogUser.Name
+	}
+	pwd, err := generatePassword()
+	if err != nil {
+		return err
+	}
+	secret = &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: stardogUser.Namespace},
+		Data: map[string][]byte{
+			credentials.GetUsernameKey(): []byte(username),
+			credentials.GetPasswordKey(): []byte(pwd),
+		},
+	}
+	if template := credentials.SecretTemplate; template != nil {
+		secret.Type = template.Type
+		secret.Labels = template.Labels
+		secret.Annotations = template.Annotations
+		if template.ConnectionDetails {
+			connection, err := getConnectionDetails(ctx, r.Client, instance, "")
+			if err != nil {
+				return err
+			}
+			for k, v := range connection {
+				secret.Data[k] = []byte(v)
+			}
+		}
+	}
+	if err := controllerutil.SetControllerReference(stardogUser, secret, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, secret); err != nil {
+		return fmt.Errorf("cannot create Secret %s/%s: %v", secret.Namespace, secret.Name, err)
+	}
+	r.Log.Info("created credential secret", "namespace", secret.Namespace, "name", secret.Name)
+	return nil
+}
+
+// rotateGeneratedCredentials writes a new password to the Secret of the generated credentials if the rotation policy
+// is due. The password is changed in Stardog by syncCredentials, as it no longer matches the applied credentials.
+func (r *StardogUserReconciler) rotateGeneratedCredentials(sur *StardogUserReconciliation) (bool, error) {
+	ctx := sur.reconciliationContext.context
+	stardogUser := sur.resource
+
+	rotate, err := isCredentialRotationDue(stardogUser.Spec.CredentialRotation, stardogUser.Status.LastRotationTime, stardogUser.CreationTimestamp)
+	if err != nil || !rotate {
+		return false, err
+	}
+
+	pwd, err := generatePassword()
+	if err != nil {
+		return false, err
+	}
+	key := types.NamespacedName{Namespace: stardogUser.Namespace, Name: stardogUser.Spec.Credentials.SecretRef}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret := &v1.Secret{}
+		if err := r.Get(ctx, key, secret); err != nil {
+			return err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		passwordKey := stardogUser.Spec.Credentials.GetPasswordKey()
+		secret.Data[passwordKey] = []byte(pwd)
+		delete(secret.StringData, passwordKey)
+		return r.Update(ctx, secret)
+	})
+	if err != nil {
+		return false, fmt.Errorf("cannot update Secret %s with rotated password: %v", key, err)
+	}
+	r.Log.Info("rotated password of generated credentials", "secret", key.String())
+	return true, nil
+}
+
+// syncCredentials creates the user or changes its password if the credentials Secret has changed since it has been
+// applied last. The applied password is validated against Stardog.
+func (r *StardogUserReconciler) syncCredentials(sur *StardogUserReconciliation, stardogClient stardogapi.StardogAPI, users []string, username, password string, secretVersion CredentialsSecretVersion) error {
+	rc := sur.reconciliationContext
+	ctx := rc.context
+	namespace := rc.namespace
+	status := sur.resource.Status
+
+	if contains(users, username) {
+		sur.username = username
+	}
+	if contains(users, username) && status.AppliedCredentials != nil && *status.AppliedCredentials == secretVersion {
+		r.Log.V(1).Info("user already exists with current credentials", "username", username)
+		lastRotationTime := metav1.Time{}
+		if status.LastRotationTime != nil {
+			lastRotationTime = *status.LastRotationTime
+		}
+		rc.SetStatusCondition(createStatusConditionCredentialsSynced(true, lastRotationTime, "Credentials synchronized"))
+		return nil
+	}
+
+	if contains(users, username) {
+		r.Log.Info("changing password of user", "username", username)
+		if err := stardogClient.ChangePassword(ctx, username, password); err != nil {
+			rc.SetStatusCondition(createStatusConditionCredentialsSynced(false, metav1.Now(), "Password cannot be changed"))
+			return fmt.Errorf("cannot change password for %s/%s: %v", namespace, username, err)
+		}
+	} else {
+		r.Log.V(1).Info("creating user", "username", username, "superuser", sur.resource.Spec.Superuser)
+		addUser := stardogClient.AddUser
+		if sur.resource.Spec.Superuser {
+			addUser = stardogClient.AddSuperuser
+		}
+		if err := addUser(ctx, username, password); err != nil {
+			return fmt.Errorf("cannot create user in %s/%s: %v", namespace, username, err)
+		}
+		sur.username = username
+	}
+
+	if !sur.resource.Spec.IsEnabled() && contains(users, username) {
+		r.Log.V(1).Info("skipping validation of disabled user", "username", username)
+	} else {
+		valid, err := stardogClient.ValidateUser(ctx, username, password)
+		if err != nil {
+			return fmt.Errorf("cannot validate credentials of %s/%s: %v", namespace, username, err)
+		}
+		if !valid {
+			rc.SetStatusCondition(createStatusConditionCredentialsSynced(false, metav1.Now(), "Password is not accepted by Stardog"))
+			return fmt.Errorf("password of %s/%s is not accepted by Stardog", namespace, username)
+		}
+	}
+
+	rotationTime := metav1.NewTime(now())
+	sur.appliedCredentials = &secretVersion
+	sur.lastRotationTime = &rotationTime
+	rc.SetStatusCondition(createStatusConditionCredentialsSynced(true, rotationTime, "Credentials synchronized"))
+	return nil
+}
+
+// isSuperuserAllowed returns whether the StardogInstance allows superusers from the given namespace
+func (r *StardogUserReconciler) isSuperuserAllowed(ctx context.Context, instance v1beta1.StardogInstanceRef, namespace string) (bool, error) {
+	stardogInstance := &StardogInstance{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, stardogInstance)
+	if err != nil {
+		return false, fmt.Errorf("cannot retrieve stardogInstanceRef %s/%s: %v", instance.Namespace, instance.Name, err)
+	}
+	if stardogInstance.Spec.AllowSuperuserFrom == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(stardogInstance.Spec.AllowSuperuserFrom)
+	if err != nil {
+		return false, fmt.Errorf("cannot parse allowSuperuserFrom of StardogInstance %s: %v", instance.Name, err)
+	}
+
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, fmt.Errorf("cannot get namespace %s: %v", namespace, err)
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// checkSuperuser reports a user which is a superuser in Stardog without being specified as one. A user cannot be
+// promoted to a superuser once it exists.
+func (r *StardogUserReconciler) checkSuperuser(sur *StardogUserReconciliation, stardogClient stardogapi.StardogAPI, username string) error {
+	rc := sur.reconciliationContext
+	superuser, err := stardogClient.IsSuperuser(rc.context, username)
+	if err != nil {
+		return fmt.Errorf("cannot check whether %s/%s is a superuser: %v", rc.namespace, username, err)
+	}
+
+	if superuser && !sur.resource.Spec.Superuser {
+		r.Log.Info("user is an unexpected superuser", "username", username)
+		rc.SetStatusCondition(createStatusConditionUnexpectedSuperuser(username))
+		return nil
+	}
+	rc.SetStatusIfExisting(StardogUnexpectedSuperuser, v1.ConditionFalse)
+	if !superuser && sur.resource.Spec.Superuser {
+		return fmt.Errorf("existing user %s/%s cannot be promoted to a superuser, it has to be recreated", rc.namespace, username)
+	}
+	return nil
+}
+
+// syncEnabled enables or disables the user in Stardog as specified. A state differing from the one applied last has
+// been changed out of band, which is reported by the StardogUserEnabled condition.
+func (r *StardogUserReconciler) syncEnabled(sur *StardogUserReconciliation, stardogClient stardogapi.StardogAPI, username string) error {
+	rc := sur.reconciliationContext
+	ctx := rc.context
+	desired := sur.resource.Spec.IsEnabled()
+	applied := sur.resource.Status.Enabled
+
+	enabled, err := stardogClient.IsUserEnabled(ctx, username)
+	if err != nil {
+		return fmt.Errorf("cannot get enabled state of %s/%s: %v", rc.namespace, username, err)
+	}
+	drifted := enabled != desired && applied != nil && *applied == desired
+	if drifted {
+		r.Log.Info("enabled state of user has been changed out of band", "username", username, "enabled", enabled)
+	}
+	if enabled != desired {
+		if err := stardogClient.SetUserEnabled(ctx, username, desired); err != nil {
+			return fmt.Errorf("cannot set enabled state of %s/%s: %v", rc.namespace, username, err)
+		}
+		r.Log.Info("changed enabled state of user", "username", username, "enabled", desired)
+	}
+
+	sur.enabled = &desired
+	rc.SetStatusCondition(createStatusConditionUserEnabled(desired, drifted))
+	return nil
+}
+
+// refreshEffectivePermissions lists all permissions of the user and attributes them to the roles the user has in
+// Stardog and to the permissions granted to the user directly, including wildcard permissions covering them
+func (r *StardogUserReconciler) refreshEffectivePermissions(sur *StardogUserReconciliation, stardogClient stardogapi.StardogAPI, username string) error {
+	ctx := sur.reconciliationContext.context
+	namespace := sur.reconciliationContext.namespace
+
+	permissions, err := stardogClient.GetEffectivePermissions(ctx, username)
+	if err != nil {
+		return fmt.Errorf("cannot list effective permissions of user %s in %s: %v", username, namespace, err)
+	}
+	userPermissions, err := stardogClient.GetUserPermissions(ctx, username)
+	if err != nil {
+		return fmt.Errorf("cannot list permissions of user %s in %s: %v", username, namespace, err)
+	}
+	roles, err := stardogClient.GetUserRoles(ctx, username)
+	if err != nil {
+		return fmt.Errorf("cannot get list of roles from %s/%s: %v", namespace, username, err)
+	}
+	rolePermissions := make(map[string][]stardogapi.Permission, len(roles))
+	for _, role := range roles {
+		rolePermissions[role], err = stardogClient.GetRolePermissions(ctx, role)
+		if err != nil {
+			return fmt.Errorf("cannot list permissions for role %s in %s: %v", role, namespace, err)
+		}
+	}
+
+	effectivePermissions := make([]EffectivePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		effectivePermission := EffectivePermission{
+			Action:       permission.Action,
+			ResourceType: permission.ResourceType,
+			Resources:    permission.Resources,
+		}
+		if containsGrantingPermission(userPermissions, permission) {
+			effectivePermission.GrantedBy = append(effectivePermission.GrantedBy, "user:"+username)
+		}
+		for _, role := range roles {
+			if containsGrantingPermission(rolePermissions[role], permission) {
+				effectivePermission.GrantedBy = append(effectivePermission.GrantedBy, role)
+			}
+		}
+		effectivePermissions = append(effectivePermissions, effectivePermission)
+	}
+
+	refreshTime := metav1.NewTime(now())
+	sur.effectivePermissions = effectivePermissions
+	sur.effectivePermissionsRefreshTime = &refreshTime
 	return nil
 }
 
@@ -261,6 +642,20 @@ func (r *StardogUserReconciler) updateStatus(sur *StardogUserReconciliation) err
 	// Once we are on Kubernetes 0.19, we can use metav1.Conditions, but for now, we have to implement our helpers on
 	// our own.
 	status.Conditions = mergeWithExistingConditions(status.Conditions, sur.reconciliationContext.conditions)
+	if sur.appliedCredentials != nil {
+		status.AppliedCredentials = sur.appliedCredentials
+		status.LastRotationTime = sur.lastRotationTime
+	}
+	if sur.username != "" {
+		status.Username = sur.username
+	}
+	if sur.enabled != nil {
+		status.Enabled = sur.enabled
+	}
+	if sur.effectivePermissionsRefreshTime != nil {
+		status.EffectivePermissions = sur.effectivePermissions
+		status.EffectivePermissionsRefreshTime = sur.effectivePermissionsRefreshTime
+	}
 	cfg.Status = status
 	err := r.Client.Status().Update(sur.reconciliationContext.context, cfg)
 	if err != nil {
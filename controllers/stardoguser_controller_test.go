@@ -4,10 +4,8 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
-	stardog_client "github.com/vshn/stardog-userrole-operator/stardogrest/client"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
 	"os"
 	"testing"
 	"time"
@@ -16,9 +14,10 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/vshn/stardog-userrole-operator/api/v1alpha1"
-	stardogmock "github.com/vshn/stardog-userrole-operator/stardogrest/mocks"
+	"github.com/vshn/stardog-userrole-operator/api/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -42,17 +41,18 @@ func Test_deleteStardogUser(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name               string
 		stardogUser        v1alpha1.StardogUser
+		username           string
+		credentials        v1alpha1.StardogUserGeneratedCredentialsSpec
 		stardogInstance    v1alpha1.StardogInstance
 		secretAdmin        v1.Secret
 		secretUser         v1.Secret
 		sur                StardogUserReconciliation
-		condition          func(stardog_client.Stardog)
+		condition          func()
 		expectedFinalizers []string
 		err                error
 	}{
@@ -64,16 +64,62 @@ func Test_deleteStardogUser(t *testing.T) {
 			secretUser:      *createFullSecret(namespace, secretNameUser, usernameUser, passwordUser),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUserWithFinalizer(namespace, stardogUserName, stardogInstanceRef, secretNameAdmin, roles),
 			},
-			condition: func(stardog_client.Stardog) {
+			condition: func() {
 				stardogMocked.EXPECT().
-					RemoveUser(gomock.Any(), gomock.Any())
+					DeleteUser(gomock.Any(), stardogUserName)
+			},
+			expectedFinalizers: nil,
+			err:                nil,
+		},
+		{
+			name:            "GivenRecordedUsername_WhenStardogUserCanBeDeleted_ThenDeleteRecordedUser",
+			stardogUser:     *createStardogUserWithFinalizer(namespace, stardogUserName, stardogInstanceRef, secretNameUser, roles),
+			username:        usernameUser,
+			stardogInstance: *createStardogInstance(namespace, stardogInstanceRef, secretNameAdmin, serverURL),
+			secretAdmin:     *createFullSecret(namespace, secretNameAdmin, usernameAdmin, passwordAdmin),
+			secretUser:      *createFullSecret(namespace, secretNameUser, usernameUser, passwordUser),
+			sur: StardogUserReconciliation{
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+				resource: &v1alpha1.StardogUser{},
+			},
+			condition: func() {
+				stardogMocked.EXPECT().
+					DeleteUser(gomock.Any(), usernameUser)
+			},
+			expectedFinalizers: nil,
+			err:                nil,
+		},
+		{
+			name:            "GivenGeneratedCredentialsWithoutRecordedUsername_WhenStardogUserCanBeDeleted_ThenDeleteGeneratedUser",
+			stardogUser:     *createStardogUserWithFinalizer(namespace, stardogUserName, stardogInstanceRef, secretNameUser, roles),
+			credentials:     v1alpha1.StardogUserGeneratedCredentialsSpec{Generate: true, Username: "generated-user"},
+			stardogInstance: *createStardogInstance(namespace, stardogInstanceRef, secretNameAdmin, serverURL),
+			secretAdmin:     *createFullSecret(namespace, secretNameAdmin, usernameAdmin, passwordAdmin),
+			secretUser:      *createFullSecret(namespace, secretNameUser, usernameUser, passwordUser),
+			sur: StardogUserReconciliation{
+				reconciliationContext: &ReconciliationContext{
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
+				},
+				resource: &v1alpha1.StardogUser{},
+			},
+			condition: func() {
+				stardogMocked.EXPECT().
+					DeleteUser(gomock.Any(), "generated-user")
 			},
 			expectedFinalizers: nil,
 			err:                nil,
@@ -86,17 +132,17 @@ func Test_deleteStardogUser(t *testing.T) {
 			secretUser:      *createFullSecret(namespace, secretNameUser, usernameUser, passwordUser),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUserWithFinalizer(namespace, stardogUserName, stardogInstanceRef, secretNameUser, roles),
 			},
-			condition: func(stardog_client.Stardog) {
+			condition: func() {
 				stardogMocked.EXPECT().
-					RemoveUser(gomock.Any(), gomock.Any()).
-					Return(users.NewRemoveUserNoContent(), errors.New("cannot remove user"))
+					DeleteUser(gomock.Any(), gomock.Any()).
+					Return(errors.New("cannot remove user"))
 			},
 			expectedFinalizers: []string{userFinalizer},
 			err:                errors.New("cannot remove Stardog user namespace-test/user-test: cannot remove user"),
@@ -105,6 +151,11 @@ func Test_deleteStardogUser(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			tt.stardogUser.Status.Username = tt.username
+			if tt.credentials.Generate {
+				tt.credentials.SecretRef = secretNameUser
+				tt.stardogUser.Spec.Credentials = tt.credentials
+			}
 			fakeKubeClient, err := createKubeFakeClient(&tt.stardogUser, &tt.stardogInstance, &tt.secretAdmin, &tt.secretUser)
 			assert.NoError(t, err)
 			r := StardogUserReconciler{
@@ -113,8 +164,7 @@ func Test_deleteStardogUser(t *testing.T) {
 				Scheme:            scheme.Scheme,
 				Client:            fakeKubeClient,
 			}
-			tt.condition(*stardogClient)
-			stardogMocked.EXPECT().SetTransport(gomock.Any()).AnyTimes()
+			tt.condition()
 
 			err = fakeKubeClient.Get(context.Background(), types.NamespacedName{
 				Namespace: namespace,
@@ -208,8 +258,7 @@ func Test_syncUser(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 	encodedUser := base64.StdEncoding.EncodeToString([]byte(usernameUser))
 	encodedPwd := base64.StdEncoding.EncodeToString([]byte(passwordUser))
 
@@ -220,7 +269,7 @@ func Test_syncUser(t *testing.T) {
 		secretAdmin     v1.Secret
 		secretUser      v1.Secret
 		sur             StardogUserReconciliation
-		expectations    []func(stardog_client.Stardog)
+		expectations    []func()
 		err             error
 	}{
 		{
@@ -231,44 +280,93 @@ func Test_syncUser(t *testing.T) {
 			secretUser:      *createFullSecret(namespace, secretNameUser, usernameUser, passwordUser),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUser, stardogInstanceRef, secretNameUser, roles1),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
+					stardogMocked.
+						EXPECT().
+						ListUsers(gomock.Any()).
+						Return([]string{}, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						AddUser(gomock.Any(), gomock.Any(), gomock.Any()).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						ValidateUser(gomock.Any(), encodedUser, encodedPwd).
+						Return(true, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						IsUserEnabled(gomock.Any(), encodedUser).
+						Return(true, nil).
+						Times(1)
+				},
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListUsers(gomock.Any(), gomock.Any()).
-						Return(&users.ListUsersOK{Payload: &models.Users{Users: []string{}}}, nil).
+						IsSuperuser(gomock.Any(), encodedUser).
+						Return(false, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						CreateUser(gomock.Any(), gomock.Any()).
+						GetEffectivePermissions(gomock.Any(), encodedUser).
+						Return([]stardogapi.Permission{}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
+					stardogMocked.
+						EXPECT().
+						GetRolePermissions(gomock.Any(), gomock.Any()).
+						Return([]stardogapi.Permission{}, nil).
+						Times(2)
+				},
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListUserRoles(users_roles.NewListUserRolesParams().WithUser(encodedUser), gomock.Any()).
-						Return(&users_roles.ListUserRolesOK{Payload: &models.Roles{Roles: []string{}}}, nil).
+						GetUserRoles(gomock.Any(), encodedUser).
+						Return([]string{}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						AddRole(users_roles.NewAddRoleParams().WithUser(encodedUser).WithRole(&models.Rolename{Rolename: &role1}), gomock.Any()).
+						GetUserRoles(gomock.Any(), encodedUser).
+						Return(roles1, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						AddRole(users_roles.NewAddRoleParams().WithUser(encodedUser).WithRole(&models.Rolename{Rolename: &role2}), gomock.Any()).
+						GetUserPermissions(gomock.Any(), encodedUser).
+						Return([]stardogapi.Permission{}, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						AddUserRole(gomock.Any(), encodedUser, role1).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						AddUserRole(gomock.Any(), encodedUser, role2).
 						Times(1)
 				},
 			},
@@ -282,44 +380,93 @@ func Test_syncUser(t *testing.T) {
 			secretUser:      *createFullSecret(namespace, secretNameUser, usernameUser, passwordUser),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUser, stardogInstanceRef, secretNameUser, roles1),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
+					stardogMocked.
+						EXPECT().
+						ListUsers(gomock.Any()).
+						Return([]string{"random-user"}, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						AddUser(gomock.Any(), gomock.Any(), gomock.Any()).
+						Times(1)
+				},
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListUsers(gomock.Any(), gomock.Any()).
-						Return(&users.ListUsersOK{Payload: &models.Users{Users: []string{"random-user"}}}, nil).
+						ValidateUser(gomock.Any(), encodedUser, encodedPwd).
+						Return(true, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						CreateUser(gomock.Any(), gomock.Any()).
+						IsUserEnabled(gomock.Any(), encodedUser).
+						Return(true, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListUserRoles(gomock.Any(), gomock.Any()).
-						Return(&users_roles.ListUserRolesOK{Payload: &models.Roles{Roles: []string{}}}, nil).
+						IsSuperuser(gomock.Any(), encodedUser).
+						Return(false, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						AddRole(users_roles.NewAddRoleParams().WithUser(encodedUser).WithRole(&models.Rolename{Rolename: &role1}), gomock.Any()).
+						GetEffectivePermissions(gomock.Any(), encodedUser).
+						Return([]stardogapi.Permission{}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
+					stardogMocked.
+						EXPECT().
+						GetRolePermissions(gomock.Any(), gomock.Any()).
+						Return([]stardogapi.Permission{}, nil).
+						Times(2)
+				},
+				func() {
 					stardogMocked.
 						EXPECT().
-						AddRole(users_roles.NewAddRoleParams().WithUser(encodedUser).WithRole(&models.Rolename{Rolename: &role2}), gomock.Any()).
+						GetUserRoles(gomock.Any(), gomock.Any()).
+						Return([]string{}, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						GetUserRoles(gomock.Any(), encodedUser).
+						Return(roles1, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						GetUserPermissions(gomock.Any(), encodedUser).
+						Return([]stardogapi.Permission{}, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						AddUserRole(gomock.Any(), encodedUser, role1).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						AddUserRole(gomock.Any(), encodedUser, role2).
 						Times(1)
 				},
 			},
@@ -333,48 +480,93 @@ func Test_syncUser(t *testing.T) {
 			secretUser:      *createFullSecret(namespace, secretNameUser, usernameUser, passwordUser),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUser, stardogInstanceRef, secretNameUser, roles2),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
+					stardogMocked.
+						EXPECT().
+						ListUsers(gomock.Any()).
+						Return([]string{encodedUser}, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						ChangePassword(gomock.Any(), encodedUser, encodedPwd).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						ValidateUser(gomock.Any(), encodedUser, encodedPwd).
+						Return(true, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						IsUserEnabled(gomock.Any(), encodedUser).
+						Return(true, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						IsSuperuser(gomock.Any(), encodedUser).
+						Return(false, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						GetEffectivePermissions(gomock.Any(), encodedUser).
+						Return([]stardogapi.Permission{}, nil).
+						Times(1)
+				},
+				func() {
+					stardogMocked.
+						EXPECT().
+						GetRolePermissions(gomock.Any(), gomock.Any()).
+						Return([]stardogapi.Permission{}, nil).
+						Times(2)
+				},
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListUsers(gomock.Any(), gomock.Any()).
-						Return(&users.ListUsersOK{Payload: &models.Users{Users: []string{encodedUser}}}, nil).
+						GetUserRoles(gomock.Any(), encodedUser).
+						Return(roles1, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						ChangePassword(
-							users.NewChangePasswordParams().
-								WithUser(encodedUser).
-								WithPassword(&models.Password{Password: &encodedPwd}),
-							gomock.Any()).
+						GetUserRoles(gomock.Any(), encodedUser).
+						Return(roles2, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						ListUserRoles(users_roles.NewListUserRolesParams().WithUser(encodedUser), gomock.Any()).
-						Return(&users_roles.ListUserRolesOK{Payload: &models.Roles{Roles: roles1}}, nil).
+						GetUserPermissions(gomock.Any(), encodedUser).
+						Return([]stardogapi.Permission{}, nil).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						AddRole(users_roles.NewAddRoleParams().WithUser(encodedUser).WithRole(&models.Rolename{Rolename: &role3}), gomock.Any()).
+						AddUserRole(gomock.Any(), encodedUser, role3).
 						Times(1)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.
 						EXPECT().
-						RemoveRoleOfUser(users_roles.NewRemoveRoleOfUserParams().WithUser(encodedUser).WithRole(role1), gomock.Any()).
+						DeleteUserRole(gomock.Any(), encodedUser, role1).
 						Times(1)
 				},
 			},
@@ -393,9 +585,8 @@ func Test_syncUser(t *testing.T) {
 				Client:            fakeKubeClient,
 			}
 			for _, addExpectation := range tt.expectations {
-				addExpectation(*tt.sur.reconciliationContext.stardogClient)
+				addExpectation()
 			}
-			stardogMocked.EXPECT().SetTransport(gomock.Any()).AnyTimes()
 
 			err = r.syncUser(&tt.sur)
 
@@ -404,6 +595,540 @@ func Test_syncUser(t *testing.T) {
 	}
 }
 
+func Test_syncCredentials(t *testing.T) {
+	username := "user"
+	password := "1234"
+	rotationTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	previousRotation := metav1.NewTime(rotationTime.Add(-time.Hour))
+	secretVersion := v1alpha1.CredentialsSecretVersion{UID: "secret-uid", ResourceVersion: "2"}
+	oldSecretVersion := v1alpha1.CredentialsSecretVersion{UID: "secret-uid", ResourceVersion: "1"}
+
+	tests := []struct {
+		name                 string
+		users                []string
+		status               v1alpha1.StardogUserStatus
+		expectMocks          func(stardogMocked *mock.MockStardogAPI)
+		expectedErr          bool
+		expectedUsername     string
+		expectedApplied      *v1alpha1.CredentialsSecretVersion
+		expectedSynced       bool
+		expectedRotationTime metav1.Time
+	}{
+		{
+			name:                 "GivenUnchangedCredentials_WhenSyncing_ThenKeepPassword",
+			users:                []string{username},
+			status:               v1alpha1.StardogUserStatus{AppliedCredentials: &secretVersion, LastRotationTime: &previousRotation},
+			expectMocks:          func(stardogMocked *mock.MockStardogAPI) {},
+			expectedUsername:     username,
+			expectedSynced:       true,
+			expectedRotationTime: previousRotation,
+		},
+		{
+			name:   "GivenChangedSecret_WhenSyncing_ThenChangeAndValidatePassword",
+			users:  []string{username},
+			status: v1alpha1.StardogUserStatus{AppliedCredentials: &oldSecretVersion, LastRotationTime: &previousRotation},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().ChangePassword(gomock.Any(), username, password).Return(nil).Times(1)
+				stardogMocked.EXPECT().ValidateUser(gomock.Any(), username, password).Return(true, nil).Times(1)
+			},
+			expectedUsername:     username,
+			expectedApplied:      &secretVersion,
+			expectedSynced:       true,
+			expectedRotationTime: metav1.NewTime(rotationTime),
+		},
+		{
+			name:  "GivenNewUser_WhenSyncing_ThenCreateAndValidateUser",
+			users: []string{},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().AddUser(gomock.Any(), username, password).Return(nil).Times(1)
+				stardogMocked.EXPECT().ValidateUser(gomock.Any(), username, password).Return(true, nil).Times(1)
+			},
+			expectedUsername:     username,
+			expectedApplied:      &secretVersion,
+			expectedSynced:       true,
+			expectedRotationTime: metav1.NewTime(rotationTime),
+		},
+		{
+			name:   "GivenRejectedPassword_WhenSyncing_ThenError",
+			users:  []string{username},
+			status: v1alpha1.StardogUserStatus{AppliedCredentials: &oldSecretVersion},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().ChangePassword(gomock.Any(), username, password).Return(nil).Times(1)
+				stardogMocked.EXPECT().ValidateUser(gomock.Any(), username, password).Return(false, nil).Times(1)
+			},
+			expectedErr:      true,
+			expectedUsername: username,
+			expectedSynced:   false,
+		},
+		{
+			name:  "GivenNewUser_WhenCreationFails_ThenDoNotRecordUsername",
+			users: []string{},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().AddUser(gomock.Any(), username, password).Return(errors.New("conflict")).Times(1)
+			},
+			expectedErr:    true,
+			expectedSynced: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return rotationTime }
+			defer func() { now = time.Now }()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			tt.expectMocks(stardogMocked)
+			stardogUser := createStardogUser("namespace-test", "user-test", "instance-test", "secret-test", []string{})
+			stardogUser.Status = tt.status
+			sur := &StardogUserReconciliation{
+				resource: stardogUser,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+					namespace:  "namespace-test",
+				},
+			}
+			r := StardogUserReconciler{Log: testr.New(t)}
+
+			err := r.syncCredentials(sur, stardogMocked, tt.users, username, password, secretVersion)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			assert.Equal(t, tt.expectedUsername, sur.username)
+			assert.Equal(t, tt.expectedApplied, sur.appliedCredentials)
+			condition := sur.reconciliationContext.conditions[v1alpha1.StardogCredentialsSynced]
+			assert.Equal(t, tt.expectedSynced, condition.Status == v1.ConditionTrue)
+			if tt.expectedSynced {
+				assert.Equal(t, tt.expectedRotationTime, condition.LastTransitionTime)
+			}
+		})
+	}
+}
+
+func Test_syncEnabled(t *testing.T) {
+	username := "user"
+	enabled := true
+	disabled := false
+
+	tests := []struct {
+		name            string
+		specEnabled     *bool
+		statusEnabled   *bool
+		stardogEnabled  bool
+		expectChange    bool
+		expectedReason  string
+		expectedEnabled bool
+	}{
+		{
+			name:            "GivenEnabledUser_WhenSyncing_ThenKeepUser",
+			stardogEnabled:  true,
+			expectedReason:  v1alpha1.ReasonEnabled,
+			expectedEnabled: true,
+		},
+		{
+			name:            "GivenDisabledSpec_WhenSyncing_ThenDisableUser",
+			specEnabled:     &disabled,
+			statusEnabled:   &enabled,
+			stardogEnabled:  true,
+			expectChange:    true,
+			expectedReason:  v1alpha1.ReasonDisabled,
+			expectedEnabled: false,
+		},
+		{
+			name:            "GivenUserDisabledOutOfBand_WhenSyncing_ThenEnableUserAndReportDrift",
+			statusEnabled:   &enabled,
+			stardogEnabled:  false,
+			expectChange:    true,
+			expectedReason:  v1alpha1.ReasonDrift,
+			expectedEnabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			stardogMocked.EXPECT().IsUserEnabled(gomock.Any(), username).Return(tt.stardogEnabled, nil).Times(1)
+			if tt.expectChange {
+				stardogMocked.EXPECT().SetUserEnabled(gomock.Any(), username, tt.expectedEnabled).Return(nil).Times(1)
+			}
+			stardogUser := createStardogUser("namespace-test", "user-test", "instance-test", "secret-test", []string{})
+			stardogUser.Spec.Enabled = tt.specEnabled
+			stardogUser.Status.Enabled = tt.statusEnabled
+			sur := &StardogUserReconciliation{
+				resource: stardogUser,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+					namespace:  "namespace-test",
+				},
+			}
+			r := StardogUserReconciler{Log: testr.New(t)}
+
+			err := r.syncEnabled(sur, stardogMocked, username)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEnabled, *sur.enabled)
+			condition := sur.reconciliationContext.conditions[v1alpha1.StardogUserEnabled]
+			assert.Equal(t, tt.expectedEnabled, condition.Status == v1.ConditionTrue)
+			assert.Equal(t, tt.expectedReason, condition.Reason)
+		})
+	}
+}
+
+func Test_syncPermissions(t *testing.T) {
+	username := "user"
+	readGraph := stardogapi.Permission{Action: "READ", ResourceType: "named-graph", Resources: []string{"db", "urn:graph"}}
+	writeDB := stardogapi.Permission{Action: "WRITE", ResourceType: "db", Resources: []string{"db"}}
+
+	tests := []struct {
+		name        string
+		permissions []v1alpha1.StardogPermissionSpec
+		existing    []stardogapi.Permission
+		unmanaged   bool
+		expectMocks func(stardogMocked *mock.MockStardogAPI)
+	}{
+		{
+			name:        "GivenNewPermission_WhenSyncing_ThenGrantPermission",
+			permissions: []v1alpha1.StardogPermissionSpec{{Action: "READ", ResourceType: "NAMED-GRAPH", Resources: []string{"db", "urn:graph"}}},
+			existing:    []stardogapi.Permission{},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().AddUserPermission(gomock.Any(), username,
+					stardogapi.Permission{Action: "READ", ResourceType: "NAMED-GRAPH", Resources: []string{"db", "urn:graph"}}).Return(nil).Times(1)
+			},
+		},
+		{
+			name:        "GivenExistingPermissions_WhenSyncing_ThenRevokePermissionsNotInSpec",
+			permissions: []v1alpha1.StardogPermissionSpec{{Action: "READ", ResourceType: "NAMED-GRAPH", Resources: []string{"db", "urn:graph"}}},
+			existing:    []stardogapi.Permission{readGraph, writeDB},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().DeleteUserPermission(gomock.Any(), username, writeDB).Return(nil).Times(1)
+			},
+		},
+		{
+			name:        "GivenEmptyPermissions_WhenSyncing_ThenRevokeAllPermissions",
+			permissions: []v1alpha1.StardogPermissionSpec{},
+			existing:    []stardogapi.Permission{writeDB},
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {
+				stardogMocked.EXPECT().DeleteUserPermission(gomock.Any(), username, writeDB).Return(nil).Times(1)
+			},
+		},
+		{
+			name:        "GivenUnsetPermissions_WhenSyncing_ThenKeepPermissionsUnmanaged",
+			unmanaged:   true,
+			expectMocks: func(stardogMocked *mock.MockStardogAPI) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			if !tt.unmanaged {
+				stardogMocked.EXPECT().GetUserPermissions(gomock.Any(), username).Return(tt.existing, nil).Times(1)
+			}
+			tt.expectMocks(stardogMocked)
+			stardogUser := createStardogUser("namespace-test", "user-test", "instance-test", "secret-test", []string{})
+			stardogUser.Spec.Permissions = tt.permissions
+			sur := &StardogUserReconciliation{
+				resource: stardogUser,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+					namespace:  "namespace-test",
+				},
+			}
+			r := StardogUserReconciler{Log: testr.New(t)}
+
+			err := r.syncPermissions(sur, stardogMocked, username)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_refreshEffectivePermissions(t *testing.T) {
+	username := "user"
+	refreshTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	readDB := stardogapi.Permission{Action: "read", ResourceType: "db", Resources: []string{"db"}}
+	writeGraph := stardogapi.Permission{Action: "write", ResourceType: "named-graph", Resources: []string{"db", "urn:graph"}}
+
+	tests := []struct {
+		name             string
+		userPermissions  []stardogapi.Permission
+		roles            []string
+		rolePermissions  map[string][]stardogapi.Permission
+		expectedGrantors [][]string
+	}{
+		{
+			name:            "GivenEqualPermissions_WhenRefreshing_ThenAttributeToRolesAndUser",
+			userPermissions: []stardogapi.Permission{{Action: "WRITE", ResourceType: "NAMED-GRAPH", Resources: []string{"db", "urn:graph"}}},
+			roles:           []string{"reader", "writer"},
+			rolePermissions: map[string][]stardogapi.Permission{
+				"reader": {readDB},
+				"writer": {readDB, writeGraph},
+			},
+			expectedGrantors: [][]string{{"reader", "writer"}, {"user:user", "writer"}},
+		},
+		{
+			name:  "GivenRoleAddedOutOfBand_WhenRefreshing_ThenAttributeToLiveRole",
+			roles: []string{"out-of-band"},
+			rolePermissions: map[string][]stardogapi.Permission{
+				"out-of-band": {readDB, writeGraph},
+			},
+			expectedGrantors: [][]string{{"out-of-band"}, {"out-of-band"}},
+		},
+		{
+			name:            "GivenWildcardPermissions_WhenRefreshing_ThenAttributeToCoveringRolesAndUser",
+			userPermissions: []stardogapi.Permission{{Action: "write", ResourceType: "named-graph", Resources: []string{"db", "*"}}},
+			roles:           []string{"admin", "reader"},
+			rolePermissions: map[string][]stardogapi.Permission{
+				"admin":  {{Action: "all", ResourceType: "*", Resources: []string{"*"}}},
+				"reader": {{Action: "read", ResourceType: "db", Resources: []string{"*"}}},
+			},
+			expectedGrantors: [][]string{{"admin", "reader"}, {"user:user", "admin"}},
+		},
+		{
+			name:             "GivenNoGrantingPermission_WhenRefreshing_ThenLeaveUnattributed",
+			roles:            []string{"other"},
+			rolePermissions:  map[string][]stardogapi.Permission{"other": {{Action: "read", ResourceType: "db", Resources: []string{"other"}}}},
+			expectedGrantors: [][]string{nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return refreshTime }
+			defer func() { now = time.Now }()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			stardogMocked.EXPECT().GetEffectivePermissions(gomock.Any(), username).Return([]stardogapi.Permission{readDB, writeGraph}, nil).Times(1)
+			stardogMocked.EXPECT().GetUserPermissions(gomock.Any(), username).Return(tt.userPermissions, nil).Times(1)
+			stardogMocked.EXPECT().GetUserRoles(gomock.Any(), username).Return(tt.roles, nil).Times(1)
+			for role, permissions := range tt.rolePermissions {
+				stardogMocked.EXPECT().GetRolePermissions(gomock.Any(), role).Return(permissions, nil).Times(1)
+			}
+			// the spec roles are ignored, the permissions are attributed to the roles the user has in Stardog
+			stardogUser := createStardogUser("namespace-test", "user-test", "instance-test", "secret-test", []string{"reader", "writer"})
+			sur := &StardogUserReconciliation{
+				resource: stardogUser,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+					namespace:  "namespace-test",
+				},
+			}
+			r := StardogUserReconciler{Log: testr.New(t)}
+
+			err := r.refreshEffectivePermissions(sur, stardogMocked, username)
+
+			assert.NoError(t, err)
+			assert.Equal(t, []v1alpha1.EffectivePermission{
+				{Action: "read", ResourceType: "db", Resources: []string{"db"}, GrantedBy: tt.expectedGrantors[0]},
+				{Action: "write", ResourceType: "named-graph", Resources: []string{"db", "urn:graph"}, GrantedBy: tt.expectedGrantors[1]},
+			}, sur.effectivePermissions)
+			assert.Equal(t, metav1.NewTime(refreshTime), *sur.effectivePermissionsRefreshTime)
+		})
+	}
+}
+
+func Test_isSuperuserAllowed(t *testing.T) {
+	namespace := "namespace-test"
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"stardog.vshn.ch/superusers": "true"}}
+
+	tests := []struct {
+		name            string
+		selector        *metav1.LabelSelector
+		namespaceLabels map[string]string
+		expectedAllowed bool
+	}{
+		{
+			name:            "GivenNoSelector_WhenCheckingSuperuser_ThenDeny",
+			namespaceLabels: map[string]string{"stardog.vshn.ch/superusers": "true"},
+			expectedAllowed: false,
+		},
+		{
+			name:            "GivenMatchingNamespace_WhenCheckingSuperuser_ThenAllow",
+			selector:        selector,
+			namespaceLabels: map[string]string{"stardog.vshn.ch/superusers": "true"},
+			expectedAllowed: true,
+		},
+		{
+			name:            "GivenOtherNamespace_WhenCheckingSuperuser_ThenDeny",
+			selector:        selector,
+			expectedAllowed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := createStardogInstance(namespace, "instance-test", "admin-secret", "https://stardog.example.com")
+			instance.Spec.AllowSuperuserFrom = tt.selector
+			ns := createNamespace(namespace)
+			ns.Labels = tt.namespaceLabels
+			fakeKubeClient, err := createKubeFakeClient(instance, ns)
+			assert.NoError(t, err)
+			r := StardogUserReconciler{Client: fakeKubeClient, Log: testr.New(t)}
+
+			allowed, err := r.isSuperuserAllowed(context.Background(), v1beta1.NewStardogInstanceRef("instance-test", namespace), namespace)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedAllowed, allowed)
+		})
+	}
+}
+
+func Test_checkSuperuser(t *testing.T) {
+	username := "user"
+
+	tests := []struct {
+		name               string
+		specSuperuser      bool
+		stardogSuperuser   bool
+		expectedErr        bool
+		expectedUnexpected bool
+	}{
+		{
+			name: "GivenRegularUser_WhenChecking_ThenReportNothing",
+		},
+		{
+			name:               "GivenUserPromotedOutOfBand_WhenChecking_ThenReportUnexpectedSuperuser",
+			stardogSuperuser:   true,
+			expectedUnexpected: true,
+		},
+		{
+			name:             "GivenSuperuser_WhenChecking_ThenReportNothing",
+			specSuperuser:    true,
+			stardogSuperuser: true,
+		},
+		{
+			name:          "GivenExistingRegularUserAsSuperuser_WhenChecking_ThenError",
+			specSuperuser: true,
+			expectedErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			stardogMocked := mock.NewMockStardogAPI(mockCtrl)
+			stardogMocked.EXPECT().IsSuperuser(gomock.Any(), username).Return(tt.stardogSuperuser, nil).Times(1)
+			stardogUser := createStardogUser("namespace-test", "user-test", "instance-test", "secret-test", []string{})
+			stardogUser.Spec.Superuser = tt.specSuperuser
+			sur := &StardogUserReconciliation{
+				resource: stardogUser,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+					namespace:  "namespace-test",
+				},
+			}
+			r := StardogUserReconciler{Log: testr.New(t)}
+
+			err := r.checkSuperuser(sur, stardogMocked, username)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "%v", err)
+			condition := sur.reconciliationContext.conditions[v1alpha1.StardogUnexpectedSuperuser]
+			assert.Equal(t, tt.expectedUnexpected, condition.Status == v1.ConditionTrue)
+		})
+	}
+}
+
+func Test_createGeneratedCredentials(t *testing.T) {
+	namespace := "namespace-test"
+
+	tests := []struct {
+		name             string
+		existingSecret   *v1.Secret
+		username         string
+		usernameKey      string
+		template         *v1alpha1.CredentialSecretTemplate
+		expectedUsername string
+		expectedPassword string
+		expectedType     v1.SecretType
+		expectedEndpoint string
+	}{
+		{
+			name:             "GivenNoSecret_WhenCreatingCredentials_ThenCreateOwnedSecret",
+			expectedUsername: "user-test",
+		},
+		{
+			name:             "GivenNoSecretAndUsername_WhenCreatingCredentials_ThenUseUsername",
+			username:         "service",
+			expectedUsername: "service",
+		},
+		{
+			name:             "GivenSecretTemplate_WhenCreatingCredentials_ThenApplyTypeAndConnectionDetails",
+			template:         &v1alpha1.CredentialSecretTemplate{Type: v1.SecretTypeBasicAuth, Labels: map[string]string{"app": "api"}, ConnectionDetails: true},
+			expectedUsername: "user-test",
+			expectedType:     v1.SecretTypeBasicAuth,
+			expectedEndpoint: "https://stardog.example.com",
+		},
+		{
+			name:             "GivenKeyMapping_WhenCreatingCredentials_ThenUseMappedKeys",
+			usernameKey:      "user",
+			expectedUsername: "user-test",
+		},
+		{
+			name: "GivenExistingSecret_WhenCreatingCredentials_ThenKeepSecret",
+			existingSecret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "user-secret", Namespace: namespace},
+				Data:       map[string][]byte{"username": []byte("existing"), "password": []byte("1234")},
+			},
+			expectedUsername: "existing",
+			expectedPassword: "1234",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stardogUser := createStardogUser(namespace, "user-test", "instance-test", "user-secret", []string{})
+			stardogUser.Spec.Credentials.Generate = true
+			stardogUser.Spec.Credentials.Username = tt.username
+			stardogUser.Spec.Credentials.UsernameKey = tt.usernameKey
+			stardogUser.Spec.Credentials.SecretTemplate = tt.template
+			objects := []runtime.Object{stardogUser, createStardogInstance(namespace, "instance-test", "admin-secret", "https://stardog.example.com/")}
+			if tt.existingSecret != nil {
+				objects = append(objects, tt.existingSecret)
+			}
+			fakeKubeClient, err := createKubeFakeClient(objects...)
+			assert.NoError(t, err)
+			r := StardogUserReconciler{Client: fakeKubeClient, Log: testr.New(t), Scheme: scheme.Scheme}
+			sur := &StardogUserReconciliation{
+				resource: stardogUser,
+				reconciliationContext: &ReconciliationContext{
+					context:    context.Background(),
+					conditions: make(v1alpha1.StardogConditionMap),
+					namespace:  namespace,
+				},
+			}
+
+			err = r.createGeneratedCredentials(sur, v1beta1.NewStardogInstanceRef("instance-test", namespace))
+
+			assert.NoError(t, err)
+			secret := &v1.Secret{}
+			assert.NoError(t, fakeKubeClient.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: "user-secret"}, secret))
+			username, password, err := getUsernameAndPassword(*secret, stardogUser.Spec.Credentials.StardogUserCredentialsSpec)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedUsername, username)
+			if tt.expectedPassword != "" {
+				assert.Equal(t, tt.expectedPassword, password)
+			} else {
+				assert.Len(t, password, 20)
+				assert.True(t, metav1.IsControlledBy(secret, stardogUser))
+			}
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, secret.Type)
+			}
+			assert.Equal(t, tt.expectedEndpoint, string(secret.Data["endpoint"]))
+		})
+	}
+}
+
 func Test_ReconcileUser(t *testing.T) {
 
 	namespace := "namespace-test"
@@ -474,8 +1199,7 @@ func Test_ReconcileStardogUser(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	stardogMocked := stardogmock.NewMockStardogTestClient(mockCtrl)
-	stardogClient := createStardogClientFromMock(stardogMocked)
+	stardogMocked := mock.NewMockStardogAPI(mockCtrl)
 
 	tests := []struct {
 		name            string
@@ -485,7 +1209,7 @@ func Test_ReconcileStardogUser(t *testing.T) {
 		stardogUser     v1alpha1.StardogUser
 		sur             StardogUserReconciliation
 		secret          v1.Secret
-		expectations    []func(stardog_client.Stardog)
+		expectations    []func()
 		expectedResult  ctrl.Result
 	}{
 		{
@@ -497,17 +1221,17 @@ func Test_ReconcileStardogUser(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUserName, stardogInstanceName, secretName, []string{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						RemoveUser(gomock.Any(), gomock.Any())
+						DeleteUser(gomock.Any(), gomock.Any())
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -524,17 +1248,17 @@ func Test_ReconcileStardogUser(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUserName, stardogInstanceName, secretName, []string{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						RemoveUser(gomock.Any(), gomock.Any())
+						DeleteUser(gomock.Any(), gomock.Any())
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -551,18 +1275,18 @@ func Test_ReconcileStardogUser(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUserName, stardogInstanceName, secretName, []string{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
 					stardogMocked.EXPECT().
-						RemoveUser(gomock.Any(), gomock.Any()).
-						Return(users.NewRemoveUserNoContent(), errors.New("cannot delete user"))
+						DeleteUser(gomock.Any(), gomock.Any()).
+						Return(errors.New("cannot delete user"))
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -579,10 +1303,10 @@ func Test_ReconcileStardogUser(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUserName, stardogInstanceName, secretName, []string{}),
 			},
@@ -599,10 +1323,10 @@ func Test_ReconcileStardogUser(t *testing.T) {
 			stardogUser:     *createStardogUser(namespace, stardogUserName, stardogInstanceName, secretName, []string{}),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUserName, stardogInstanceName, secretName, []string{}),
 			},
@@ -620,27 +1344,53 @@ func Test_ReconcileStardogUser(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUserName, stardogInstanceName, secretName, []string{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
+					stardogMocked.EXPECT().
+						ListUsers(gomock.Any()).
+						Return([]string{}, nil)
+				},
+				func() {
+					stardogMocked.EXPECT().
+						AddUser(gomock.Any(), gomock.Any(), gomock.Any())
+				},
+				func() {
 					stardogMocked.EXPECT().
-						ListUsers(gomock.Any(), gomock.Any()).
-						Return(&users.ListUsersOK{Payload: &models.Users{Users: []string{}}}, nil)
+						ValidateUser(gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(true, nil)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						CreateUser(gomock.Any(), gomock.Any())
+						IsUserEnabled(gomock.Any(), gomock.Any()).
+						Return(true, nil)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						ListUserRoles(gomock.Any(), gomock.Any()).
-						Return(&users_roles.ListUserRolesOK{Payload: &models.Roles{Roles: []string{}}}, nil)
+						IsSuperuser(gomock.Any(), gomock.Any()).
+						Return(false, nil)
+				},
+				func() {
+					stardogMocked.EXPECT().
+						GetEffectivePermissions(gomock.Any(), gomock.Any()).
+						Return([]stardogapi.Permission{}, nil)
+				},
+				func() {
+					stardogMocked.EXPECT().
+						GetUserRoles(gomock.Any(), gomock.Any()).
+						Return([]string{}, nil).
+						Times(2)
+				},
+				func() {
+					stardogMocked.EXPECT().
+						GetUserPermissions(gomock.Any(), gomock.Any()).
+						Return([]stardogapi.Permission{}, nil)
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -657,27 +1407,53 @@ func Test_ReconcileStardogUser(t *testing.T) {
 			secret:          *createFullSecret(namespace, secretName, username, password),
 			sur: StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(v1alpha1.StardogConditionMap),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(v1alpha1.StardogConditionMap),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: createStardogUser(namespace, stardogUserName, stardogInstanceName, secretName, []string{}),
 			},
-			expectations: []func(stardog_client.Stardog){
-				func(stardog_client.Stardog) {
+			expectations: []func(){
+				func() {
+					stardogMocked.EXPECT().
+						ListUsers(gomock.Any()).
+						Return([]string{}, nil)
+				},
+				func() {
 					stardogMocked.EXPECT().
-						ListUsers(gomock.Any(), gomock.Any()).
-						Return(&users.ListUsersOK{Payload: &models.Users{Users: []string{}}}, nil)
+						AddUser(gomock.Any(), gomock.Any(), gomock.Any())
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						CreateUser(gomock.Any(), gomock.Any())
+						ValidateUser(gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(true, nil)
 				},
-				func(stardog_client.Stardog) {
+				func() {
 					stardogMocked.EXPECT().
-						ListUserRoles(gomock.Any(), gomock.Any()).
-						Return(&users_roles.ListUserRolesOK{Payload: &models.Roles{Roles: []string{}}}, nil)
+						IsUserEnabled(gomock.Any(), gomock.Any()).
+						Return(true, nil)
+				},
+				func() {
+					stardogMocked.EXPECT().
+						IsSuperuser(gomock.Any(), gomock.Any()).
+						Return(false, nil)
+				},
+				func() {
+					stardogMocked.EXPECT().
+						GetEffectivePermissions(gomock.Any(), gomock.Any()).
+						Return([]stardogapi.Permission{}, nil)
+				},
+				func() {
+					stardogMocked.EXPECT().
+						GetUserRoles(gomock.Any(), gomock.Any()).
+						Return([]string{}, nil).
+						Times(2)
+				},
+				func() {
+					stardogMocked.EXPECT().
+						GetUserPermissions(gomock.Any(), gomock.Any()).
+						Return([]stardogapi.Permission{}, nil)
 				},
 			},
 			expectedResult: ctrl.Result{
@@ -699,17 +1475,16 @@ func Test_ReconcileStardogUser(t *testing.T) {
 			}
 			sur := &StardogUserReconciliation{
 				reconciliationContext: &ReconciliationContext{
-					context:       context.Background(),
-					conditions:    make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
-					namespace:     namespace,
-					stardogClient: stardogClient,
+					context:        context.Background(),
+					conditions:     make(map[v1alpha1.StardogConditionType]v1alpha1.StardogCondition),
+					namespace:      namespace,
+					stardogClients: createStardogClientPoolFromMock(stardogMocked),
 				},
 				resource: &tt.stardogUser,
 			}
 			for _, addExpectation := range tt.expectations {
-				addExpectation(*tt.sur.reconciliationContext.stardogClient)
+				addExpectation()
 			}
-			stardogMocked.EXPECT().SetTransport(gomock.Any()).AnyTimes()
 
 			result, err := r.ReconcileStardogUser(sur)
 
@@ -724,9 +1499,11 @@ func createStardogUser(namespace, stardogUserName, stardogInstanceRef, secretRef
 		ObjectMeta: metav1.ObjectMeta{Name: stardogUserName, Namespace: namespace},
 		Spec: v1alpha1.StardogUserSpec{
 			StardogInstanceRef: stardogInstanceRef,
-			Credentials: v1alpha1.StardogUserCredentialsSpec{
-				Namespace: namespace,
-				SecretRef: secretRef,
+			Credentials: v1alpha1.StardogUserGeneratedCredentialsSpec{
+				StardogUserCredentialsSpec: v1alpha1.StardogUserCredentialsSpec{
+					Namespace: namespace,
+					SecretRef: secretRef,
+				},
 			},
 			Roles: roles,
 		},
@@ -2,8 +2,9 @@ package controllers
 
 import (
 	"context"
-	stardog_client "github.com/vshn/stardog-userrole-operator/stardogrest/client"
-	stardogmock "github.com/vshn/stardog-userrole-operator/stardogrest/mocks"
+	"crypto/tls"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi/mock"
 	"path/filepath"
 	"testing"
 
@@ -17,6 +18,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	stardogv1alpha1 "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
 	stardogv1beta1 "github.com/vshn/stardog-userrole-operator/api/v1beta1"
 	//+kubebuilder:scaffold:imports
 )
@@ -69,9 +71,10 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&DatabaseReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
-		Log:    k8sManager.GetLogger(),
+		Client:         k8sManager.GetClient(),
+		Scheme:         k8sManager.GetScheme(),
+		Log:            k8sManager.GetLogger(),
+		StardogClients: NewStardogClientPool(NewStardogClient),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -89,14 +92,8 @@ var _ = AfterSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 })
 
-func createStardogClientFromMock(mockedClient *stardogmock.MockStardogTestClient) *stardog_client.Stardog {
-	return &stardog_client.Stardog{
-		Db:               mockedClient,
-		Roles:            mockedClient,
-		RolesPermissions: mockedClient,
-		Users:            mockedClient,
-		UsersPermissions: mockedClient,
-		UsersRoles:       mockedClient,
-		Transport:        mockedClient,
-	}
+func createStardogClientPoolFromMock(mockedClient *mock.MockStardogAPI) *StardogClientPool {
+	return NewStardogClientPool(func(_ stardogv1alpha1.StardogInstance, _, _ string, _ *tls.Config) (stardogapi.StardogAPI, error) {
+		return mockedClient, nil
+	})
 }
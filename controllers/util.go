@@ -2,16 +2,11 @@ package controllers
 
 import (
 	"fmt"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/db"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/roles_permissions"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_permissions"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/client/users_roles"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
 	"os"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,14 +16,18 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-type StardogResponse interface {
-	IsCode(code int) bool
-}
-
 var (
 	ReconFreqErr         = time.Second * 30
 	ReconFreq            = time.Duration(0)
 	disabledEnvironments = ""
+	// StatsFreq is the interval in which the statistics of databases are refreshed, 0 disables the refresh. It is
+	// disabled by default, as Databases are requeued in this interval to refresh their statistics.
+	StatsFreq = time.Duration(0)
+	// MaxConcurrentBackups is the number of DatabaseBackups that are reconciled in parallel. A backup blocks its
+	// worker until the Stardog server has finished it, which can take up to the LongRunningRequestTimeout.
+	MaxConcurrentBackups = 4
+	// now returns the current time, it is replaced in tests
+	now = time.Now
 )
 
 // InitEnv initialize env variables
@@ -40,6 +39,15 @@ func InitEnv() {
 		ReconFreq = 0
 		ReconFreqErr = 0
 	}
+	if timeout, err := time.ParseDuration(os.Getenv("STARDOG_LONG_RUNNING_REQUEST_TIMEOUT")); err == nil && timeout > 0 {
+		stardogapi.LongRunningRequestTimeout = timeout
+	}
+	if statsFreq, err := time.ParseDuration(os.Getenv("DATABASE_STATISTICS_FREQUENCY")); err == nil {
+		StatsFreq = max(statsFreq, 0)
+	}
+	if backups, err := strconv.Atoi(os.Getenv("MAX_CONCURRENT_BACKUPS")); err == nil && backups > 0 {
+		MaxConcurrentBackups = backups
+	}
 }
 
 // createStatusConditionReady is a shortcut for adding a StardogReady condition.
@@ -92,6 +100,113 @@ func createStatusConditionTerminating(err error) StardogCondition {
 	}
 }
 
+// createStatusConditionTerminatingWithReason is a shortcut for adding a StardogTerminating condition with the given
+// reason and error message.
+func createStatusConditionTerminatingWithReason(reason string, err error) StardogCondition {
+	condition := createStatusConditionTerminating(err)
+	condition.Reason = reason
+	return condition
+}
+
+// createStatusConditionDeletionBlocked is a shortcut for adding a StardogDeletionBlocked condition with the given error
+// message.
+func createStatusConditionDeletionBlocked(err error) StardogCondition {
+	return StardogCondition{
+		Status:             v1.ConditionTrue,
+		Type:               StardogDeletionBlocked,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonProtected,
+		Message:            err.Error(),
+	}
+}
+
+// errDeletionProtected is returned by the finalizers of resources which are protected by the
+// DeletionProtectionAnnotation
+var errDeletionProtected = fmt.Errorf("deletion is blocked by the annotation %s=true, remove it to proceed", DeletionProtectionAnnotation)
+
+// createStatusConditionOptionsSynchronized is a shortcut for adding a StardogOptionsSynchronized condition.
+func createStatusConditionOptionsSynchronized(synchronized bool, message string) StardogCondition {
+	condition := StardogCondition{
+		Status:             v1.ConditionTrue,
+		Type:               StardogOptionsSynchronized,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSucceeded,
+		Message:            message,
+	}
+	if !synchronized {
+		condition.Status = v1.ConditionFalse
+		condition.Reason = ReasonPending
+	}
+	return condition
+}
+
+// createStatusConditionCredentialsSynced is a shortcut for adding a StardogCredentialsSynced condition. The
+// transition time is the time the password has been changed last.
+func createStatusConditionCredentialsSynced(synced bool, lastRotationTime metav1.Time, message string) StardogCondition {
+	condition := StardogCondition{
+		Status:             v1.ConditionTrue,
+		Type:               StardogCredentialsSynced,
+		LastTransitionTime: lastRotationTime,
+		Reason:             ReasonSucceeded,
+		Message:            message,
+	}
+	if !synced {
+		condition.Status = v1.ConditionFalse
+		condition.Reason = ReasonFailed
+	}
+	return condition
+}
+
+// createStatusConditionUserEnabled is a shortcut for adding a StardogUserEnabled condition. A drifted state has been
+// changed in Stardog out of band and was reverted to the specified one.
+func createStatusConditionUserEnabled(enabled, drifted bool) StardogCondition {
+	condition := StardogCondition{
+		Status:             v1.ConditionTrue,
+		Type:               StardogUserEnabled,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonEnabled,
+		Message:            "User is enabled",
+	}
+	if !enabled {
+		condition.Status = v1.ConditionFalse
+		condition.Reason = ReasonDisabled
+		condition.Message = "User is disabled"
+	}
+	if drifted {
+		condition.Reason = ReasonDrift
+		condition.Message += ", it had been changed in Stardog out of band"
+	}
+	return condition
+}
+
+// createStatusConditionUnexpectedSuperuser is a shortcut for adding a StardogUnexpectedSuperuser condition
+func createStatusConditionUnexpectedSuperuser(username string) StardogCondition {
+	return StardogCondition{
+		Status:             v1.ConditionTrue,
+		Type:               StardogUnexpectedSuperuser,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSuperuser,
+		Message:            fmt.Sprintf("User %s is a superuser in Stardog, it has probably been promoted out of band", username),
+	}
+}
+
+// setCredentialsRecovered adds a StardogCredentialsRecovered condition for the given Secret and users, the messages of
+// multiple Secrets are joined.
+func (rc *ReconciliationContext) setCredentialsRecovered(namespace, secretName string, users []string) {
+	message := fmt.Sprintf("Credential Secret %s/%s was missing or incomplete, the passwords of %s have been reset",
+		namespace, secretName, strings.Join(users, ", "))
+	if existing, ok := rc.conditions[StardogCredentialsRecovered]; ok {
+		message = existing.Message + "; " + message
+	}
+	rc.SetStatusCondition(StardogCondition{
+		Status:             v1.ConditionTrue,
+		Type:               StardogCredentialsRecovered,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonReset,
+		Message:            message,
+	})
+}
+
 func createInstanceStatusAvailableCondition(message string) metav1.Condition {
 	return metav1.Condition{
 		Type:               "Available",
@@ -141,6 +256,16 @@ func mapConditionsToTypeAndDisableStatus(conditions []StardogCondition) (m Stard
 	return m
 }
 
+// isConditionTrue returns true if the conditions contain a condition of the given type with the status true
+func isConditionTrue(conditions []StardogCondition, conditionType StardogConditionType) bool {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func missingAtLeastOne(list []string, strings ...string) bool {
 	for _, s := range strings {
 		if !contains(list, s) {
@@ -159,7 +284,7 @@ func contains(list []string, s string) bool {
 	return false
 }
 
-func containsStardogPermission(permissionsTypeB []StardogPermissionSpec, permissionTypeA models.Permission) bool {
+func containsStardogPermission(permissionsTypeB []StardogPermissionSpec, permissionTypeA stardogapi.Permission) bool {
 	for _, permissionTypeB := range permissionsTypeB {
 		if equals(permissionTypeA, permissionTypeB) {
 			return true
@@ -168,54 +293,77 @@ func containsStardogPermission(permissionsTypeB []StardogPermissionSpec, permiss
 	return false
 }
 
-func containsOperatorPermission(permissionsTypeA []*models.Permission, permissionTypeB StardogPermissionSpec) bool {
+func containsOperatorPermission(permissionsTypeA []stardogapi.Permission, permissionTypeB StardogPermissionSpec) bool {
 	for _, permissionTypeA := range permissionsTypeA {
-		if equals(*permissionTypeA, permissionTypeB) {
+		if equals(permissionTypeA, permissionTypeB) {
 			return true
 		}
 	}
 	return false
 }
 
-func containsPermission(permissionsA []*models.Permission, permissionB models.Permission) bool {
+func containsPermission(permissionsA []stardogapi.Permission, permissionB stardogapi.Permission) bool {
 	for _, permissionA := range permissionsA {
-		if reflect.DeepEqual(*permissionA, permissionB) {
+		if reflect.DeepEqual(permissionA, permissionB) {
 			return true
 		}
 	}
 	return false
 }
 
-func equals(permissionTypeA models.Permission, permissionTypeB StardogPermissionSpec) bool {
-	var action bool
-	if permissionTypeA.Action == nil {
-		if permissionTypeB.Action != "" {
-			return false
+// containsEqualPermission returns whether the permissions contain the permission, ignoring the case of the action and
+// resource type
+func containsEqualPermission(permissions []stardogapi.Permission, permission stardogapi.Permission) bool {
+	for _, p := range permissions {
+		if equals(p, StardogPermissionSpec{Action: permission.Action, ResourceType: permission.ResourceType, Resources: permission.Resources}) {
+			return true
 		}
-		action = true
-	} else {
-		action = strings.EqualFold(*permissionTypeA.Action, permissionTypeB.Action)
 	}
+	return false
+}
 
-	var resourceType bool
-	if permissionTypeA.ResourceType == nil {
-		if permissionTypeB.ResourceType != "" {
-			return false
+// containsGrantingPermission returns true if one of the permissions grants the given permission, either because it is
+// equal or because it covers it with Stardog wildcards
+func containsGrantingPermission(permissions []stardogapi.Permission, permission stardogapi.Permission) bool {
+	for _, p := range permissions {
+		if grants(p, permission) {
+			return true
 		}
-		resourceType = true
-	} else {
-		resourceType = strings.EqualFold(*permissionTypeA.ResourceType, permissionTypeB.ResourceType)
 	}
+	return false
+}
 
-	var resources bool
-	if permissionTypeA.Resource == nil {
-		if permissionTypeB.Resources != nil {
+// grants returns true if the granting permission covers the given permission. Stardog treats the action "all", the
+// resource type "*" and the resource "*" as wildcards.
+func grants(granting stardogapi.Permission, permission stardogapi.Permission) bool {
+	if !strings.EqualFold(granting.Action, permission.Action) && !isWildcardAction(granting.Action) {
+		return false
+	}
+	if !strings.EqualFold(granting.ResourceType, permission.ResourceType) && granting.ResourceType != "*" {
+		return false
+	}
+	if len(granting.Resources) == 1 && granting.Resources[0] == "*" {
+		return true
+	}
+	if len(granting.Resources) != len(permission.Resources) {
+		return false
+	}
+	for i, resource := range granting.Resources {
+		if resource != permission.Resources[i] && resource != "*" {
 			return false
 		}
-		resources = true
-	} else {
-		resources = reflect.DeepEqual(permissionTypeA.Resource, permissionTypeB.Resources)
 	}
+	return true
+}
+
+func isWildcardAction(action string) bool {
+	return strings.EqualFold(action, "all") || action == "*"
+}
+
+func equals(permissionTypeA stardogapi.Permission, permissionTypeB StardogPermissionSpec) bool {
+	action := strings.EqualFold(permissionTypeA.Action, permissionTypeB.Action)
+	resourceType := strings.EqualFold(permissionTypeA.ResourceType, permissionTypeB.ResourceType)
+	resources := reflect.DeepEqual(permissionTypeA.Resources, permissionTypeB.Resources)
 
 	return action && resourceType && resources
 }
@@ -238,17 +386,6 @@ func containsStardogInstanceRef(refs []stardogv1beta1.StardogInstanceRef, ref st
 	return false
 }
 
-func NotFound(err error) bool {
-	errType := reflect.TypeOf(err).String()
-	return errType == reflect.TypeOf(roles_permissions.NewRemoveRolePermissionNotFound()).String() ||
-		errType == reflect.TypeOf(users_roles.NewRemoveRoleOfUserNotFound()).String() ||
-		errType == reflect.TypeOf(db.NewDropDatabaseNotFound()).String() ||
-		errType == reflect.TypeOf(roles.NewRemoveRoleNotFound()).String() ||
-		errType == reflect.TypeOf(users.NewRemoveUserNotFound()).String() ||
-		errType == reflect.TypeOf(users_permissions.NewRemoveUserPermissionNotFound()).String() ||
-		errType == reflect.TypeOf(db.NewGetDBSizeNotFound()).String()
-}
-
 func environmentDisabled(object client.Object) bool {
 	if disabledEnvironments == "" {
 		return false
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"github.com/stretchr/testify/assert"
 	. "github.com/vshn/stardog-userrole-operator/api/v1alpha1"
-	"github.com/vshn/stardog-userrole-operator/stardogrest/models"
+	"github.com/vshn/stardog-userrole-operator/pkg/stardogapi"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"os"
@@ -320,16 +320,16 @@ func Test_equals(t *testing.T) {
 
 	tests := []struct {
 		name            string
-		permissionTypeA models.Permission
+		permissionTypeA stardogapi.Permission
 		permissionTypeB StardogPermissionSpec
 		expectValue     bool
 	}{
 		{
 			name: "GivenEqualTypes_ThenReturnTrue",
-			permissionTypeA: models.Permission{
-				Action:       &actionRead,
-				ResourceType: &resourceTypeAll,
-				Resource:     resourcesX,
+			permissionTypeA: stardogapi.Permission{
+				Action:       actionRead,
+				ResourceType: resourceTypeAll,
+				Resources:    resourcesX,
 			},
 			permissionTypeB: StardogPermissionSpec{
 				Action:       actionRead,
@@ -340,10 +340,10 @@ func Test_equals(t *testing.T) {
 		},
 		{
 			name: "GivenNonEqualTypes1_ThenReturnFalse",
-			permissionTypeA: models.Permission{
-				Action:       &actionRead,
-				ResourceType: &resourceTypeAll,
-				Resource:     resourcesX,
+			permissionTypeA: stardogapi.Permission{
+				Action:       actionRead,
+				ResourceType: resourceTypeAll,
+				Resources:    resourcesX,
 			},
 			permissionTypeB: StardogPermissionSpec{
 				Action:       actionRead,
@@ -354,10 +354,10 @@ func Test_equals(t *testing.T) {
 		},
 		{
 			name: "GivenNonEqualTypes2_ThenReturnFalse",
-			permissionTypeA: models.Permission{
-				Action:       &actionRead,
-				ResourceType: &resourceTypeAll,
-				Resource:     resourcesX,
+			permissionTypeA: stardogapi.Permission{
+				Action:       actionRead,
+				ResourceType: resourceTypeAll,
+				Resources:    resourcesX,
 			},
 			permissionTypeB: StardogPermissionSpec{
 				Action:       actionWrite,
@@ -368,9 +368,9 @@ func Test_equals(t *testing.T) {
 		},
 		{
 			name: "GivenMissingAttribute1_ThenReturnFalse",
-			permissionTypeA: models.Permission{
-				ResourceType: &resourceTypeAll,
-				Resource:     resourcesX,
+			permissionTypeA: stardogapi.Permission{
+				ResourceType: resourceTypeAll,
+				Resources:    resourcesX,
 			},
 			permissionTypeB: StardogPermissionSpec{
 				Action:       actionWrite,
@@ -381,10 +381,10 @@ func Test_equals(t *testing.T) {
 		},
 		{
 			name: "GivenMissingAttribute2_ThenReturnFalse",
-			permissionTypeA: models.Permission{
-				Action:       &actionRead,
-				ResourceType: &resourceTypeAll,
-				Resource:     resourcesX,
+			permissionTypeA: stardogapi.Permission{
+				Action:       actionRead,
+				ResourceType: resourceTypeAll,
+				Resources:    resourcesX,
 			},
 			permissionTypeB: StardogPermissionSpec{
 				Action:       actionWrite,
@@ -394,9 +394,9 @@ func Test_equals(t *testing.T) {
 		},
 		{
 			name: "GivenMissingAttribute3_ThenReturnFalse",
-			permissionTypeA: models.Permission{
-				Action:       &actionWrite,
-				ResourceType: &resourceTypeAll,
+			permissionTypeA: stardogapi.Permission{
+				Action:       actionWrite,
+				ResourceType: resourceTypeAll,
 			},
 			permissionTypeB: StardogPermissionSpec{
 				Action:       actionWrite,
@@ -413,6 +413,74 @@ func Test_equals(t *testing.T) {
 	}
 }
 
+func Test_grants(t *testing.T) {
+
+	readDB := stardogapi.Permission{Action: "read", ResourceType: "db", Resources: []string{"db"}}
+	writeGraph := stardogapi.Permission{Action: "write", ResourceType: "named-graph", Resources: []string{"db", "urn:graph"}}
+
+	tests := []struct {
+		name        string
+		granting    stardogapi.Permission
+		permission  stardogapi.Permission
+		expectValue bool
+	}{
+		{
+			name:        "GivenEqualPermission_ThenReturnTrue",
+			granting:    stardogapi.Permission{Action: "READ", ResourceType: "DB", Resources: []string{"db"}},
+			permission:  readDB,
+			expectValue: true,
+		},
+		{
+			name:        "GivenOtherResource_ThenReturnFalse",
+			granting:    stardogapi.Permission{Action: "read", ResourceType: "db", Resources: []string{"other"}},
+			permission:  readDB,
+			expectValue: false,
+		},
+		{
+			name:        "GivenOtherAction_ThenReturnFalse",
+			granting:    stardogapi.Permission{Action: "write", ResourceType: "db", Resources: []string{"db"}},
+			permission:  readDB,
+			expectValue: false,
+		},
+		{
+			name:        "GivenActionAll_ThenReturnTrue",
+			granting:    stardogapi.Permission{Action: "ALL", ResourceType: "db", Resources: []string{"db"}},
+			permission:  readDB,
+			expectValue: true,
+		},
+		{
+			name:        "GivenWildcardResourceTypeAndResource_ThenReturnTrue",
+			granting:    stardogapi.Permission{Action: "write", ResourceType: "*", Resources: []string{"*"}},
+			permission:  writeGraph,
+			expectValue: true,
+		},
+		{
+			name:        "GivenWildcardGraphOfDatabase_ThenReturnTrue",
+			granting:    stardogapi.Permission{Action: "write", ResourceType: "named-graph", Resources: []string{"db", "*"}},
+			permission:  writeGraph,
+			expectValue: true,
+		},
+		{
+			name:        "GivenWildcardGraphOfOtherDatabase_ThenReturnFalse",
+			granting:    stardogapi.Permission{Action: "write", ResourceType: "named-graph", Resources: []string{"other", "*"}},
+			permission:  writeGraph,
+			expectValue: false,
+		},
+		{
+			name:        "GivenWildcardResourceOfOtherType_ThenReturnFalse",
+			granting:    stardogapi.Permission{Action: "read", ResourceType: "role", Resources: []string{"*"}},
+			permission:  readDB,
+			expectValue: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := grants(tt.granting, tt.permission)
+			assert.Equal(t, tt.expectValue, result)
+		})
+	}
+}
+
 func Test_containsStardogPermission(t *testing.T) {
 
 	actionRead := "READ"
@@ -426,16 +494,16 @@ func Test_containsStardogPermission(t *testing.T) {
 
 	tests := []struct {
 		name             string
-		permissionTypeA  models.Permission
+		permissionTypeA  stardogapi.Permission
 		permissionTypesB []StardogPermissionSpec
 		expectValue      bool
 	}{
 		{
 			name: "GivenAListOfStardogPermissionSpec_WhenPermissionExists_ThenReturnTrue",
-			permissionTypeA: models.Permission{
-				Action:       &actionRead,
-				ResourceType: &resourceTypeAll,
-				Resource:     resourcesX,
+			permissionTypeA: stardogapi.Permission{
+				Action:       actionRead,
+				ResourceType: resourceTypeAll,
+				Resources:    resourcesX,
 			},
 			permissionTypesB: []StardogPermissionSpec{
 				{
@@ -468,7 +536,7 @@ func Test_containsStardogPermission(t *testing.T) {
 		},
 		{
 			name:            "GivenAListOfStardogPermissionSpec_WhenPermissionIsEmpty_ThenReturnFalse",
-			permissionTypeA: models.Permission{},
+			permissionTypeA: stardogapi.Permission{},
 			permissionTypesB: []StardogPermissionSpec{
 				{
 					Action:       actionRead,
@@ -521,7 +589,7 @@ func Test_containsOperatorPermission(t *testing.T) {
 	tests := []struct {
 		name             string
 		permissionTypeA  StardogPermissionSpec
-		permissionTypesB []*models.Permission
+		permissionTypesB []stardogapi.Permission
 		expectValue      bool
 	}{
 		{
@@ -531,31 +599,31 @@ func Test_containsOperatorPermission(t *testing.T) {
 				ResourceType: resourceTypeAll,
 				Resources:    resourcesX,
 			},
-			permissionTypesB: []*models.Permission{
+			permissionTypesB: []stardogapi.Permission{
 				{
-					Action:       &actionRead,
-					ResourceType: &resourceTypeGraph,
-					Resource:     resourcesX,
+					Action:       actionRead,
+					ResourceType: resourceTypeGraph,
+					Resources:    resourcesX,
 				},
 				{
-					Action:       &actionAll,
-					ResourceType: &resourceTypeDB,
-					Resource:     resourcesY,
+					Action:       actionAll,
+					ResourceType: resourceTypeDB,
+					Resources:    resourcesY,
 				},
 				{
-					Action:       &actionRead,
-					ResourceType: &resourceTypeAll,
-					Resource:     resourcesX,
+					Action:       actionRead,
+					ResourceType: resourceTypeAll,
+					Resources:    resourcesX,
 				},
 				{
-					Action:       &actionAll,
-					ResourceType: &resourceTypeAll,
-					Resource:     resourcesY,
+					Action:       actionAll,
+					ResourceType: resourceTypeAll,
+					Resources:    resourcesY,
 				},
 				{
-					Action:       &actionWrite,
-					ResourceType: &resourceTypeGraph,
-					Resource:     resourcesY,
+					Action:       actionWrite,
+					ResourceType: resourceTypeGraph,
+					Resources:    resourcesY,
 				},
 			},
 			expectValue: true,
@@ -563,31 +631,31 @@ func Test_containsOperatorPermission(t *testing.T) {
 		{
 			name:            "GivenAListOfPermission_WhenStardogPermissionSpecIsEmpty_ThenReturnFalse",
 			permissionTypeA: StardogPermissionSpec{},
-			permissionTypesB: []*models.Permission{
+			permissionTypesB: []stardogapi.Permission{
 				{
-					Action:       &actionRead,
-					ResourceType: &resourceTypeGraph,
-					Resource:     resourcesX,
+					Action:       actionRead,
+					ResourceType: resourceTypeGraph,
+					Resources:    resourcesX,
 				},
 				{
-					Action:       &actionAll,
-					ResourceType: &resourceTypeDB,
-					Resource:     resourcesY,
+					Action:       actionAll,
+					ResourceType: resourceTypeDB,
+					Resources:    resourcesY,
 				},
 				{
-					Action:       &actionRead,
-					ResourceType: &resourceTypeAll,
-					Resource:     resourcesX,
+					Action:       actionRead,
+					ResourceType: resourceTypeAll,
+					Resources:    resourcesX,
 				},
 				{
-					Action:       &actionAll,
-					ResourceType: &resourceTypeAll,
-					Resource:     resourcesY,
+					Action:       actionAll,
+					ResourceType: resourceTypeAll,
+					Resources:    resourcesY,
 				},
 				{
-					Action:       &actionWrite,
-					ResourceType: &resourceTypeGraph,
-					Resource:     resourcesY,
+					Action:       actionWrite,
+					ResourceType: resourceTypeGraph,
+					Resources:    resourcesY,
 				},
 			},
 			expectValue: false,
@@ -683,29 +751,37 @@ func Test_init(t *testing.T) {
 		name                    string
 		reconFreqErr            string
 		reconFreq               string
+		maxConcurrentBackups    string
 		expectedReconFreqErrDur time.Duration
 		expectedReconFreqDur    time.Duration
+		expectedConcurrency     int
 	}{
 		{
 			name:                    "GiveReconFreq_WhenIsCorrectPopulated_ThenReturnDuration",
 			reconFreqErr:            "1s",
 			reconFreq:               "1h",
+			maxConcurrentBackups:    "8",
 			expectedReconFreqErrDur: time.Second,
 			expectedReconFreqDur:    time.Hour,
+			expectedConcurrency:     8,
 		},
 		{
 			name:                    "GiveReconFreq_WhenIsNotParsable_ThenReturn0Duration",
 			reconFreqErr:            "1asd",
 			reconFreq:               "1d",
+			maxConcurrentBackups:    "many",
 			expectedReconFreqErrDur: 0,
 			expectedReconFreqDur:    0,
+			expectedConcurrency:     4,
 		},
 		{
 			name:                    "GiveReconFreq_WhenIsNegativeValue_ThenReturn0Duration",
 			reconFreqErr:            "-24s",
 			reconFreq:               "-1h",
+			maxConcurrentBackups:    "-1",
 			expectedReconFreqErrDur: 0,
 			expectedReconFreqDur:    0,
+			expectedConcurrency:     4,
 		},
 	}
 
@@ -713,9 +789,12 @@ func Test_init(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			_ = os.Setenv("RECONCILIATION_FREQUENCY_ON_ERROR", tt.reconFreqErr)
 			_ = os.Setenv("RECONCILIATION_FREQUENCY", tt.reconFreq)
+			_ = os.Setenv("MAX_CONCURRENT_BACKUPS", tt.maxConcurrentBackups)
+			MaxConcurrentBackups = 4
 			InitEnv()
 			assert.Equal(t, tt.expectedReconFreqDur, ReconFreq)
 			assert.Equal(t, tt.expectedReconFreqErrDur, ReconFreqErr)
+			assert.Equal(t, tt.expectedConcurrency, MaxConcurrentBackups)
 		})
 	}
 }
@@ -55,46 +55,137 @@ func main() {
 		os.Exit(1)
 	}
 
+	stardogClients := controllers.NewStardogClientPool(controllers.NewStardogClient)
+
 	if err = (&controllers.StardogRoleReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("StardogRole"),
-		Scheme: mgr.GetScheme(),
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("StardogRole"),
+		Scheme:         mgr.GetScheme(),
+		StardogClients: stardogClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "StardogRole")
 		os.Exit(1)
 	}
 	if err = (&controllers.StardogUserReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("StardogUser"),
-		Scheme: mgr.GetScheme(),
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("StardogUser"),
+		Scheme:         mgr.GetScheme(),
+		StardogClients: stardogClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "StardogUser")
 		os.Exit(1)
 	}
 	if err = (&controllers.StardogInstanceReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("StardogInstance"),
-		Scheme: mgr.GetScheme(),
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("StardogInstance"),
+		Scheme:         mgr.GetScheme(),
+		StardogClients: stardogClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "StardogInstance")
 		os.Exit(1)
 	}
 	if err = (&controllers.DatabaseReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Database"),
-		Scheme: mgr.GetScheme(),
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("Database"),
+		Scheme:         mgr.GetScheme(),
+		Recorder:       mgr.GetEventRecorderFor("database-controller"),
+		StardogClients: stardogClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Database")
 		os.Exit(1)
 	}
 	if err = (&controllers.OrganizationReconciler{
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("Organization"),
+		Scheme:         mgr.GetScheme(),
+		StardogClients: stardogClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Organization")
+		os.Exit(1)
+	}
+	if err = (&controllers.DatabaseMigrationReconciler{
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("DatabaseMigration"),
+		Scheme:         mgr.GetScheme(),
+		StardogClients: stardogClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DatabaseMigration")
+		os.Exit(1)
+	}
+	if err = (&controllers.DatabaseBackupReconciler{
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("DatabaseBackup"),
+		Scheme:         mgr.GetScheme(),
+		StardogClients: stardogClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DatabaseBackup")
+		os.Exit(1)
+	}
+	if err = (&controllers.DatabaseBackupScheduleReconciler{
 		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Organization"),
+		Log:    ctrl.Log.WithName("controllers").WithName("DatabaseBackupSchedule"),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Organization")
+		setupLog.Error(err, "unable to create controller", "controller", "DatabaseBackupSchedule")
+		os.Exit(1)
+	}
+	if err = (&controllers.ArchivedDatabaseReconciler{
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("ArchivedDatabase"),
+		Scheme:         mgr.GetScheme(),
+		StardogClients: stardogClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ArchivedDatabase")
 		os.Exit(1)
 	}
+	if err = (&controllers.DatabaseRestoreReconciler{
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("DatabaseRestore"),
+		Scheme:         mgr.GetScheme(),
+		StardogClients: stardogClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DatabaseRestore")
+		os.Exit(1)
+	}
+
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&stardogv1alpha1.StardogInstance{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "StardogInstance")
+			os.Exit(1)
+		}
+		if err = (&stardogv1alpha1.StardogRole{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "StardogRole")
+			os.Exit(1)
+		}
+		if err = (&stardogv1alpha1.StardogUser{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "StardogUser")
+			os.Exit(1)
+		}
+		if err = (&stardogv1beta1.Database{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Database")
+			os.Exit(1)
+		}
+		if err = (&stardogv1beta1.Organization{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Organization")
+			os.Exit(1)
+		}
+		if err = (&stardogv1beta1.DatabaseMigration{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DatabaseMigration")
+			os.Exit(1)
+		}
+		if err = (&stardogv1beta1.DatabaseBackup{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DatabaseBackup")
+			os.Exit(1)
+		}
+		if err = (&stardogv1beta1.DatabaseBackupSchedule{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DatabaseBackupSchedule")
+			os.Exit(1)
+		}
+		if err = (&stardogv1beta1.DatabaseRestore{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DatabaseRestore")
+			os.Exit(1)
+		}
+	}
 
 	// +kubebuilder:scaffold:builder
 
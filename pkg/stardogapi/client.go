@@ -3,7 +3,9 @@ package stardogapi
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -11,12 +13,21 @@ import (
 	"time"
 )
 
+// requestTimeout is the timeout of regular requests to the Stardog API
+const requestTimeout = time.Second * 30
+
+// LongRunningRequestTimeout is the timeout of requests which take longer than regular requests, e.g. backups and
+// restores of databases, which run synchronously on the Stardog server
+var LongRunningRequestTimeout = time.Hour * 6
+
 // Client holds an HTTPClient and connectivity information
 type Client struct {
 	BaseURL    string
 	Username   string
 	Password   string
 	HTTPClient *http.Client
+	// LongRunningHTTPClient shares the connection pool of the HTTPClient, but uses the LongRunningRequestTimeout
+	LongRunningHTTPClient *http.Client
 }
 
 // errorResponse is an internal struct to decode Stardog error messages
@@ -25,19 +36,66 @@ type errorResponse struct {
 	Code    string `json:"code"`
 }
 
-// Create a new API Client
+// Error is returned for every response of the Stardog API with an unsuccessful status code
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unknown error with status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("error from stardog (status: %d): %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if the error is an Error of the Stardog API with the status code 404
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Create a new API Client with its own connection pool
 func NewClient(username, password, baseURL string) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
 	return &Client{BaseURL: baseURL,
 		Username: username,
 		Password: password,
 		HTTPClient: &http.Client{
-			Timeout: time.Second * 30,
+			Timeout:   requestTimeout,
+			Transport: transport,
+		},
+		LongRunningHTTPClient: &http.Client{
+			Timeout:   LongRunningRequestTimeout,
+			Transport: transport,
 		},
 	}
 }
 
+// Create a new API Client which uses the given TLS configuration for HTTPS connections
+func NewClientWithTLSConfig(username, password, baseURL string, tlsConfig *tls.Config) *Client {
+	c := NewClient(username, password, baseURL)
+	c.HTTPClient.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+	return c
+}
+
+// CloseIdleConnections closes the idle keep-alive connections of the client, e.g. once it is no longer used
+func (c *Client) CloseIdleConnections() {
+	c.HTTPClient.CloseIdleConnections()
+}
+
 // Send an HTTP request to the Stardog server and decode the JSON response (incl. JSON errors)
 func (c *Client) sendRequest(ctx context.Context, method string, path string, body any, response any) error {
+	return c.sendRequestWithClient(ctx, c.HTTPClient, method, path, body, response)
+}
+
+// Send an HTTP request which may take longer than the timeout of regular requests to the Stardog server and decode the
+// JSON response (incl. JSON errors)
+func (c *Client) sendLongRunningRequest(ctx context.Context, method string, path string, body any, response any) error {
+	return c.sendRequestWithClient(ctx, c.LongRunningHTTPClient, method, path, body, response)
+}
+
+func (c *Client) sendRequestWithClient(ctx context.Context, httpClient *http.Client, method string, path string, body any, response any) error {
 	bodyBuffer := &bytes.Buffer{}
 
 	err := json.NewEncoder(bodyBuffer).Encode(body)
@@ -51,7 +109,7 @@ func (c *Client) sendRequest(ctx context.Context, method string, path string, bo
 	}
 	req.SetBasicAuth(c.Username, c.Password)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -91,7 +149,7 @@ func (c *Client) sendMultipartJsonRequest(ctx context.Context, method string, pa
 	req.SetBasicAuth(c.Username, c.Password)
 	req.Header.Set("Content-Type", multipartWriter.FormDataContentType())
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -106,10 +164,10 @@ func handleResponse(response *http.Response, responseStruct *any) error {
 	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusBadRequest {
 		var errRes errorResponse
 		if err := json.NewDecoder(response.Body).Decode(&errRes); err == nil {
-			return fmt.Errorf("error from stardog (status: %d): %s", response.StatusCode, errRes.Message)
+			return &Error{StatusCode: response.StatusCode, Message: errRes.Message}
 		}
 
-		return fmt.Errorf("unknown error with status code: %d", response.StatusCode)
+		return &Error{StatusCode: response.StatusCode}
 	}
 
 	if *responseStruct != nil {
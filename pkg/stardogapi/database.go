@@ -3,24 +3,38 @@ package stardogapi
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"path"
+	"time"
 )
 
-// TODO extend
 type createDatabaseRequest struct {
-	Name string `json:"dbname"`
+	Name    string         `json:"dbname"`
+	Options map[string]any `json:"options,omitempty"`
+	Files   []string       `json:"files"`
 }
 
 type listDatabasesResponse struct {
 	Databases []string `json:"databases"`
 }
 
+type databaseStateRequest struct {
+	Timeout int32 `json:"timeout"`
+}
+
+// databaseOnlineOption is the read-only database option which is true while the database is online
+const databaseOnlineOption = "database.online"
+
+// databaseStateTimeout is the time in milliseconds Stardog waits for open connections before taking a database
+// offline or online. It is shorter than the requestTimeout, so the server finishes before the client gives up.
+const databaseStateTimeout = int32((requestTimeout - time.Second*10) / time.Millisecond)
+
 // Creates a database with the given name and options
-func (c *Client) CreateDatabase(ctx context.Context, name string, options map[string]string) (err error) {
+func (c *Client) CreateDatabase(ctx context.Context, name string, options map[string]any) (err error) {
 	return c.sendMultipartJsonRequest(ctx,
 		http.MethodPost,
 		"/admin/databases",
-		map[string]any{"root": &createDatabaseRequest{Name: name}},
+		map[string]any{"root": &createDatabaseRequest{Name: name, Options: options, Files: []string{}}},
 		nil,
 	)
 }
@@ -46,3 +60,121 @@ func (c *Client) ListDatabases(ctx context.Context) (databases []string, err err
 		&response,
 	)
 }
+
+// Returns the approximate size (number of triples) of the given database
+func (c *Client) GetDatabaseSize(ctx context.Context, name string) (size int64, err error) {
+	return size, c.sendRequest(ctx,
+		http.MethodGet,
+		path.Join("/", sanitizePathValue(name), "/size")+"?exact=false",
+		nil,
+		&size,
+	)
+}
+
+// Backs up the given database into the given directory on the Stardog server. Stardog writes the backup into a
+// subdirectory named after the database. An empty location uses the backup directory configured on the server. The
+// request returns once the backup has completed, so it is sent with the LongRunningRequestTimeout.
+func (c *Client) BackupDatabase(ctx context.Context, name, location string) (err error) {
+	query := url.Values{}
+	if location != "" {
+		query.Set("to", location)
+	}
+	return c.sendLongRunningRequest(ctx,
+		http.MethodPut,
+		path.Join("/admin/databases/", sanitizePathValue(name), "/backup")+encodeQuery(query),
+		nil,
+		nil,
+	)
+}
+
+// Restores the backup from the given directory on the Stardog server as a database with the given name. An existing
+// database with that name is only overwritten if force is set. The request returns once the restore has completed, so
+// it is sent with the LongRunningRequestTimeout.
+func (c *Client) RestoreDatabase(ctx context.Context, location, name string, force bool) (err error) {
+	query := url.Values{}
+	query.Set("from", location)
+	query.Set("name", name)
+	if force {
+		query.Set("force", "true")
+	}
+	return c.sendLongRunningRequest(ctx,
+		http.MethodPut,
+		"/admin/restore"+encodeQuery(query),
+		nil,
+		nil,
+	)
+}
+
+// Returns the current values of the given options of the database
+func (c *Client) GetDatabaseOptions(ctx context.Context, name string, options []string) (values map[string]any, err error) {
+	request := make(map[string]any, len(options))
+	for _, option := range options {
+		request[option] = nil
+	}
+
+	return values, c.sendRequest(ctx,
+		http.MethodPut,
+		path.Join("/admin/databases/", sanitizePathValue(name), "/options"),
+		request,
+		&values,
+	)
+}
+
+// Returns whether the database is online
+func (c *Client) IsDatabaseOnline(ctx context.Context, name string) (online bool, err error) {
+	values, err := c.GetDatabaseOptions(ctx, name, []string{databaseOnlineOption})
+	if err != nil {
+		return false, err
+	}
+	online, _ = values[databaseOnlineOption].(bool)
+	return online, nil
+}
+
+// Sets the given options of the database. Options which are not mutable while online require the database to be
+// offline.
+func (c *Client) SetDatabaseOptions(ctx context.Context, name string, options map[string]any) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodPost,
+		path.Join("/admin/databases/", sanitizePathValue(name), "/options"),
+		options,
+		nil,
+	)
+}
+
+// Returns the metadata of all database options known by the Stardog server, indexed by option name
+func (c *Client) GetConfigProperties(ctx context.Context) (properties map[string]ConfigProperty, err error) {
+	return properties, c.sendRequest(ctx,
+		http.MethodGet,
+		"/admin/config_properties",
+		nil,
+		&properties,
+	)
+}
+
+// Takes the database offline
+func (c *Client) OfflineDatabase(ctx context.Context, name string) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodPut,
+		path.Join("/admin/databases/", sanitizePathValue(name), "/offline"),
+		&databaseStateRequest{Timeout: databaseStateTimeout},
+		nil,
+	)
+}
+
+// Brings the database online
+func (c *Client) OnlineDatabase(ctx context.Context, name string) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodPut,
+		path.Join("/admin/databases/", sanitizePathValue(name), "/online"),
+		&databaseStateRequest{Timeout: databaseStateTimeout},
+		nil,
+	)
+}
+
+// Encodes the query values including the leading question mark, if there are any
+func encodeQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	return "?" + query.Encode()
+}
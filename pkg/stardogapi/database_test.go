@@ -0,0 +1,67 @@
+package stardogapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("regular client used")
+}
+
+func TestLongRunningRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		send         func(c *Client) error
+		expectedPath string
+	}{
+		{
+			name:         "GivenBackup_WhenSending_ThenUseLongRunningClient",
+			send:         func(c *Client) error { return c.BackupDatabase(context.Background(), "db", "/backups") },
+			expectedPath: "/admin/databases/db/backup",
+		},
+		{
+			name:         "GivenRestore_WhenSending_ThenUseLongRunningClient",
+			send:         func(c *Client) error { return c.RestoreDatabase(context.Background(), "/backups/db", "db", true) },
+			expectedPath: "/admin/restore",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+			c := NewClient("admin", "admin", server.URL)
+			c.HTTPClient = &http.Client{Transport: failingTransport{}}
+
+			err := tt.send(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPath, path)
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	c := NewClient("admin", "admin", "http://stardog")
+
+	assert.Equal(t, requestTimeout, c.HTTPClient.Timeout)
+	assert.Equal(t, LongRunningRequestTimeout, c.LongRunningHTTPClient.Timeout)
+	assert.Same(t, c.HTTPClient.Transport, c.LongRunningHTTPClient.Transport)
+}
+
+func TestDatabaseStateTimeout(t *testing.T) {
+	assert.Less(t, time.Duration(databaseStateTimeout)*time.Millisecond, requestTimeout)
+}
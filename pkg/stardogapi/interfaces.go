@@ -7,26 +7,49 @@ import "context"
 //go:generate mockgen -source interfaces.go -destination mock/mock_client.go -package mock -aux_files=github.com/vshn/stardog-userrole-operator/pkg/stardogapi=interfaces.go
 type StardogAPI interface {
 	// DB
-	CreateDatabase(ctx context.Context, name string, options map[string]string) (err error)
+	CreateDatabase(ctx context.Context, name string, options map[string]any) (err error)
 	DropDatabase(ctx context.Context, name string) (err error)
 	ListDatabases(ctx context.Context) (databases []string, err error)
+	GetDatabaseSize(ctx context.Context, name string) (size int64, err error)
+	BackupDatabase(ctx context.Context, name, location string) (err error)
+	RestoreDatabase(ctx context.Context, location, name string, force bool) (err error)
+	GetDatabaseOptions(ctx context.Context, name string, options []string) (values map[string]any, err error)
+	SetDatabaseOptions(ctx context.Context, name string, options map[string]any) (err error)
+	GetConfigProperties(ctx context.Context) (properties map[string]ConfigProperty, err error)
+	OfflineDatabase(ctx context.Context, name string) (err error)
+	OnlineDatabase(ctx context.Context, name string) (err error)
+	IsDatabaseOnline(ctx context.Context, name string) (online bool, err error)
 
 	// User
 	AddUser(ctx context.Context, name, password string) (err error)
+	AddSuperuser(ctx context.Context, name, password string) (err error)
 	DeleteUser(ctx context.Context, name string) (err error)
 	GetUser(ctx context.Context, name string) (user User, err error)
+	ListUsers(ctx context.Context) (users []string, err error)
+	ChangePassword(ctx context.Context, name, password string) (err error)
+	ValidateUser(ctx context.Context, name, password string) (valid bool, err error)
+	IsUserEnabled(ctx context.Context, name string) (enabled bool, err error)
+	SetUserEnabled(ctx context.Context, name string, enabled bool) (err error)
+	IsSuperuser(ctx context.Context, name string) (superuser bool, err error)
 	SetUserRoles(ctx context.Context, name string, roles []string) (err error)
 	GetUserRoles(ctx context.Context, name string) (roles []string, err error)
+	AddUserRole(ctx context.Context, name, role string) (err error)
+	DeleteUserRole(ctx context.Context, name, role string) (err error)
 
 	// Roles
 	AddRole(ctx context.Context, name string) (err error)
 	DeleteRole(ctx context.Context, name string) (err error)
 	GetRoles(ctx context.Context) (roles []string, err error)
+	GetRoleUsers(ctx context.Context, name string) (users []string, err error)
 
 	// Permissions
 	AddRolePermission(ctx context.Context, name string, permission Permission) (err error)
 	DeleteRolePermission(ctx context.Context, name string, permission Permission) (err error)
 	GetRolePermissions(ctx context.Context, name string) (permissions []Permission, err error)
+	AddUserPermission(ctx context.Context, name string, permission Permission) (err error)
+	DeleteUserPermission(ctx context.Context, name string, permission Permission) (err error)
+	GetUserPermissions(ctx context.Context, name string) (permissions []Permission, err error)
+	GetEffectivePermissions(ctx context.Context, name string) (permissions []Permission, err error)
 }
 
 var _ StardogAPI = (*Client)(nil)
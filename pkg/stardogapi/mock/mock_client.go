@@ -63,6 +63,20 @@ func (mr *MockStardogAPIMockRecorder) AddRolePermission(ctx, name, permission in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRolePermission", reflect.TypeOf((*MockStardogAPI)(nil).AddRolePermission), ctx, name, permission)
 }
 
+// AddSuperuser mocks base method.
+func (m *MockStardogAPI) AddSuperuser(ctx context.Context, name, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSuperuser", ctx, name, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddSuperuser indicates an expected call of AddSuperuser.
+func (mr *MockStardogAPIMockRecorder) AddSuperuser(ctx, name, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSuperuser", reflect.TypeOf((*MockStardogAPI)(nil).AddSuperuser), ctx, name, password)
+}
+
 // AddUser mocks base method.
 func (m *MockStardogAPI) AddUser(ctx context.Context, name, password string) error {
 	m.ctrl.T.Helper()
@@ -77,8 +91,64 @@ func (mr *MockStardogAPIMockRecorder) AddUser(ctx, name, password interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockStardogAPI)(nil).AddUser), ctx, name, password)
 }
 
+// AddUserPermission mocks base method.
+func (m *MockStardogAPI) AddUserPermission(ctx context.Context, name string, permission stardogapi.Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUserPermission", ctx, name, permission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUserPermission indicates an expected call of AddUserPermission.
+func (mr *MockStardogAPIMockRecorder) AddUserPermission(ctx, name, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserPermission", reflect.TypeOf((*MockStardogAPI)(nil).AddUserPermission), ctx, name, permission)
+}
+
+// AddUserRole mocks base method.
+func (m *MockStardogAPI) AddUserRole(ctx context.Context, name, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUserRole", ctx, name, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUserRole indicates an expected call of AddUserRole.
+func (mr *MockStardogAPIMockRecorder) AddUserRole(ctx, name, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserRole", reflect.TypeOf((*MockStardogAPI)(nil).AddUserRole), ctx, name, role)
+}
+
+// BackupDatabase mocks base method.
+func (m *MockStardogAPI) BackupDatabase(ctx context.Context, name, location string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackupDatabase", ctx, name, location)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BackupDatabase indicates an expected call of BackupDatabase.
+func (mr *MockStardogAPIMockRecorder) BackupDatabase(ctx, name, location interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupDatabase", reflect.TypeOf((*MockStardogAPI)(nil).BackupDatabase), ctx, name, location)
+}
+
+// ChangePassword mocks base method.
+func (m *MockStardogAPI) ChangePassword(ctx context.Context, name, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, name, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockStardogAPIMockRecorder) ChangePassword(ctx, name, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockStardogAPI)(nil).ChangePassword), ctx, name, password)
+}
+
 // CreateDatabase mocks base method.
-func (m *MockStardogAPI) CreateDatabase(ctx context.Context, name string, options map[string]string) error {
+func (m *MockStardogAPI) CreateDatabase(ctx context.Context, name string, options map[string]any) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateDatabase", ctx, name, options)
 	ret0, _ := ret[0].(error)
@@ -133,6 +203,34 @@ func (mr *MockStardogAPIMockRecorder) DeleteUser(ctx, name interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStardogAPI)(nil).DeleteUser), ctx, name)
 }
 
+// DeleteUserPermission mocks base method.
+func (m *MockStardogAPI) DeleteUserPermission(ctx context.Context, name string, permission stardogapi.Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserPermission", ctx, name, permission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserPermission indicates an expected call of DeleteUserPermission.
+func (mr *MockStardogAPIMockRecorder) DeleteUserPermission(ctx, name, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserPermission", reflect.TypeOf((*MockStardogAPI)(nil).DeleteUserPermission), ctx, name, permission)
+}
+
+// DeleteUserRole mocks base method.
+func (m *MockStardogAPI) DeleteUserRole(ctx context.Context, name, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserRole", ctx, name, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserRole indicates an expected call of DeleteUserRole.
+func (mr *MockStardogAPIMockRecorder) DeleteUserRole(ctx, name, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserRole", reflect.TypeOf((*MockStardogAPI)(nil).DeleteUserRole), ctx, name, role)
+}
+
 // DropDatabase mocks base method.
 func (m *MockStardogAPI) DropDatabase(ctx context.Context, name string) error {
 	m.ctrl.T.Helper()
@@ -147,6 +245,66 @@ func (mr *MockStardogAPIMockRecorder) DropDatabase(ctx, name interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropDatabase", reflect.TypeOf((*MockStardogAPI)(nil).DropDatabase), ctx, name)
 }
 
+// GetConfigProperties mocks base method.
+func (m *MockStardogAPI) GetConfigProperties(ctx context.Context) (map[string]stardogapi.ConfigProperty, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigProperties", ctx)
+	ret0, _ := ret[0].(map[string]stardogapi.ConfigProperty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigProperties indicates an expected call of GetConfigProperties.
+func (mr *MockStardogAPIMockRecorder) GetConfigProperties(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigProperties", reflect.TypeOf((*MockStardogAPI)(nil).GetConfigProperties), ctx)
+}
+
+// GetDatabaseOptions mocks base method.
+func (m *MockStardogAPI) GetDatabaseOptions(ctx context.Context, name string, options []string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDatabaseOptions", ctx, name, options)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDatabaseOptions indicates an expected call of GetDatabaseOptions.
+func (mr *MockStardogAPIMockRecorder) GetDatabaseOptions(ctx, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDatabaseOptions", reflect.TypeOf((*MockStardogAPI)(nil).GetDatabaseOptions), ctx, name, options)
+}
+
+// GetDatabaseSize mocks base method.
+func (m *MockStardogAPI) GetDatabaseSize(ctx context.Context, name string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDatabaseSize", ctx, name)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDatabaseSize indicates an expected call of GetDatabaseSize.
+func (mr *MockStardogAPIMockRecorder) GetDatabaseSize(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDatabaseSize", reflect.TypeOf((*MockStardogAPI)(nil).GetDatabaseSize), ctx, name)
+}
+
+// GetEffectivePermissions mocks base method.
+func (m *MockStardogAPI) GetEffectivePermissions(ctx context.Context, name string) ([]stardogapi.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEffectivePermissions", ctx, name)
+	ret0, _ := ret[0].([]stardogapi.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEffectivePermissions indicates an expected call of GetEffectivePermissions.
+func (mr *MockStardogAPIMockRecorder) GetEffectivePermissions(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEffectivePermissions", reflect.TypeOf((*MockStardogAPI)(nil).GetEffectivePermissions), ctx, name)
+}
+
 // GetRolePermissions mocks base method.
 func (m *MockStardogAPI) GetRolePermissions(ctx context.Context, name string) ([]stardogapi.Permission, error) {
 	m.ctrl.T.Helper()
@@ -162,6 +320,21 @@ func (mr *MockStardogAPIMockRecorder) GetRolePermissions(ctx, name interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRolePermissions", reflect.TypeOf((*MockStardogAPI)(nil).GetRolePermissions), ctx, name)
 }
 
+// GetRoleUsers mocks base method.
+func (m *MockStardogAPI) GetRoleUsers(ctx context.Context, name string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleUsers", ctx, name)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleUsers indicates an expected call of GetRoleUsers.
+func (mr *MockStardogAPIMockRecorder) GetRoleUsers(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleUsers", reflect.TypeOf((*MockStardogAPI)(nil).GetRoleUsers), ctx, name)
+}
+
 // GetRoles mocks base method.
 func (m *MockStardogAPI) GetRoles(ctx context.Context) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -192,6 +365,21 @@ func (mr *MockStardogAPIMockRecorder) GetUser(ctx, name interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStardogAPI)(nil).GetUser), ctx, name)
 }
 
+// GetUserPermissions mocks base method.
+func (m *MockStardogAPI) GetUserPermissions(ctx context.Context, name string) ([]stardogapi.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPermissions", ctx, name)
+	ret0, _ := ret[0].([]stardogapi.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPermissions indicates an expected call of GetUserPermissions.
+func (mr *MockStardogAPIMockRecorder) GetUserPermissions(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPermissions", reflect.TypeOf((*MockStardogAPI)(nil).GetUserPermissions), ctx, name)
+}
+
 // GetUserRoles mocks base method.
 func (m *MockStardogAPI) GetUserRoles(ctx context.Context, name string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -207,6 +395,51 @@ func (mr *MockStardogAPIMockRecorder) GetUserRoles(ctx, name interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRoles", reflect.TypeOf((*MockStardogAPI)(nil).GetUserRoles), ctx, name)
 }
 
+// IsDatabaseOnline mocks base method.
+func (m *MockStardogAPI) IsDatabaseOnline(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDatabaseOnline", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsDatabaseOnline indicates an expected call of IsDatabaseOnline.
+func (mr *MockStardogAPIMockRecorder) IsDatabaseOnline(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDatabaseOnline", reflect.TypeOf((*MockStardogAPI)(nil).IsDatabaseOnline), ctx, name)
+}
+
+// IsSuperuser mocks base method.
+func (m *MockStardogAPI) IsSuperuser(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSuperuser", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSuperuser indicates an expected call of IsSuperuser.
+func (mr *MockStardogAPIMockRecorder) IsSuperuser(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSuperuser", reflect.TypeOf((*MockStardogAPI)(nil).IsSuperuser), ctx, name)
+}
+
+// IsUserEnabled mocks base method.
+func (m *MockStardogAPI) IsUserEnabled(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsUserEnabled", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsUserEnabled indicates an expected call of IsUserEnabled.
+func (mr *MockStardogAPIMockRecorder) IsUserEnabled(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUserEnabled", reflect.TypeOf((*MockStardogAPI)(nil).IsUserEnabled), ctx, name)
+}
+
 // ListDatabases mocks base method.
 func (m *MockStardogAPI) ListDatabases(ctx context.Context) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -222,6 +455,91 @@ func (mr *MockStardogAPIMockRecorder) ListDatabases(ctx interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDatabases", reflect.TypeOf((*MockStardogAPI)(nil).ListDatabases), ctx)
 }
 
+// ListUsers mocks base method.
+func (m *MockStardogAPI) ListUsers(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockStardogAPIMockRecorder) ListUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStardogAPI)(nil).ListUsers), ctx)
+}
+
+// OfflineDatabase mocks base method.
+func (m *MockStardogAPI) OfflineDatabase(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OfflineDatabase", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OfflineDatabase indicates an expected call of OfflineDatabase.
+func (mr *MockStardogAPIMockRecorder) OfflineDatabase(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OfflineDatabase", reflect.TypeOf((*MockStardogAPI)(nil).OfflineDatabase), ctx, name)
+}
+
+// OnlineDatabase mocks base method.
+func (m *MockStardogAPI) OnlineDatabase(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OnlineDatabase", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OnlineDatabase indicates an expected call of OnlineDatabase.
+func (mr *MockStardogAPIMockRecorder) OnlineDatabase(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnlineDatabase", reflect.TypeOf((*MockStardogAPI)(nil).OnlineDatabase), ctx, name)
+}
+
+// RestoreDatabase mocks base method.
+func (m *MockStardogAPI) RestoreDatabase(ctx context.Context, location, name string, force bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreDatabase", ctx, location, name, force)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreDatabase indicates an expected call of RestoreDatabase.
+func (mr *MockStardogAPIMockRecorder) RestoreDatabase(ctx, location, name, force interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreDatabase", reflect.TypeOf((*MockStardogAPI)(nil).RestoreDatabase), ctx, location, name, force)
+}
+
+// SetDatabaseOptions mocks base method.
+func (m *MockStardogAPI) SetDatabaseOptions(ctx context.Context, name string, options map[string]any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDatabaseOptions", ctx, name, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDatabaseOptions indicates an expected call of SetDatabaseOptions.
+func (mr *MockStardogAPIMockRecorder) SetDatabaseOptions(ctx, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDatabaseOptions", reflect.TypeOf((*MockStardogAPI)(nil).SetDatabaseOptions), ctx, name, options)
+}
+
+// SetUserEnabled mocks base method.
+func (m *MockStardogAPI) SetUserEnabled(ctx context.Context, name string, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserEnabled", ctx, name, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserEnabled indicates an expected call of SetUserEnabled.
+func (mr *MockStardogAPIMockRecorder) SetUserEnabled(ctx, name, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserEnabled", reflect.TypeOf((*MockStardogAPI)(nil).SetUserEnabled), ctx, name, enabled)
+}
+
 // SetUserRoles mocks base method.
 func (m *MockStardogAPI) SetUserRoles(ctx context.Context, name string, roles []string) error {
 	m.ctrl.T.Helper()
@@ -235,3 +553,18 @@ func (mr *MockStardogAPIMockRecorder) SetUserRoles(ctx, name, roles interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRoles", reflect.TypeOf((*MockStardogAPI)(nil).SetUserRoles), ctx, name, roles)
 }
+
+// ValidateUser mocks base method.
+func (m *MockStardogAPI) ValidateUser(ctx context.Context, name, password string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateUser", ctx, name, password)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateUser indicates an expected call of ValidateUser.
+func (mr *MockStardogAPIMockRecorder) ValidateUser(ctx, name, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateUser", reflect.TypeOf((*MockStardogAPI)(nil).ValidateUser), ctx, name, password)
+}
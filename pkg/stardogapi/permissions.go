@@ -6,13 +6,13 @@ import (
 	"path"
 )
 
-type getRolePermissionsRolesResponse struct {
+type getPermissionsResponse struct {
 	Permissions []Permission `json:"permissions"`
 }
 
 // Get Permissions of a role
 func (c *Client) GetRolePermissions(ctx context.Context, name string) ([]Permission, error) {
-	var response getRolePermissionsRolesResponse
+	var response getPermissionsResponse
 
 	return response.Permissions, c.sendRequest(ctx,
 		http.MethodGet,
@@ -41,3 +41,47 @@ func (c *Client) DeleteRolePermission(ctx context.Context, name string, permissi
 		nil,
 	)
 }
+
+// Get Permissions granted to a user directly
+func (c *Client) GetUserPermissions(ctx context.Context, name string) ([]Permission, error) {
+	var response getPermissionsResponse
+
+	return response.Permissions, c.sendRequest(ctx,
+		http.MethodGet,
+		path.Join("/admin/permissions/user/", sanitizePathValue(name)),
+		nil,
+		&response,
+	)
+}
+
+// Add the Permission to a user
+func (c *Client) AddUserPermission(ctx context.Context, name string, permission Permission) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodPut,
+		path.Join("/admin/permissions/user/", sanitizePathValue(name)),
+		&permission,
+		nil,
+	)
+}
+
+// Delete the Permission from a user
+func (c *Client) DeleteUserPermission(ctx context.Context, name string, permission Permission) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodPost,
+		path.Join("/admin/permissions/user/", sanitizePathValue(name), "/delete"),
+		&permission,
+		nil,
+	)
+}
+
+// Get all Permissions of a user, including the ones granted by its roles
+func (c *Client) GetEffectivePermissions(ctx context.Context, name string) ([]Permission, error) {
+	var response getPermissionsResponse
+
+	return response.Permissions, c.sendRequest(ctx,
+		http.MethodGet,
+		path.Join("/admin/permissions/effective/user/", sanitizePathValue(name)),
+		nil,
+		&response,
+	)
+}
@@ -14,6 +14,10 @@ type rolesResponse struct {
 	Roles []string `json:"roles"`
 }
 
+type roleUsersResponse struct {
+	Users []string `json:"users"`
+}
+
 // Get the available roles
 func (c *Client) GetRoles(ctx context.Context) ([]string, error) {
 	var rolesResponse rolesResponse
@@ -45,3 +49,15 @@ func (c *Client) DeleteRole(ctx context.Context, name string) (err error) {
 		nil,
 	)
 }
+
+// Get the users assigned to a role
+func (c *Client) GetRoleUsers(ctx context.Context, name string) (users []string, err error) {
+	var response roleUsersResponse
+
+	return response.Users, c.sendRequest(ctx,
+		http.MethodGet,
+		path.Join("/admin/roles/", sanitizePathValue(name), "/users"),
+		nil,
+		&response,
+	)
+}
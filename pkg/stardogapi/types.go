@@ -21,3 +21,16 @@ type UserCredentials struct {
 	Name     string
 	Password string
 }
+
+// ConfigProperty reflects the metadata of a database option returned by the Stardog API.
+type ConfigProperty struct {
+	Name               string   `json:"name"`
+	Type               string   `json:"type"`
+	Mutable            bool     `json:"mutable"`
+	MutableWhileOnline bool     `json:"mutableWhileOnline"`
+	Category           string   `json:"category"`
+	Label              string   `json:"label"`
+	Description        string   `json:"description"`
+	PossibleValues     []string `json:"possibleValues"`
+	DefaultValue       string   `json:"defaultValue"`
+}
@@ -2,20 +2,38 @@ package stardogapi
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"path"
 	"strings"
 )
 
 type addUserRequest struct {
-	Username string   `json:"username"`
-	Password []string `json:"password"`
+	Username  string   `json:"username"`
+	Password  []string `json:"password"`
+	Superuser bool     `json:"superuser"`
 }
 
 type userRolesRequestAndResponse struct {
 	Roles []string `json:"roles"`
 }
 
+type listUsersResponse struct {
+	Users []string `json:"users"`
+}
+
+type changePasswordRequest struct {
+	Password string `json:"password"`
+}
+
+type superuserResponse struct {
+	Superuser bool `json:"superuser"`
+}
+
+type userEnabledRequestAndResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
 // Add a new user
 func (c *Client) AddUser(ctx context.Context, name, password string) (err error) {
 	return c.sendRequest(ctx,
@@ -29,6 +47,93 @@ func (c *Client) AddUser(ctx context.Context, name, password string) (err error)
 	)
 }
 
+// Add a new superuser, the superuser flag of a user can only be set on creation
+func (c *Client) AddSuperuser(ctx context.Context, name, password string) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodPost,
+		"/admin/users",
+		&addUserRequest{
+			Username:  name,
+			Password:  strings.Split(password, ""), // The API expects the password split into an array of single characters
+			Superuser: true,
+		},
+		nil,
+	)
+}
+
+// Get the names of all users
+func (c *Client) ListUsers(ctx context.Context) (users []string, err error) {
+	var response listUsersResponse
+
+	return response.Users, c.sendRequest(ctx,
+		http.MethodGet,
+		"/admin/users",
+		nil,
+		&response,
+	)
+}
+
+// Change the password of a user
+func (c *Client) ChangePassword(ctx context.Context, name, password string) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodPut,
+		path.Join("/admin/users/", sanitizePathValue(name), "/pwd"),
+		&changePasswordRequest{Password: password},
+		nil,
+	)
+}
+
+// Check whether the credentials of a user are valid by authenticating with them
+func (c *Client) ValidateUser(ctx context.Context, name, password string) (valid bool, err error) {
+	userClient := &Client{BaseURL: c.BaseURL, Username: name, Password: password, HTTPClient: c.HTTPClient, LongRunningHTTPClient: c.LongRunningHTTPClient}
+
+	err = userClient.sendRequest(ctx,
+		http.MethodGet,
+		"/admin/users/valid",
+		nil,
+		nil,
+	)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Check whether a user is enabled
+func (c *Client) IsUserEnabled(ctx context.Context, name string) (enabled bool, err error) {
+	var response userEnabledRequestAndResponse
+
+	return response.Enabled, c.sendRequest(ctx,
+		http.MethodGet,
+		path.Join("/admin/users/", sanitizePathValue(name), "/enabled"),
+		nil,
+		&response,
+	)
+}
+
+// Check whether a user is a superuser
+func (c *Client) IsSuperuser(ctx context.Context, name string) (superuser bool, err error) {
+	var response superuserResponse
+
+	return response.Superuser, c.sendRequest(ctx,
+		http.MethodGet,
+		path.Join("/admin/users/", sanitizePathValue(name), "/superuser"),
+		nil,
+		&response,
+	)
+}
+
+// Enable or disable a user
+func (c *Client) SetUserEnabled(ctx context.Context, name string, enabled bool) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodPut,
+		path.Join("/admin/users/", sanitizePathValue(name), "/enabled"),
+		&userEnabledRequestAndResponse{Enabled: enabled},
+		nil,
+	)
+}
+
 // Delete a user
 func (c *Client) DeleteUser(ctx context.Context, name string) (err error) {
 	return c.sendRequest(ctx,
@@ -70,3 +175,23 @@ func (c *Client) GetUserRoles(ctx context.Context, name string) (roles []string,
 		&response,
 	)
 }
+
+// Add a role to a user
+func (c *Client) AddUserRole(ctx context.Context, name, role string) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodPost,
+		path.Join("/admin/users/", sanitizePathValue(name), "/roles"),
+		&addRoleRequest{Rolename: role},
+		nil,
+	)
+}
+
+// Remove a role from a user
+func (c *Client) DeleteUserRole(ctx context.Context, name, role string) (err error) {
+	return c.sendRequest(ctx,
+		http.MethodDelete,
+		path.Join("/admin/users/", sanitizePathValue(name), "/roles/", sanitizePathValue(role)),
+		nil,
+		nil,
+	)
+}